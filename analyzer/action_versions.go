@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RecommendedActionVersions are the major versions rrctl steers common actions to: the upgrade
+// target of DeprecatedActions and the version repo-autofix pins unpinned references to
+var RecommendedActionVersions = map[string]string{
+	"actions/checkout":           "v4",
+	"actions/setup-go":           "v5",
+	"actions/setup-node":         "v4",
+	"actions/setup-python":       "v5",
+	"actions/setup-java":         "v4",
+	"actions/setup-dotnet":       "v4",
+	"actions/cache":              "v4",
+	"actions/upload-artifact":    "v4",
+	"actions/download-artifact":  "v4",
+	"actions/github-script":      "v7",
+	"docker/setup-buildx-action": "v3",
+	"docker/setup-qemu-action":   "v3",
+	"docker/login-action":        "v3",
+	"docker/build-push-action":   "v5",
+}
+
+// DeprecatedAction marks the major versions of an action below BelowMajor as deprecated
+type DeprecatedAction struct {
+	Action     string
+	BelowMajor int
+	// Reason says why those versions should not be used
+	Reason string
+}
+
+// DeprecatedActions are action versions that run on Node runtimes GitHub-hosted runners no longer
+// provide, or that depend on a backend service GitHub has sunset
+var DeprecatedActions = []DeprecatedAction{
+	{Action: "actions/checkout", BelowMajor: 4, Reason: "runs on Node 16 or older"},
+	{Action: "actions/setup-go", BelowMajor: 5, Reason: "runs on Node 16 or older"},
+	{Action: "actions/setup-node", BelowMajor: 4, Reason: "runs on Node 16 or older"},
+	{Action: "actions/setup-python", BelowMajor: 5, Reason: "runs on Node 16 or older"},
+	{Action: "actions/setup-java", BelowMajor: 4, Reason: "runs on Node 16 or older"},
+	{Action: "actions/setup-dotnet", BelowMajor: 4, Reason: "runs on Node 16 or older"},
+	{Action: "actions/github-script", BelowMajor: 7, Reason: "runs on Node 16 or older"},
+	{Action: "actions/cache", BelowMajor: 4, Reason: "uses the cache service GitHub shut down in 2025"},
+	{Action: "actions/upload-artifact", BelowMajor: 4, Reason: "sunset: v3 and older uploads fail"},
+	{Action: "actions/download-artifact", BelowMajor: 4, Reason: "sunset: v3 and older downloads fail"},
+	{Action: "docker/setup-buildx-action", BelowMajor: 3, Reason: "runs on Node 16 or older"},
+	{Action: "docker/setup-qemu-action", BelowMajor: 3, Reason: "runs on Node 16 or older"},
+	{Action: "docker/login-action", BelowMajor: 3, Reason: "runs on Node 16 or older"},
+	{Action: "docker/build-push-action", BelowMajor: 5, Reason: "runs on Node 16 or older"},
+}
+
+// detectDeprecatedActions flags steps referencing a deprecated major version of an action, with
+// the recommended upgrade. SHA and branch references are skipped: their version is unknown.
+func detectDeprecatedActions(w WorkflowReport, root map[string]any) []Finding {
+	var out []Finding
+	forEachStep(root, func(job string, _ map[string]any, step string, sm map[string]any) {
+		u, _ := sm["uses"].(string)
+		_, ref, _ := strings.Cut(strings.TrimSpace(u), "@")
+		major, ok := actionMajor(ref)
+		if !ok {
+			return
+		}
+		for _, d := range DeprecatedActions {
+			if !usesAction(sm, d.Action) || major >= d.BelowMajor {
+				continue
+			}
+			msg := fmt.Sprintf("job:%s step:%s: %s@%s is deprecated (%s); upgrade to %s@%s", job, step, d.Action, ref, d.Reason, d.Action, valueOr(RecommendedActionVersions[d.Action], fmt.Sprintf("v%d", d.BelowMajor)))
+			out = append(out, newStepFinding("workflow.deprecated-action", w.File, job, step, msg))
+		}
+	})
+	return out
+}
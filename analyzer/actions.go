@@ -0,0 +1,80 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ActionReport describes a local action definition (action.yml) found in the repository
+type ActionReport struct {
+	File     string    `json:"file"`
+	Name     string    `json:"name"`
+	Using    string    `json:"using"`
+	Findings []Finding `json:"findings"`
+}
+
+// deprecatedNodeRuntimes maps EOL runs.using values to their replacement
+var deprecatedNodeRuntimes = map[string]string{
+	"node12": "node20",
+	"node16": "node20",
+}
+
+// ScanActions walks the repository for action.yml/action.yaml files and analyzes them
+func ScanActions(root string, opts ScanOptions) ([]ActionReport, error) {
+	var out []ActionReport
+	err := filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != root && (name == "node_modules" || (strings.HasPrefix(name, ".") && name != ".github")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != "action.yml" && d.Name() != "action.yaml" {
+			return nil
+		}
+		Tracef("Scanning action %s", p)
+		ar, err := analyzeActionFile(p)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to analyze %s: %v\n", p, err)
+			return nil
+		}
+		out = append(out, ar)
+		return opts.finish(ar.Findings)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].File < out[j].File })
+	return out, nil
+}
+
+func analyzeActionFile(path string) (ActionReport, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return ActionReport{}, err
+	}
+	var doc struct {
+		Name string `yaml:"name"`
+		Runs struct {
+			Using string `yaml:"using"`
+		} `yaml:"runs"`
+	}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return ActionReport{}, err
+	}
+	ar := ActionReport{File: path, Name: doc.Name, Using: doc.Runs.Using}
+	if repl, ok := deprecatedNodeRuntimes[strings.ToLower(ar.Using)]; ok {
+		ar.Findings = append(ar.Findings, newFinding("action.deprecated-runtime", path,
+			fmt.Sprintf("runs.using: %s is an EOL Node runtime; upgrade to %s", ar.Using, repl)))
+	}
+	return ar, nil
+}
@@ -0,0 +1,304 @@
+package analyzer
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// Options configures Analyze. The zero value scans root/.github/workflows with git history, a
+// 60-day stale threshold and no GitHub enrichment.
+type Options struct {
+	// Context cancels GitHub enrichment (default context.Background())
+	Context context.Context
+	// Root is the repository root ("." when empty)
+	Root string
+	// WorkflowDirs are the workflows directories to scan (default Root/.github/workflows)
+	WorkflowDirs []string
+	// Provider is "github" (workflows and local actions, the default), "gitlab" (.gitlab-ci.yml) or
+	// "azure" (azure-pipelines.yml)
+	Provider string
+	// StaleDays is the age after which workflows, PRs and environments count as stale (default 60)
+	StaleDays int
+	// NoGit takes last-modified times from the filesystem instead of git history
+	NoGit bool
+	// Since keeps only workflows changed after a date or git ref (see FilterChangedSince)
+	Since string
+	// MinSeverity drops findings below it (default low: keep all)
+	MinSeverity Severity
+	// SortBy orders findings by file (default), rule or severity (see SortReport)
+	SortBy string
+	// GitHub, when set, enriches the report from the GitHub API
+	GitHub *GitHubOptions
+	// Baseline drops findings already present in a previous report (see CompareAgainstBaseline);
+	// BaselinePath is recorded in the report
+	Baseline     *Report
+	BaselinePath string
+	// DeltaBase is diffed against the run (see DiffReports); DeltaPath is recorded in the report
+	DeltaBase *Report
+	DeltaPath string
+	// ScanOptions tunes the workflow, action, GitLab and Azure analysis
+	ScanOptions
+}
+
+// ScanOptions tunes how files are analyzed and is passed to every Scan function. The zero value
+// applies the built-in defaults.
+type ScanOptions struct {
+	// Concurrency is the number of workflow files analyzed in parallel (0 = runtime.NumCPU())
+	Concurrency int
+	// ConcurrencyScope is the concurrency that satisfies workflow.no-concurrency: "any" (workflow
+	// or job level, the default) or "workflow" (the top-level block only)
+	ConcurrencyScope string
+	// MatrixThreshold is the matrix size above which workflow.matrix-large flags a job and
+	// recommends max-parallel (0 = DefaultMatrixThreshold)
+	MatrixThreshold int
+	// DeprecatedInputs replaces the built-in DefaultDeprecatedInputs knowledge base when set
+	DeprecatedInputs []DeprecatedInput
+	// TrustedInstallers are URL prefixes exempt from the pipe-to-shell check
+	TrustedInstallers []string
+	// SeverityOverrides remaps rule severities (see ParseSeverityOverrides)
+	SeverityOverrides map[string]Severity
+	// Skip, when set, is consulted for every workflow file before it is analyzed; returning true
+	// leaves the file out of the results (used for --ignore and .rrctlignore)
+	Skip func(path string) bool
+	// StopAt, when set, is consulted for every finding as files are analyzed; returning true
+	// aborts the scan with a *StopError (used for --fail-fast)
+	StopAt func(Finding) bool
+}
+
+func (o ScanOptions) workers() int {
+	if o.Concurrency > 0 {
+		return o.Concurrency
+	}
+	return runtime.NumCPU()
+}
+
+func (o ScanOptions) matrixThreshold() int {
+	if o.MatrixThreshold > 0 {
+		return o.MatrixThreshold
+	}
+	return DefaultMatrixThreshold
+}
+
+func (o ScanOptions) deprecatedInputs() []DeprecatedInput {
+	if o.DeprecatedInputs != nil {
+		return o.DeprecatedInputs
+	}
+	return DefaultDeprecatedInputs
+}
+
+// finish applies the severity overrides to the findings of one analyzed file and reports whether
+// StopAt ends the scan there
+func (o ScanOptions) finish(fs []Finding) error {
+	OverrideSeverities(fs, o.SeverityOverrides)
+	if o.StopAt == nil {
+		return nil
+	}
+	for _, f := range fs {
+		if o.StopAt(f) {
+			return &StopError{Finding: f}
+		}
+	}
+	return nil
+}
+
+// GitHubOptions selects the repository and sampling for GitHub API enrichment
+type GitHubOptions struct {
+	Owner string
+	Repo  string
+	Token string
+	// SampleRuns is the number of recent runs sampled per workflow for failure rates
+	SampleRuns int
+	// Timeout bounds the whole enrichment (0 = no limit); on expiry the sections fetched so far are
+	// kept as a Partial report
+	Timeout time.Duration
+	// APIURL is the REST API root (default DefaultGitHubAPIURL); GitHub Enterprise Server serves it
+	// at https://HOST/api/v3 (see ParseGitHubAPIURL)
+	APIURL string
+	// Retries is how often one request is retried after a network error, a 5xx response or a rate
+	// limit (0 = never; rrctl uses DefaultGitHubRetries); other 4xx responses fail at once
+	Retries int
+	// CacheDir, when set, caches API responses on disk keyed by URL and revalidates them with
+	// If-None-Match, so unchanged resources come back as 304 Not Modified
+	CacheDir string
+	// MaxPRs caps how many open pull requests are paged through (0 = no cap)
+	MaxPRs int
+}
+
+// Analyze runs the complete repo-defrag analysis: scanning, summary counts, local actions,
+// optional GitHub enrichment, hygiene scores, severity filtering, fingerprints, baseline comparison and sorting.
+// A StopAt hit is returned as a *StopError. GitHub enrichment failures are reported via Warnf and
+// leave Report.GitHub nil, except a cancelled or timed-out enrichment, which keeps its partial data.
+func Analyze(opts Options) (Report, error) {
+	root := opts.Root
+	if root == "" {
+		root = "."
+	}
+	staleDays := opts.StaleDays
+	if staleDays == 0 {
+		staleDays = 60
+	}
+	minSev := opts.MinSeverity
+	if minSev == "" {
+		minSev = SeverityLow
+	}
+	report := Report{
+		GeneratedAt: time.Now().UTC(),
+		RootPath:    root,
+		StaleDays:   staleDays,
+	}
+
+	switch opts.Provider {
+	case "gitlab":
+		gl, err := ScanGitLabCI(root, staleDays, !opts.NoGit, opts.ScanOptions)
+		if err != nil {
+			return report, err
+		}
+		report.GitLab = gl
+	case "azure":
+		az, err := ScanAzurePipelines(root, !opts.NoGit, opts.ScanOptions)
+		if err != nil {
+			return report, err
+		}
+		report.Azure = az
+	default:
+		dirs := opts.WorkflowDirs
+		if len(dirs) == 0 {
+			dirs = []string{filepath.Join(root, ".github", "workflows")}
+		}
+		workflows, err := ScanWorkflowDirs(dirs, staleDays, !opts.NoGit, opts.ScanOptions)
+		if err != nil {
+			return report, err
+		}
+		if opts.Since != "" {
+			if workflows, err = FilterChangedSince(workflows, root, opts.Since); err != nil {
+				return report, err
+			}
+			report.Since = opts.Since
+		}
+		report.WorkflowsPath, report.WorkflowsDirs, report.Workflows = dirs[0], dirs, workflows
+	}
+
+	for _, w := range report.Workflows {
+		report.Summary.WorkflowCount++
+		if WorkflowIsStale(w, staleDays) {
+			report.Summary.WorkflowsStale++
+		}
+		if w.UsesUnpinnedAction {
+			report.Summary.WorkflowsWithUnpinned++
+		}
+		if !w.HasConcurrency {
+			report.Summary.WorkflowsWithoutConcurrency++
+		}
+		if !w.HasPermissions {
+			report.Summary.WorkflowsWithoutPermissions++
+		}
+		report.Summary.JobsWithoutTimeout += len(w.JobsWithoutTimeout)
+		report.Summary.ActionPins.Add(w.ActionPins)
+	}
+
+	// Local action definitions (composite/JS/docker actions authored in this repo)
+	if opts.Provider != "gitlab" && opts.Provider != "azure" {
+		actions, err := ScanActions(root, opts.ScanOptions)
+		if _, stopped := err.(*StopError); stopped {
+			return report, err
+		}
+		if err != nil {
+			Warnf("action scan failed: %v", err)
+		}
+		report.Actions = actions
+	}
+
+	if gh := opts.GitHub; gh != nil {
+		ctx := opts.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		if gh.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, gh.Timeout)
+			defer cancel()
+		}
+		enriched, err := EnrichFromGitHub(ctx, *gh, staleDays)
+		switch {
+		case enriched != nil && enriched.Partial:
+			Warnf("%v; reporting the GitHub data fetched so far", err)
+			report.GitHub = enriched
+		case err != nil:
+			Warnf("GitHub enrichment failed: %v", err)
+		default:
+			report.GitHub = enriched
+		}
+	}
+	if report.GitHub != nil {
+		for i := range report.Workflows {
+			w := &report.Workflows[i]
+			var added []Finding
+			if report.GitHub.DefaultBranch != "" {
+				added = append(added, DetectDefaultBranchExcluded(*w, report.GitHub.DefaultBranch)...)
+			}
+			added = append(added, DetectRepoSecretsInEnvironmentJobs(*w, report.GitHub)...)
+			OverrideSeverities(added, opts.SeverityOverrides)
+			w.Findings = append(w.Findings, added...)
+		}
+	}
+
+	ScoreReport(&report)
+
+	for i := range report.Workflows {
+		report.Workflows[i].Findings = FilterFindings(report.Workflows[i].Findings, minSev)
+		AssignFingerprints(report.Workflows[i].Findings)
+	}
+	for i := range report.Actions {
+		report.Actions[i].Findings = FilterFindings(report.Actions[i].Findings, minSev)
+		AssignFingerprints(report.Actions[i].Findings)
+	}
+	if gl := report.GitLab; gl != nil {
+		gl.Findings = FilterFindings(gl.Findings, minSev)
+		AssignFingerprints(gl.Findings)
+	}
+	if az := report.Azure; az != nil {
+		az.Findings = FilterFindings(az.Findings, minSev)
+		AssignFingerprints(az.Findings)
+	}
+	// Before the baseline comparison drops the findings the delta would count as unchanged
+	if opts.DeltaBase != nil {
+		report.Delta = DiffReports(&report, opts.DeltaBase, opts.DeltaPath)
+	}
+	if opts.Baseline != nil {
+		CompareAgainstBaseline(&report, opts.Baseline, opts.BaselinePath)
+	}
+
+	SortReport(&report, opts.SortBy)
+
+	for i := range report.Workflows {
+		w := &report.Workflows[i]
+		w.Recommendations = FindingMessages(w.Findings)
+	}
+	for _, a := range report.Actions {
+		if len(a.Findings) > 0 {
+			report.Summary.ActionsDeprecatedRuntime++
+		}
+	}
+	return report, nil
+}
+
+// AllFindings returns the findings of every workflow, then every action, then GitLab CI and Azure
+// Pipelines, in report order
+func (r Report) AllFindings() []Finding {
+	var out []Finding
+	for _, w := range r.Workflows {
+		out = append(out, w.Findings...)
+	}
+	for _, a := range r.Actions {
+		out = append(out, a.Findings...)
+	}
+	if r.GitLab != nil {
+		out = append(out, r.GitLab.Findings...)
+	}
+	if r.Azure != nil {
+		out = append(out, r.Azure.Findings...)
+	}
+	return out
+}
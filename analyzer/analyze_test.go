@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestAnalyzeOptionsDoNotLeak runs Analyze with tuned ScanOptions, then with the zero value, and
+// checks the second run sees none of the first run's settings
+func TestAnalyzeOptionsDoNotLeak(t *testing.T) {
+	path := writeTempWorkflow(t, "on: push\njobs:\n  build:\n    concurrency: build\n    runs-on: ubuntu-latest\n    strategy:\n      matrix:\n        a: [1, 2, 3]\n        b: [1, 2]\n    steps:\n      - run: make\n")
+	root := filepath.Dir(filepath.Dir(filepath.Dir(path)))
+
+	rules := func(r Report) map[string]Severity {
+		got := map[string]Severity{}
+		for _, f := range r.AllFindings() {
+			got[f.RuleID] = f.Severity
+		}
+		return got
+	}
+	tuned, err := Analyze(Options{Root: root, NoGit: true, ScanOptions: ScanOptions{
+		ConcurrencyScope:  "workflow",
+		MatrixThreshold:   5,
+		SeverityOverrides: map[string]Severity{"workflow.no-concurrency": SeverityHigh},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rules(tuned); got["workflow.no-concurrency"] != SeverityHigh || got["workflow.matrix-large"] == "" {
+		t.Errorf("tuned run: want a high workflow.no-concurrency and a workflow.matrix-large finding, got %v", got)
+	}
+
+	plain, err := Analyze(Options{Root: root, NoGit: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rules(plain); got["workflow.no-concurrency"] != "" || got["workflow.matrix-large"] != "" {
+		t.Errorf("default run: job-level concurrency and a 6-job matrix should pass, got %v", got)
+	}
+
+	if _, err := ParseSeverityOverrides([]string{"workflow.no-concurrency=high"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := newFinding("workflow.no-concurrency", "ci.yml", "").Severity; got != SeverityLow {
+		t.Errorf("parsing overrides changed the catalog severity to %s", got)
+	}
+}
@@ -0,0 +1,208 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AzureReport describes a repository's azure-pipelines.yml (repo-defrag --provider azure)
+type AzureReport struct {
+	File         string     `json:"file"`
+	Jobs         []AzureJob `json:"jobs"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Findings     []Finding  `json:"findings"`
+}
+
+// AzureJob summarizes one pipeline job; VMImage is inherited from the stage or pipeline pool
+type AzureJob struct {
+	Name       string `json:"name"`
+	Stage      string `json:"stage,omitempty"`
+	VMImage    string `json:"vmImage,omitempty"`
+	Deployment bool   `json:"deployment"`
+	Tasks      int    `json:"tasks"`
+}
+
+// azureRetiredImages maps Microsoft-hosted vmImage labels (lowercased) that Azure Pipelines has
+// retired or is retiring to a current replacement
+var azureRetiredImages = map[string]string{
+	"ubuntu-16.04":      "ubuntu-24.04",
+	"ubuntu-18.04":      "ubuntu-24.04",
+	"ubuntu-20.04":      "ubuntu-24.04",
+	"macos-10.13":       "macOS-15",
+	"macos-10.14":       "macOS-15",
+	"macos-10.15":       "macOS-15",
+	"macos-11":          "macOS-15",
+	"macos-12":          "macOS-15",
+	"macos-13":          "macOS-15",
+	"vs2015-win2012r2":  "windows-2022",
+	"vs2017-win2016":    "windows-2022",
+	"win1803":           "windows-2022",
+	"windows-2016":      "windows-2022",
+	"windows-2019":      "windows-2022",
+	"windows-2019-vs19": "windows-2022",
+}
+
+// reAzureTaskVersion matches the @major (or @major.minor.patch) suffix that pins a task version
+var reAzureTaskVersion = regexp.MustCompile(`@\d+(\.\d+)*$`)
+
+// ScanAzurePipelines analyzes root/azure-pipelines.yml for retired hosted images, tasks without a
+// pinned version and pipelines whose CI or PR trigger covers every branch. Stages, jobs and
+// deployment jobs are walked; template: references are not expanded.
+func ScanAzurePipelines(root string, useGit bool, opts ScanOptions) (*AzureReport, error) {
+	path := filepath.Join(root, "azure-pipelines.yml")
+	Tracef("Scanning Azure Pipelines config %s", path)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read Azure Pipelines config: %w", err)
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	ar := &AzureReport{File: path, Jobs: []AzureJob{}}
+	if !useGit {
+		if info, err := os.Stat(path); err == nil {
+			ts := info.ModTime()
+			ar.LastModified = &ts
+		}
+	} else if ts, err := gitLastModified(path); err == nil {
+		ar.LastModified = &ts
+	}
+
+	trigger, hasTrigger := doc["trigger"]
+	if azureTriggerUnscoped(trigger, hasTrigger) {
+		ar.Findings = append(ar.Findings, newFinding("azure.unscoped-trigger", path,
+			"trigger is missing or includes every branch, so every push runs the pipeline; list branches (or paths) under trigger:, or set trigger: none"))
+	}
+	pr, hasPR := doc["pr"]
+	if azureTriggerUnscoped(pr, hasPR) {
+		ar.Findings = append(ar.Findings, newFinding("azure.unscoped-trigger", path,
+			"pr is missing or includes every branch, so pull requests into any branch run the pipeline; list target branches under pr:, or set pr: none"))
+	}
+
+	pipelinePool := azureVMImage(doc["pool"], "")
+	addJob := func(jm map[string]any, stage, pool string) {
+		job := AzureJob{Stage: stage, VMImage: azureVMImage(jm["pool"], pool)}
+		job.Name, _ = jm["job"].(string)
+		if name, ok := jm["deployment"].(string); ok {
+			job.Name, job.Deployment = name, true
+		}
+		if job.Name == "" {
+			// A pipeline with top-level steps runs them in one implicit job Azure names "Job"
+			job.Name = "Job"
+		}
+		if repl, ok := azureRetiredImages[strings.ToLower(job.VMImage)]; ok {
+			f := newFinding("azure.deprecated-image", path, fmt.Sprintf("job:%s runs on vmImage %s, a retired Microsoft-hosted image; move to %s", job.Name, job.VMImage, repl))
+			f.Job = job.Name
+			ar.Findings = append(ar.Findings, f)
+		}
+		for _, task := range azureTasks(jm) {
+			job.Tasks++
+			if !reAzureTaskVersion.MatchString(task) {
+				name, _, _ := strings.Cut(task, "@")
+				f := newFinding("azure.unpinned-task", path, fmt.Sprintf("job:%s task %s has no pinned version; pin the major version (e.g. %s@2) so a new major release cannot change the step", job.Name, task, name))
+				f.Job = job.Name
+				ar.Findings = append(ar.Findings, f)
+			}
+		}
+		ar.Jobs = append(ar.Jobs, job)
+	}
+	addJobs := func(v any, stage, pool string) {
+		list, _ := v.([]any)
+		for _, j := range list {
+			if jm := asMap(j); jm["job"] != nil || jm["deployment"] != nil {
+				addJob(jm, stage, pool)
+			}
+		}
+	}
+	switch {
+	case doc["stages"] != nil:
+		stages, _ := doc["stages"].([]any)
+		for _, s := range stages {
+			sm := asMap(s)
+			name, _ := sm["stage"].(string)
+			if name == "" {
+				continue
+			}
+			addJobs(sm["jobs"], name, azureVMImage(sm["pool"], pipelinePool))
+		}
+	case doc["jobs"] != nil:
+		addJobs(doc["jobs"], "", pipelinePool)
+	case doc["steps"] != nil:
+		addJob(doc, "", pipelinePool)
+	}
+	return ar, opts.finish(ar.Findings)
+}
+
+// azureTriggerUnscoped reports whether a trigger: or pr: value (present tells an absent key from
+// an empty one) runs for every branch: missing, a "*" branch, or a mapping without branches or
+// paths. "none" and explicit branch lists are scoped.
+func azureTriggerUnscoped(v any, present bool) bool {
+	if !present {
+		return true
+	}
+	if m, ok := v.(map[string]any); ok {
+		if m["branches"] == nil && m["paths"] == nil {
+			return true
+		}
+		// Paths alone, or branches with only exclude, scope the trigger
+		v = asMap(m["branches"])["include"]
+	}
+	return slices.Contains(stringList(v), "*")
+}
+
+// azureVMImage returns the vmImage of a pool: value, or inherited when there is none. Any pool
+// replaces the inherited one; named (self-hosted) pools and images chosen through variables or
+// template expressions return "".
+func azureVMImage(pool any, inherited string) string {
+	if pool == nil {
+		return inherited
+	}
+	img, _ := asMap(pool)["vmImage"].(string)
+	if strings.Contains(img, "$(") || strings.Contains(img, "${{") || strings.Contains(img, "$[") {
+		return ""
+	}
+	return img
+}
+
+// azureStepKeys are the job keys that hold steps, directly or through a deployment strategy;
+// pool, variables and the like are not searched for tasks
+var azureStepKeys = map[string]bool{
+	"steps": true, "strategy": true, "runOnce": true, "rolling": true, "canary": true,
+	"preDeploy": true, "deploy": true, "routeTraffic": true, "postRouteTraffic": true, "on": true, "success": true, "failure": true,
+}
+
+// azureTasks lists the task: references of a job's steps, including the steps of deployment
+// strategies (runOnce, rolling, canary and their lifecycle hooks), in a stable order
+func azureTasks(v any) []string {
+	var out []string
+	switch vv := v.(type) {
+	case []any:
+		for _, it := range vv {
+			out = append(out, azureTasks(it)...)
+		}
+	case map[string]any:
+		if task, ok := vv["task"].(string); ok {
+			return []string{task}
+		}
+		var keys []string
+		for k := range vv {
+			if azureStepKeys[k] {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			out = append(out, azureTasks(vv[k])...)
+		}
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// BaselineComparison records how the current run differs from a previous JSON report
+type BaselineComparison struct {
+	Path             string   `json:"path"`
+	NewFindings      int      `json:"newFindings"`
+	ExistingFindings int      `json:"existingFindings"`
+	NewlyFailing     []string `json:"newlyFailingWorkflows,omitempty"`
+}
+
+// LoadReport reads a JSON report previously written by repo-defrag --json
+func LoadReport(path string) (*Report, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read baseline: %w", err)
+	}
+	var r Report
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("parse baseline %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// baselineFingerprints indexes every finding in a previous report by fingerprint, keyed by
+// workflow/action file. Reports written before fingerprints existed are fingerprinted on load.
+func baselineFingerprints(base *Report) map[string]map[string]bool {
+	idx := map[string]map[string]bool{}
+	add := func(file string, fs []Finding) {
+		if idx[file] == nil {
+			idx[file] = map[string]bool{}
+		}
+		for _, f := range fs {
+			fp := f.Fingerprint
+			if fp == "" {
+				fp = fingerprintFinding(f)
+			}
+			idx[file][fp] = true
+		}
+	}
+	for _, w := range base.Workflows {
+		add(w.File, w.Findings)
+	}
+	for _, a := range base.Actions {
+		add(a.File, a.Findings)
+	}
+	if base.GitLab != nil {
+		add(base.GitLab.File, base.GitLab.Findings)
+	}
+	if base.Azure != nil {
+		add(base.Azure.File, base.Azure.Findings)
+	}
+	return idx
+}
+
+// CompareAgainstBaseline drops findings already present in the baseline so only regressions
+// remain, and records workflows whose most recent run newly failed
+func CompareAgainstBaseline(r *Report, base *Report, path string) {
+	idx := baselineFingerprints(base)
+	cmp := &BaselineComparison{Path: path}
+	keepNew := func(file string, fs []Finding) []Finding {
+		var out []Finding
+		for _, f := range fs {
+			if idx[file][f.Fingerprint] {
+				cmp.ExistingFindings++
+				continue
+			}
+			out = append(out, f)
+		}
+		cmp.NewFindings += len(out)
+		return out
+	}
+	for i := range r.Workflows {
+		r.Workflows[i].Findings = keepNew(r.Workflows[i].File, r.Workflows[i].Findings)
+	}
+	for i := range r.Actions {
+		r.Actions[i].Findings = keepNew(r.Actions[i].File, r.Actions[i].Findings)
+	}
+	if r.GitLab != nil {
+		r.GitLab.Findings = keepNew(r.GitLab.File, r.GitLab.Findings)
+	}
+	if r.Azure != nil {
+		r.Azure.Findings = keepNew(r.Azure.File, r.Azure.Findings)
+	}
+
+	if r.GitHub != nil {
+		failedBefore := map[string]bool{}
+		if base.GitHub != nil {
+			for _, wf := range base.GitHub.WorkflowFailure {
+				failedBefore[wf.Name] = wf.RecentFailure
+			}
+		}
+		for _, wf := range r.GitHub.WorkflowFailure {
+			if wf.RecentFailure && !failedBefore[wf.Name] {
+				cmp.NewlyFailing = append(cmp.NewlyFailing, wf.Name)
+			}
+		}
+	}
+	r.Baseline = cmp
+}
+
+// InBaseline returns a matcher reporting whether a finding (by fingerprint) exists in base
+func InBaseline(base *Report) func(Finding) bool {
+	idx := baselineFingerprints(base)
+	return func(f Finding) bool {
+		fp := f.Fingerprint
+		if fp == "" {
+			fp = fingerprintFinding(f)
+		}
+		return idx[f.File][fp]
+	}
+}
@@ -0,0 +1,62 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cacheSetup is a setup action with a built-in dependency cache
+type cacheSetup struct {
+	Action string
+	// Suggest is the cache: value recommended when none is set
+	Suggest string
+	// DefaultOnSince is the first major version that caches without a cache: input (0 = never)
+	DefaultOnSince int
+}
+
+var cacheSetups = []cacheSetup{
+	{Action: "actions/setup-go", Suggest: "true", DefaultOnSince: 4},
+	{Action: "actions/setup-node", Suggest: "npm"},
+	{Action: "actions/setup-python", Suggest: "pip"},
+}
+
+// detectMissingSetupCache flags setup-go/node/python steps whose dependency cache is off: no
+// cache: input (on versions where it is not the default) or cache: false. Jobs that restore
+// their own actions/cache are skipped.
+func detectMissingSetupCache(w WorkflowReport, root map[string]any) []Finding {
+	ownCache := map[string]bool{}
+	forEachStep(root, func(job string, _ map[string]any, _ string, sm map[string]any) {
+		if usesAction(sm, "actions/cache") || usesAction(sm, "actions/cache/restore") {
+			ownCache[job] = true
+		}
+	})
+	var out []Finding
+	forEachStep(root, func(job string, _ map[string]any, step string, sm map[string]any) {
+		if ownCache[job] {
+			return
+		}
+		u, _ := sm["uses"].(string)
+		_, ref, _ := strings.Cut(u, "@")
+		for _, cs := range cacheSetups {
+			if !usesAction(sm, cs.Action) {
+				continue
+			}
+			cache := strings.ToLower(stepWith(sm, "cache"))
+			if cache == "" && cs.DefaultOnSince > 0 {
+				// SHA or branch refs are assumed current, where caching is the default
+				if major, ok := actionMajor(ref); !ok || major >= cs.DefaultOnSince {
+					continue
+				}
+			}
+			if cache != "" && cache != "false" {
+				continue
+			}
+			msg := fmt.Sprintf("job:%s step:%s uses %s@%s without its dependency cache; set with: cache: %s", job, step, cs.Action, valueOr(ref, "(none)"), cs.Suggest)
+			if cache == "false" {
+				msg = fmt.Sprintf("job:%s step:%s disables the %s dependency cache (cache: false); remove it or add an actions/cache step", job, step, cs.Action)
+			}
+			out = append(out, newStepFinding("workflow.setup-no-cache", w.File, job, step, msg))
+		}
+	})
+	return out
+}
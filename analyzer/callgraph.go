@@ -0,0 +1,167 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"slices"
+	"sort"
+)
+
+// CallGraph is the reusable-workflow call graph of a set of workflows: which workflow's jobs call
+// which reusable workflow through jobs.<id>.uses
+type CallGraph struct {
+	// Nodes are the scanned workflows (by file path), then other local callees (by path) and remote
+	// callees (by uses: reference), each group sorted
+	Nodes []CallNode `json:"nodes"`
+	Edges []CallEdge `json:"edges"`
+}
+
+// CallNode is a workflow in the call graph
+type CallNode struct {
+	// ID is the workflow file path, or the uses: reference of a remote callee
+	ID     string `json:"id"`
+	Remote bool   `json:"remote,omitempty"`
+	// Missing is set for a local callee that does not exist in the repository
+	Missing bool `json:"missing,omitempty"`
+	// Orphan is set for a workflow triggered only by workflow_call that no scanned workflow calls;
+	// other repositories may still call it
+	Orphan bool `json:"orphan,omitempty"`
+	// InCycle is set for a workflow that, through its callees, ends up calling itself
+	InCycle bool `json:"inCycle,omitempty"`
+}
+
+// CallEdge is a job of From calling the reusable workflow To
+type CallEdge struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Job     string `json:"job"`
+	InCycle bool   `json:"inCycle,omitempty"`
+}
+
+// BuildCallGraph links each workflow to the reusable workflows its jobs call, marking cycles
+// (which GitHub rejects when the workflow runs) and orphaned reusable workflows
+func BuildCallGraph(workflows []WorkflowReport) CallGraph {
+	var g CallGraph
+	index := map[string]int{}
+	addNode := func(n CallNode) {
+		if _, ok := index[n.ID]; !ok {
+			index[n.ID] = len(g.Nodes)
+			g.Nodes = append(g.Nodes, n)
+		}
+	}
+	files := make([]string, 0, len(workflows))
+	for _, w := range workflows {
+		files = append(files, filepath.Clean(w.File))
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		addNode(CallNode{ID: f})
+	}
+
+	// Callees that are not scanned workflows: missing or unscanned local files and remote workflows
+	extra := map[string]CallNode{}
+	called := map[string]bool{}
+	for _, w := range workflows {
+		from := filepath.Clean(w.File)
+		for _, rw := range w.ReusableWorkflows {
+			n := CallNode{ID: rw.Uses, Remote: !rw.Local}
+			if rw.Local {
+				n.ID = filepath.Clean(filepath.Join(workflowRepoRoot(w.File), filepath.FromSlash(rw.Uses)))
+				n.Missing = rw.Exists != nil && !*rw.Exists
+			}
+			if _, scanned := index[n.ID]; !scanned {
+				extra[n.ID] = n
+			}
+			called[n.ID] = true
+			g.Edges = append(g.Edges, CallEdge{From: from, To: n.ID, Job: rw.Job})
+		}
+	}
+	ids := make([]string, 0, len(extra))
+	for id := range extra {
+		ids = append(ids, id)
+	}
+	// Local callees sort before remote ones
+	sort.Slice(ids, func(i, j int) bool {
+		if extra[ids[i]].Remote != extra[ids[j]].Remote {
+			return !extra[ids[i]].Remote
+		}
+		return ids[i] < ids[j]
+	})
+	for _, id := range ids {
+		addNode(extra[id])
+	}
+	for _, w := range workflows {
+		n := &g.Nodes[index[filepath.Clean(w.File)]]
+		n.Orphan = len(w.Triggers) == 1 && w.Triggers[0] == "workflow_call" && !called[n.ID]
+	}
+
+	for _, scc := range callGraphCycles(g) {
+		for _, id := range scc {
+			g.Nodes[index[id]].InCycle = true
+		}
+		for i := range g.Edges {
+			if slices.Contains(scc, g.Edges[i].From) && slices.Contains(scc, g.Edges[i].To) {
+				g.Edges[i].InCycle = true
+			}
+		}
+	}
+	return g
+}
+
+// callGraphCycles returns the strongly connected components of g that contain a cycle: several
+// workflows calling each other in a ring, or one workflow calling itself (Tarjan's algorithm)
+func callGraphCycles(g CallGraph) [][]string {
+	adj := map[string][]string{}
+	self := map[string]bool{}
+	for _, e := range g.Edges {
+		adj[e.From] = append(adj[e.From], e.To)
+		if e.From == e.To {
+			self[e.From] = true
+		}
+	}
+	var (
+		cycles  [][]string
+		stack   []string
+		onStack = map[string]bool{}
+		order   = map[string]int{}
+		low     = map[string]int{}
+		next    int
+		visit   func(string)
+	)
+	visit = func(v string) {
+		order[v], low[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range adj[v] {
+			if _, seen := order[w]; !seen {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], order[w])
+			}
+		}
+		if low[v] != order[v] {
+			return
+		}
+		var scc []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			scc = append(scc, w)
+			if w == v {
+				break
+			}
+		}
+		if len(scc) > 1 || self[v] {
+			sort.Strings(scc)
+			cycles = append(cycles, scc)
+		}
+	}
+	for _, n := range g.Nodes {
+		if _, seen := order[n.ID]; !seen {
+			visit(n.ID)
+		}
+	}
+	return cycles
+}
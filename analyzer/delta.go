@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// RepoDefragDelta is how the current run differs from a previous JSON report (--baseline).
+// Workflows are matched by their path relative to each report's root.
+type RepoDefragDelta struct {
+	Baseline            string         `json:"baseline"`
+	BaselineGeneratedAt time.Time      `json:"baselineGeneratedAt"`
+	AddedWorkflows      []string       `json:"addedWorkflows,omitempty"`
+	RemovedWorkflows    []string       `json:"removedWorkflows,omitempty"`
+	NewStale            []string       `json:"newStaleWorkflows,omitempty"`
+	NoLongerStale       []string       `json:"noLongerStaleWorkflows,omitempty"`
+	NewUnpinned         []string       `json:"newlyUnpinnedWorkflows,omitempty"`
+	NowPinned           []string       `json:"nowPinnedWorkflows,omitempty"`
+	NewFindings         []DeltaFinding `json:"newFindings,omitempty"`
+	ResolvedFindings    []DeltaFinding `json:"resolvedFindings,omitempty"`
+	// SummaryChange is the current minus the baseline value of each summary count
+	SummaryChange map[string]int `json:"summaryChange"`
+}
+
+// DeltaFinding is a finding that appeared or was resolved since the baseline
+type DeltaFinding struct {
+	Workflow string   `json:"workflow"`
+	RuleID   string   `json:"ruleId"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// DiffReports compares the workflows of r against base. Findings are matched by rule, job, step
+// and message within the same workflow, so moving the repository root does not count as a change.
+func DiffReports(r *Report, base *Report, path string) *RepoDefragDelta {
+	d := &RepoDefragDelta{Baseline: path, BaselineGeneratedAt: base.GeneratedAt}
+	prev := map[string]WorkflowReport{}
+	for _, w := range base.Workflows {
+		prev[relToRoot(base.RootPath, w.File)] = w
+	}
+	seen := map[string]bool{}
+	for _, w := range r.Workflows {
+		rel := relToRoot(r.RootPath, w.File)
+		seen[rel] = true
+		// A new workflow compares against an empty one, so its issues count as regressions
+		old, ok := prev[rel]
+		if !ok {
+			d.AddedWorkflows = append(d.AddedWorkflows, rel)
+		}
+		switch stale, wasStale := staleAt(w, r.StaleDays, r.GeneratedAt), ok && staleAt(old, base.StaleDays, base.GeneratedAt); {
+		case stale && !wasStale:
+			d.NewStale = append(d.NewStale, rel)
+		case !stale && wasStale:
+			d.NoLongerStale = append(d.NoLongerStale, rel)
+		}
+		switch {
+		case w.UsesUnpinnedAction && !old.UsesUnpinnedAction:
+			d.NewUnpinned = append(d.NewUnpinned, rel)
+		case !w.UsesUnpinnedAction && old.UsesUnpinnedAction:
+			d.NowPinned = append(d.NowPinned, rel)
+		}
+		d.NewFindings = append(d.NewFindings, findingsNotIn(rel, w.Findings, old.Findings)...)
+		d.ResolvedFindings = append(d.ResolvedFindings, findingsNotIn(rel, old.Findings, w.Findings)...)
+	}
+	for _, w := range base.Workflows {
+		rel := relToRoot(base.RootPath, w.File)
+		if seen[rel] {
+			continue
+		}
+		d.RemovedWorkflows = append(d.RemovedWorkflows, rel)
+		// Findings of a deleted workflow are resolved with it
+		d.ResolvedFindings = append(d.ResolvedFindings, findingsNotIn(rel, w.Findings, nil)...)
+	}
+	sort.Strings(d.RemovedWorkflows)
+
+	b, c := base.Summary, r.Summary
+	d.SummaryChange = map[string]int{
+		"workflowCount":               c.WorkflowCount - b.WorkflowCount,
+		"workflowsStale":              c.WorkflowsStale - b.WorkflowsStale,
+		"workflowsWithUnpinned":       c.WorkflowsWithUnpinned - b.WorkflowsWithUnpinned,
+		"workflowsWithoutConcurrency": c.WorkflowsWithoutConcurrency - b.WorkflowsWithoutConcurrency,
+		"workflowsWithoutPermissions": c.WorkflowsWithoutPermissions - b.WorkflowsWithoutPermissions,
+		"jobsWithoutTimeout":          c.JobsWithoutTimeout - b.JobsWithoutTimeout,
+	}
+	return d
+}
+
+// staleAt is WorkflowIsStale as of a report's generation time
+func staleAt(w WorkflowReport, daysStale int, at time.Time) bool {
+	return w.LastModified != nil && at.Sub(*w.LastModified) > time.Duration(daysStale)*24*time.Hour
+}
+
+// relToRoot is a workflow path relative to its report root, in slash form
+func relToRoot(root, file string) string {
+	if rel, err := filepath.Rel(root, file); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(file)
+}
+
+// findingsNotIn returns the findings of fs with no equal (rule, job, step, message) in other
+func findingsNotIn(workflow string, fs, other []Finding) []DeltaFinding {
+	key := func(f Finding) string { return f.RuleID + "|" + f.Job + "|" + f.Step + "|" + f.Message }
+	have := map[string]bool{}
+	for _, f := range other {
+		have[key(f)] = true
+	}
+	var out []DeltaFinding
+	for _, f := range fs {
+		if !have[key(f)] {
+			out = append(out, DeltaFinding{Workflow: workflow, RuleID: f.RuleID, Severity: f.Severity, Message: f.Message})
+		}
+	}
+	return out
+}
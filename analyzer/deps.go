@@ -0,0 +1,137 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Dependency is a direct dependency declared in a manifest
+type Dependency struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// DependencyFinding is a manifest entry that weakens supply-chain guarantees: an untagged
+// pseudo-version, a replace onto a local path, a floating range or a git source
+type DependencyFinding struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+var (
+	// rePseudoVersion matches vX.0.0-yyyymmddhhmmss-abcdefabcdef and the -pre.0./.0. variants
+	rePseudoVersion = regexp.MustCompile(`[-.]\d{14}-[0-9a-f]{12}(\+incompatible)?$`)
+	// reNpmGitHubShorthand matches npm's "owner/repo[#ref]" GitHub shorthand
+	reNpmGitHubShorthand = regexp.MustCompile(`^[\w.-]+/[\w.-]+(#.*)?$`)
+)
+
+// AuditGoMod lists the direct requirements of a go.mod and flags pseudo-versions and replace
+// directives that point at local directories
+func AuditGoMod(path string) ([]Dependency, []DependencyFinding, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read go.mod: %w", err)
+	}
+	var deps []Dependency
+	var findings []DependencyFinding
+	// block is the directive of the enclosing require ( ... ) / replace ( ... ) group
+	block := ""
+	for i, raw := range strings.Split(string(b), "\n") {
+		line, comment, _ := strings.Cut(raw, "//")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		directive := block
+		if block == "" {
+			if fields[0] != "require" && fields[0] != "replace" {
+				continue
+			}
+			if len(fields) > 1 && fields[1] == "(" {
+				block = fields[0]
+				continue
+			}
+			directive, fields = fields[0], fields[1:]
+		} else if fields[0] == ")" {
+			block = ""
+			continue
+		}
+		switch {
+		case directive == "require" && len(fields) >= 2 && !strings.Contains(comment, "indirect"):
+			deps = append(deps, Dependency{File: path, Line: i + 1, Name: fields[0], Version: fields[1]})
+			if rePseudoVersion.MatchString(fields[1]) {
+				findings = append(findings, DependencyFinding{File: path, Line: i + 1, Name: fields[0], Version: fields[1],
+					Reason: "pseudo-version: pins an untagged commit rather than a release"})
+			}
+		case directive == "replace" && len(fields) > 0:
+			_, target, _ := strings.Cut(line, "=>")
+			if to := strings.Fields(target); len(to) > 0 && isLocalModulePath(to[0]) {
+				findings = append(findings, DependencyFinding{File: path, Line: i + 1, Name: fields[0],
+					Reason: fmt.Sprintf("replace points to local path %s; CI builds depend on files outside the module", to[0])})
+			}
+		}
+	}
+	return deps, findings, nil
+}
+
+// isLocalModulePath reports whether a replace target is a filesystem path rather than a module
+func isLocalModulePath(p string) bool {
+	return strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") || strings.HasPrefix(p, "/") || p == "." || p == ".."
+}
+
+// AuditPackageJSON lists the dependencies, devDependencies, optionalDependencies and
+// peerDependencies of a package.json and flags floating (*, latest) and git sources
+func AuditPackageJSON(path string) ([]Dependency, []DependencyFinding, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read package.json: %w", err)
+	}
+	var pkg map[string]json.RawMessage
+	if err := json.Unmarshal(b, &pkg); err != nil {
+		return nil, nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	var deps []Dependency
+	var findings []DependencyFinding
+	for _, section := range []string{"dependencies", "devDependencies", "optionalDependencies", "peerDependencies"} {
+		var specs map[string]string
+		if raw, ok := pkg[section]; !ok || json.Unmarshal(raw, &specs) != nil {
+			continue
+		}
+		var names []string
+		for name := range specs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			spec := specs[name]
+			deps = append(deps, Dependency{File: path, Name: name, Version: spec})
+			if reason := npmSpecRisk(spec); reason != "" {
+				findings = append(findings, DependencyFinding{File: path, Name: name, Version: spec, Reason: reason})
+			}
+		}
+	}
+	return deps, findings, nil
+}
+
+// npmSpecRisk explains why a package.json version spec is unsafe, or returns ""
+func npmSpecRisk(spec string) string {
+	s := strings.TrimSpace(spec)
+	switch {
+	case s == "" || s == "*" || s == "x" || strings.EqualFold(s, "latest"):
+		return fmt.Sprintf("floating version %q: any future release is installed", spec)
+	case strings.HasPrefix(s, "git+") || strings.HasPrefix(s, "git://") || strings.HasPrefix(s, "github:") ||
+		strings.HasPrefix(s, "gitlab:") || strings.HasPrefix(s, "bitbucket:") || strings.HasPrefix(s, "gist:") ||
+		(strings.HasPrefix(s, "http") && strings.Contains(s, ".git")) || reNpmGitHubShorthand.MatchString(s):
+		return "git source: installs whatever the branch or ref points to, bypassing the registry"
+	}
+	return ""
+}
@@ -0,0 +1,15 @@
+// Package analyzer implements rrctl's repository analysis as an importable API: workflow and local
+// action scanning, GitHub API enrichment, baseline comparison, strict workflow validation and
+// secret scanning. Every check reports typed Findings tagged with a rule ID and severity.
+//
+// The rrctl commands are thin wrappers over this package, so results match the CLI exactly:
+//
+//	report, err := analyzer.Analyze(analyzer.Options{Root: ".", StaleDays: 60})
+//	secrets, suppressed, err := analyzer.ScanSecrets(".", analyzer.DefaultSecretScanOptions)
+//
+// Analyze is the complete repo-defrag pipeline and returns the Report that --json writes; the scan
+// functions it composes (ScanWorkflowDirs, ScanActions, EnrichFromGitHub) are usable on their own.
+//
+// Everything a CLI flag tunes is passed in: ScanOptions (embedded in Options) for the analysis
+// itself and GitHubOptions for the API client, so independent calls never share settings.
+package analyzer
@@ -0,0 +1,34 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// utf8BOM is the byte order mark some Windows editors prepend to UTF-8 files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// EncodingIssues reports a leading UTF-8 BOM and the number of CRLF-terminated lines
+func EncodingIssues(raw []byte) (hasBOM bool, crlfLines int) {
+	return bytes.HasPrefix(raw, utf8BOM), bytes.Count(raw, []byte("\r\n"))
+}
+
+// NormalizeEncoding strips a leading BOM and converts CRLF line endings to LF
+func NormalizeEncoding(raw []byte) []byte {
+	return bytes.ReplaceAll(bytes.TrimPrefix(raw, utf8BOM), []byte("\r\n"), []byte("\n"))
+}
+
+// detectEncodingIssues flags BOM/CRLF workflow files, which can push analysis into the regex
+// fallback and occasionally trip GitHub's own parser
+func detectEncodingIssues(file string, raw []byte) []Finding {
+	bom, crlf := EncodingIssues(raw)
+	var out []Finding
+	if bom {
+		out = append(out, newFinding("workflow.encoding", file, "File starts with a UTF-8 BOM; save as UTF-8 without BOM (repo-autofix --normalize-encoding)"))
+	}
+	if crlf > 0 {
+		out = append(out, newFinding("workflow.encoding", file,
+			fmt.Sprintf("File uses CRLF line endings (%d lines); convert to LF (repo-autofix --normalize-encoding) or add `*.yml text eol=lf` to .gitattributes", crlf)))
+	}
+	return out
+}
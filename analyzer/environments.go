@@ -0,0 +1,126 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// EnvironmentJob records the secrets referenced by a job that deploys to an environment
+type EnvironmentJob struct {
+	Job         string   `json:"job"`
+	Environment string   `json:"environment"`
+	Secrets     []string `json:"secrets,omitempty"`
+}
+
+var (
+	reExpr      = regexp.MustCompile(`\$\{\{(.*?)\}\}`)
+	reSecretRef = regexp.MustCompile(`\bsecrets\.([A-Za-z0-9_]+)`)
+)
+
+// extractEnvironmentJobs lists jobs with an environment: and every secret they reference
+func extractEnvironmentJobs(root map[string]any) []EnvironmentJob {
+	jobs, _ := root["jobs"].(map[string]any)
+	var ids []string
+	for id := range jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var out []EnvironmentJob
+	for _, id := range ids {
+		jm, ok := jobs[id].(map[string]any)
+		if !ok {
+			continue
+		}
+		env := jobEnvironment(jm)
+		if env == "" {
+			continue
+		}
+		set := map[string]bool{}
+		collectSecretRefs(jm, set)
+		delete(set, "GITHUB_TOKEN")
+		ej := EnvironmentJob{Job: id, Environment: env}
+		for s := range set {
+			ej.Secrets = append(ej.Secrets, s)
+		}
+		sort.Strings(ej.Secrets)
+		out = append(out, ej)
+	}
+	return out
+}
+
+// collectSecretRefs walks any decoded YAML value for secrets.NAME references inside ${{ }}
+func collectSecretRefs(v any, set map[string]bool) {
+	switch vv := v.(type) {
+	case string:
+		for _, expr := range reExpr.FindAllStringSubmatch(vv, -1) {
+			for _, m := range reSecretRef.FindAllStringSubmatch(expr[1], -1) {
+				set[m[1]] = true
+			}
+		}
+	case map[string]any:
+		for _, it := range vv {
+			collectSecretRefs(it, set)
+		}
+	case []any:
+		for _, it := range vv {
+			collectSecretRefs(it, set)
+		}
+	}
+}
+
+// DetectRepoSecretsInEnvironmentJobs flags environment jobs reading secrets that are not scoped to
+// that environment, so the environment's protection rules do not guard them. It needs the
+// environment secret names from GitHub enrichment and reports nothing when they are unknown.
+func DetectRepoSecretsInEnvironmentJobs(w WorkflowReport, gh *GitHubReport) []Finding {
+	if gh == nil {
+		return nil
+	}
+	envs := map[string]EnvironmentProbe{}
+	for _, e := range gh.Environments {
+		envs[e.Name] = e
+	}
+	var out []Finding
+	for _, ej := range w.EnvironmentJobs {
+		env, ok := envs[ej.Environment]
+		if !ok || !env.SecretsListed {
+			continue
+		}
+		var unscoped []string
+		for _, s := range ej.Secrets {
+			if !containsFold(env.Secrets, s) {
+				unscoped = append(unscoped, s)
+			}
+		}
+		if len(unscoped) == 0 {
+			continue
+		}
+		scope := "repository- or organization-level"
+		if gh.RepoSecrets != nil {
+			scope = "repository-level"
+			for _, s := range unscoped {
+				if !containsFold(gh.RepoSecrets, s) {
+					scope = "repository- or organization-level"
+					break
+				}
+			}
+		}
+		f := newFinding("workflow.env-job-repo-secret", w.File,
+			fmt.Sprintf("job:%s deploys to environment %s but reads %s secrets %s; move them to the %s environment so its protection rules gate access",
+				ej.Job, ej.Environment, scope, strings.Join(unscoped, ", "), ej.Environment))
+		f.Job = ej.Job
+		out = append(out, f)
+	}
+	return out
+}
+
+// containsFold is containsString for GitHub secret names, which are case-insensitive
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,283 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Severity ranks how urgently a finding should be addressed
+type Severity string
+
+const (
+	SeverityLow    Severity = "low"
+	SeverityMedium Severity = "medium"
+	SeverityHigh   Severity = "high"
+)
+
+var severityRank = map[Severity]int{
+	SeverityLow:    1,
+	SeverityMedium: 2,
+	SeverityHigh:   3,
+}
+
+// ParseSeverity parses a case-insensitive low|medium|high level
+func ParseSeverity(s string) (Severity, error) {
+	sev := Severity(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := severityRank[sev]; !ok {
+		return "", fmt.Errorf("unknown severity %q (want low|medium|high)", s)
+	}
+	return sev, nil
+}
+
+// AtLeast reports whether s is at or above the threshold t
+func (s Severity) AtLeast(t Severity) bool {
+	return severityRank[s] >= severityRank[t]
+}
+
+// Rule is a catalog entry describing a single check
+type Rule struct {
+	ID          string   `json:"id"`
+	Severity    Severity `json:"severity"`
+	Description string   `json:"description"`
+}
+
+// ruleCatalog lists every check that can produce a finding, with its default severity
+var ruleCatalog = map[string]Rule{
+	"workflow.stale":                   {ID: "workflow.stale", Severity: SeverityLow, Description: "Workflow not modified within the stale threshold"},
+	"workflow.unpinned-action":         {ID: "workflow.unpinned-action", Severity: SeverityMedium, Description: "Action referenced by a mutable branch or without a ref"},
+	"workflow.no-concurrency":          {ID: "workflow.no-concurrency", Severity: SeverityLow, Description: "Workflow has no concurrency control"},
+	"workflow.partial-concurrency":     {ID: "workflow.partial-concurrency", Severity: SeverityLow, Description: "Only some jobs declare concurrency and the workflow itself declares none"},
+	"workflow.no-permissions":          {ID: "workflow.no-permissions", Severity: SeverityMedium, Description: "Workflow declares no token permissions, so jobs inherit the repository's default GITHUB_TOKEN scopes"},
+	"workflow.job-no-timeout":          {ID: "workflow.job-no-timeout", Severity: SeverityLow, Description: "Job sets no timeout-minutes and can run for the 6-hour default"},
+	"workflow.matrix-large":            {ID: "workflow.matrix-large", Severity: SeverityLow, Description: "Job's strategy.matrix expands to more jobs than the matrix threshold"},
+	"workflow.matrix-no-fail-fast":     {ID: "workflow.matrix-no-fail-fast", Severity: SeverityLow, Description: "Matrix job leaves strategy.fail-fast at its implicit default (true)"},
+	"workflow.matrix-redundant":        {ID: "workflow.matrix-redundant", Severity: SeverityLow, Description: "Matrix exclude entry matches no combination, or include entry repeats one"},
+	"workflow.no-runs-on":              {ID: "workflow.no-runs-on", Severity: SeverityLow, Description: "No runs-on label found for the workflow's jobs"},
+	"workflow.setup-no-cache":          {ID: "workflow.setup-no-cache", Severity: SeverityLow, Description: "setup-go/node/python step runs without its built-in dependency cache"},
+	"workflow.reusable-unpinned":       {ID: "workflow.reusable-unpinned", Severity: SeverityMedium, Description: "Job calls a remote reusable workflow without a ref or on a mutable branch"},
+	"workflow.reusable-missing":        {ID: "workflow.reusable-missing", Severity: SeverityHigh, Description: "Job calls a local reusable workflow that does not exist"},
+	"workflow.push-no-path-filter":     {ID: "workflow.push-no-path-filter", Severity: SeverityLow, Description: "push trigger has no paths/paths-ignore filter, so every push runs the workflow"},
+	"gitlab.no-rules":                  {ID: "gitlab.no-rules", Severity: SeverityLow, Description: "GitLab job has no rules/only/except and runs in every pipeline"},
+	"gitlab.unpinned-image":            {ID: "gitlab.unpinned-image", Severity: SeverityMedium, Description: "GitLab job or service image has no tag or uses latest"},
+	"gitlab.no-interruptible":          {ID: "gitlab.no-interruptible", Severity: SeverityLow, Description: "GitLab job is not interruptible, so superseded pipelines keep running it"},
+	"gitlab.stale-schedule":            {ID: "gitlab.stale-schedule", Severity: SeverityLow, Description: "Scheduled GitLab job in a config not modified within the stale threshold"},
+	"azure.deprecated-image":           {ID: "azure.deprecated-image", Severity: SeverityMedium, Description: "Azure Pipelines job runs on a retired Microsoft-hosted vmImage"},
+	"azure.unpinned-task":              {ID: "azure.unpinned-task", Severity: SeverityMedium, Description: "Azure Pipelines task referenced without a version or with @*"},
+	"azure.unscoped-trigger":           {ID: "azure.unscoped-trigger", Severity: SeverityLow, Description: "Azure Pipelines trigger or pr is missing or covers every branch"},
+	"action.deprecated-runtime":        {ID: "action.deprecated-runtime", Severity: SeverityMedium, Description: "Local action declares an EOL Node runtime in runs.using"},
+	"workflow.artifact-secrets":        {ID: "workflow.artifact-secrets", Severity: SeverityHigh, Description: "Uploaded artifact path likely includes credential files"},
+	"workflow.deprecated-action":       {ID: "workflow.deprecated-action", Severity: SeverityMedium, Description: "Step uses a deprecated or sunset major version of an action"},
+	"workflow.deprecated-input":        {ID: "workflow.deprecated-input", Severity: SeverityMedium, Description: "Setup action step uses an input that is ignored in the referenced version"},
+	"workflow.deploy-without-needs":    {ID: "workflow.deploy-without-needs", Severity: SeverityLow, Description: "Deploy-looking job does not depend on the workflow's build/test jobs"},
+	"workflow.deploy-no-concurrency":   {ID: "workflow.deploy-no-concurrency", Severity: SeverityHigh, Description: "Deploy job has no workflow- or job-level concurrency, so runs can race to the same environment"},
+	"workflow.env-job-repo-secret":     {ID: "workflow.env-job-repo-secret", Severity: SeverityLow, Description: "Environment-targeting job reads secrets not scoped to that environment"},
+	"workflow.encoding":                {ID: "workflow.encoding", Severity: SeverityMedium, Description: "Workflow file has a UTF-8 BOM or CRLF line endings"},
+	"workflow.env-file-injection":      {ID: "workflow.env-file-injection", Severity: SeverityHigh, Description: "Untrusted event data written to $GITHUB_ENV or $GITHUB_PATH"},
+	"workflow.inline-secret":           {ID: "workflow.inline-secret", Severity: SeverityHigh, Description: "Credential-named key (token, password, secret, API key) set to a literal value in the workflow"},
+	"workflow.input-injection":         {ID: "workflow.input-injection", Severity: SeverityMedium, Description: "workflow_dispatch/workflow_call input interpolated directly into a run: script"},
+	"workflow.id-token-unused":         {ID: "workflow.id-token-unused", Severity: SeverityMedium, Description: "id-token: write granted to jobs that never request an OIDC token"},
+	"workflow.long-lived-cloud-creds":  {ID: "workflow.long-lived-cloud-creds", Severity: SeverityMedium, Description: "Cloud login uses long-lived secrets where OIDC federation is available"},
+	"workflow.invalid-yaml":            {ID: "workflow.invalid-yaml", Severity: SeverityHigh, Description: "Workflow file is not valid YAML"},
+	"workflow.invalid-structure":       {ID: "workflow.invalid-structure", Severity: SeverityHigh, Description: "Workflow YAML does not have the expected on/jobs/steps shape"},
+	"workflow.issue-comment-unguarded": {ID: "workflow.issue-comment-unguarded", Severity: SeverityHigh, Description: "issue_comment-triggered workflow does not check the commenter's author_association"},
+	"workflow.pipe-to-shell":           {ID: "workflow.pipe-to-shell", Severity: SeverityHigh, Description: "run: step pipes a downloaded script straight into a shell"},
+	"workflow.secret-in-run-args":      {ID: "workflow.secret-in-run-args", Severity: SeverityMedium, Description: "Secret expression interpolated into a run: command line instead of passed via env"},
+	"workflow.pr-target-checkout":      {ID: "workflow.pr-target-checkout", Severity: SeverityHigh, Description: "pull_request_target workflow checks out the pull request head, running fork code with a write token and secrets"},
+	"workflow.workflow-run-untrusted":  {ID: "workflow.workflow-run-untrusted", Severity: SeverityHigh, Description: "workflow_run workflow with secrets checks out or downloads artifacts from the triggering run"},
+	"secret.keyword":                   {ID: "secret.keyword", Severity: SeverityMedium, Description: "File mentions a secret-like keyword (password, token, api_key, ...)"},
+	"secret.high-entropy":              {ID: "secret.high-entropy", Severity: SeverityHigh, Description: "High-entropy token (likely an API key, password or private key material)"},
+	"secret.pattern":                   {ID: "secret.pattern", Severity: SeverityHigh, Description: "Line matches a known token format (built-in provider rules or --rules); severity comes from the rule"},
+	"secret.url-credentials":           {ID: "secret.url-credentials", Severity: SeverityHigh, Description: "Git or CI config embeds user:password credentials in a URL"},
+	"file.world-writable":              {ID: "file.world-writable", Severity: SeverityMedium, Description: "File is group- or world-writable, so other local users can modify it"},
+	"file.sensitive-readable":          {ID: "file.sensitive-readable", Severity: SeverityMedium, Description: "Credential-like file (.env, id_rsa, *.pem, .netrc, *.key, ...) readable by group or other users"},
+	"workflow.skips-default-branch":    {ID: "workflow.skips-default-branch", Severity: SeverityLow, Description: "Branch filters never match the repository's default branch"},
+}
+
+// RuleIDs lists the IDs of catalog rules starting with prefix (such as "workflow."), sorted
+func RuleIDs(prefix string) []string {
+	var ids []string
+	for id := range ruleCatalog {
+		if strings.HasPrefix(id, prefix) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ParseSeverityOverrides parses rule=level specs (--severity-override), validated against the
+// catalog, into ScanOptions.SeverityOverrides
+func ParseSeverityOverrides(specs []string) (map[string]Severity, error) {
+	overrides := map[string]Severity{}
+	for _, spec := range specs {
+		id, level, ok := strings.Cut(spec, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid severity override %q (want rule=level)", spec)
+		}
+		id = strings.TrimSpace(id)
+		if _, known := ruleCatalog[id]; !known {
+			return nil, fmt.Errorf("invalid severity override %q: unknown rule %q", spec, id)
+		}
+		sev, err := ParseSeverity(level)
+		if err != nil {
+			return nil, fmt.Errorf("invalid severity override %q: %w", spec, err)
+		}
+		overrides[id] = sev
+	}
+	return overrides, nil
+}
+
+// OverrideSeverities sets the severity of every finding whose rule is in overrides, in place
+func OverrideSeverities(fs []Finding, overrides map[string]Severity) {
+	if len(overrides) == 0 {
+		return
+	}
+	for i := range fs {
+		if sev, ok := overrides[fs[i].RuleID]; ok {
+			fs[i].Severity = sev
+		}
+	}
+}
+
+// Finding is a single rule violation
+type Finding struct {
+	RuleID      string   `json:"ruleId"`
+	Severity    Severity `json:"severity"`
+	File        string   `json:"file"`
+	Job         string   `json:"job,omitempty"`
+	Step        string   `json:"step,omitempty"`
+	Line        int      `json:"line,omitempty"`
+	Column      int      `json:"column,omitempty"`
+	Message     string   `json:"message"`
+	Fingerprint string   `json:"fingerprint,omitempty"`
+}
+
+// fingerprintFinding derives a stable identity for a finding that ignores its (overridable) severity
+func fingerprintFinding(f Finding) string {
+	sum := sha256.Sum256([]byte(f.RuleID + "|" + filepath.ToSlash(f.File) + "|" + f.Job + "|" + f.Step + "|" + f.Message))
+	return hex.EncodeToString(sum[:8])
+}
+
+// AssignFingerprints sets the Fingerprint of every finding in place
+func AssignFingerprints(fs []Finding) {
+	for i := range fs {
+		fs[i].Fingerprint = fingerprintFinding(fs[i])
+	}
+}
+
+func newFinding(ruleID, file, message string) Finding {
+	return Finding{RuleID: ruleID, Severity: ruleCatalog[ruleID].Severity, File: file, Message: message}
+}
+
+func newStepFinding(ruleID, file, job, step, message string) Finding {
+	f := newFinding(ruleID, file, message)
+	f.Job, f.Step = job, step
+	return f
+}
+
+// PermissionFinding reports a group/world-writable file found by security-scan's permission check
+func PermissionFinding(file, mode string) Finding {
+	return newFinding("file.world-writable", file, fmt.Sprintf("File is group/world-writable (mode %s); clear the write bits with chmod go-w", mode))
+}
+
+// StopError reports the finding that made a scan stop early
+type StopError struct {
+	Finding Finding
+}
+
+func (e *StopError) Error() string {
+	return fmt.Sprintf("stopped at %s finding in %s: %s [%s]", e.Finding.Severity, e.Finding.File, e.Finding.Message, e.Finding.RuleID)
+}
+
+// FilterFindings drops findings below the minimum severity
+func FilterFindings(fs []Finding, min Severity) []Finding {
+	if min == "" {
+		return fs
+	}
+	var out []Finding
+	for _, f := range fs {
+		if f.Severity.AtLeast(min) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// SeverityTagged renders findings as "[severity] message" for human-readable reports
+func SeverityTagged(fs []Finding) []string {
+	var out []string
+	for _, f := range fs {
+		out = append(out, fmt.Sprintf("[%s] %s", f.Severity, f.Message))
+	}
+	return out
+}
+
+// FindingMessages returns the bare messages, as stored in WorkflowReport.Recommendations
+func FindingMessages(fs []Finding) []string {
+	var out []string
+	for _, f := range fs {
+		out = append(out, f.Message)
+	}
+	return out
+}
+
+// CountAtLeast counts findings at or above the threshold, grouped by rule for error messages
+func CountAtLeast(fs []Finding, t Severity) (int, map[string]int) {
+	n := 0
+	byRule := map[string]int{}
+	for _, f := range fs {
+		if f.Severity.AtLeast(t) {
+			n++
+			byRule[f.RuleID]++
+		}
+	}
+	return n, byRule
+}
+
+// FormatRuleCounts renders per-rule counts as a sorted "rule=n, ..." list
+func FormatRuleCounts(byRule map[string]int) string {
+	var ids []string
+	for id := range byRule {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var parts []string
+	for _, id := range ids {
+		parts = append(parts, fmt.Sprintf("%s=%d", id, byRule[id]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SortFindings stably orders findings by "severity" (most severe first), "rule" or "file".
+// Ties keep detection order, so output stays deterministic.
+func SortFindings(fs []Finding, by string) {
+	sort.SliceStable(fs, func(i, j int) bool {
+		a, b := fs[i], fs[j]
+		switch by {
+		case "severity":
+			if severityRank[a.Severity] != severityRank[b.Severity] {
+				return severityRank[a.Severity] > severityRank[b.Severity]
+			}
+		case "rule":
+			if a.RuleID != b.RuleID {
+				return a.RuleID < b.RuleID
+			}
+		}
+		return a.File < b.File
+	})
+}
+
+// topSeverityRank is the rank of the most severe finding (0 when there are none)
+func topSeverityRank(fs []Finding) int {
+	top := 0
+	for _, f := range fs {
+		if r := severityRank[f.Severity]; r > top {
+			top = r
+		}
+	}
+	return top
+}
@@ -0,0 +1,616 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type GitHubReport struct {
+	Owner           string            `json:"owner"`
+	Repo            string            `json:"repo"`
+	DefaultBranch   string            `json:"defaultBranch,omitempty"`
+	WorkflowFailure []WorkflowFailure `json:"workflowFailureRates,omitempty"`
+	PRs             []PRReport        `json:"pullRequests,omitempty"`
+	// PRsTruncated is set when listing stopped at GitHubOptions.MaxPRs open pull requests
+	PRsTruncated bool               `json:"pullRequestsTruncated,omitempty"`
+	Environments []EnvironmentProbe `json:"environments,omitempty"`
+	// RepoSecrets are repository-level secret names (nil when the token may not list them)
+	RepoSecrets []string     `json:"repoSecrets,omitempty"`
+	Caches      *CacheReport `json:"actionsCaches,omitempty"`
+	// Partial is set when the context was cancelled or timed out before every section was fetched
+	Partial bool `json:"partial,omitempty"`
+}
+
+// CacheReport summarizes the repository's GitHub Actions caches
+type CacheReport struct {
+	Count          int          `json:"count"`
+	TotalSizeBytes int64        `json:"totalSizeBytes"`
+	Oldest         []CacheEntry `json:"oldest,omitempty"`
+	Stale          []CacheEntry `json:"stale,omitempty"`
+	StaleSizeBytes int64        `json:"staleSizeBytes"`
+}
+
+type CacheEntry struct {
+	ID             int64     `json:"id"`
+	Key            string    `json:"key"`
+	Ref            string    `json:"ref"`
+	SizeBytes      int64     `json:"sizeBytes"`
+	CreatedAt      time.Time `json:"createdAt"`
+	LastAccessedAt time.Time `json:"lastAccessedAt"`
+}
+
+type WorkflowFailure struct {
+	Name          string  `json:"name"`
+	WorkflowID    int64   `json:"workflowId"`
+	SampledRuns   int     `json:"sampledRuns"`
+	FailureRate   float64 `json:"failureRate"`
+	RecentFailure bool    `json:"recentFailure"`
+	Skipped       bool    `json:"skipped,omitempty"`
+	SkipReason    string  `json:"skipReason,omitempty"`
+}
+
+type PRReport struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	Draft     bool      `json:"draft"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Stale     bool      `json:"stale"`
+	HeadSHA   string    `json:"headSha"`
+}
+
+type EnvironmentProbe struct {
+	Name         string     `json:"name"`
+	LastDeployed *time.Time `json:"lastDeployed,omitempty"`
+	IsStale      bool       `json:"isStale"`
+	// ProtectionRules are the configured rule types, e.g. required_reviewers, wait_timer(10m), branch_policy
+	ProtectionRules []string `json:"protectionRules,omitempty"`
+	// Unprotected is set when the environment has no protection rules, so any workflow run can deploy to it
+	Unprotected bool `json:"unprotected"`
+	// Secrets are the environment-scoped secret names; SecretsListed is false when listing failed
+	Secrets       []string `json:"secrets,omitempty"`
+	SecretsListed bool     `json:"secretsListed,omitempty"`
+}
+
+// EnrichFromGitHub queries the GitHub API for workflow failure rates, open PRs, environment
+// deployments, secret names and Actions caches. It is a minimal client: failed per-workflow run,
+// deployment, secret or cache lookups are skipped, any other failure aborts.
+//
+// Cancelling ctx aborts the request in flight; the sections fetched so far are returned as a
+// Partial report together with the context's error.
+func EnrichFromGitHub(ctx context.Context, opts GitHubOptions, daysStale int) (*GitHubReport, error) {
+	base := fmt.Sprintf("%s/repos/%s/%s", opts.apiURL(), opts.Owner, opts.Repo)
+	cli := newGHClient(opts)
+	gr := &GitHubReport{Owner: opts.Owner, Repo: opts.Repo}
+	fail := func(err error) (*GitHubReport, error) {
+		if ctx.Err() != nil {
+			gr.Partial = true
+			return gr, fmt.Errorf("GitHub enrichment incomplete: %w", ctx.Err())
+		}
+		return nil, err
+	}
+
+	// Repository metadata (default branch)
+	var meta struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := cli.get(ctx, base, &meta); err != nil {
+		return fail(err)
+	}
+	gr.DefaultBranch = meta.DefaultBranch
+
+	// Workflows list
+	type ghWorkflow struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+	type wfResp struct {
+		Workflows []ghWorkflow `json:"workflows"`
+	}
+	var workflows []ghWorkflow
+	for url := base + "/actions/workflows?per_page=100"; url != ""; {
+		var wf wfResp
+		next, err := cli.getPage(ctx, url, &wf)
+		if err != nil {
+			return fail(err)
+		}
+		workflows = append(workflows, wf.Workflows...)
+		url = next
+	}
+
+	for i, w := range workflows {
+		// Runs for each workflow
+		runs, err := fetchWorkflowRuns(ctx, cli, base, w.ID, opts.SampleRuns)
+		Progress("GitHub workflow run histories", i+1, len(workflows))
+		if ctx.Err() != nil {
+			return fail(err)
+		}
+		if err != nil {
+			gr.WorkflowFailure = append(gr.WorkflowFailure, WorkflowFailure{Name: w.Name, WorkflowID: w.ID, Skipped: true, SkipReason: err.Error()})
+			continue
+		}
+		total := len(runs)
+		if total == 0 {
+			continue
+		}
+		fails := 0
+		recentFail := false
+		for i, r := range runs {
+			if r.Conclusion == "failure" || r.Conclusion == "timed_out" || r.Conclusion == "cancelled" {
+				fails++
+				if i == 0 {
+					recentFail = true
+				}
+			}
+		}
+		gr.WorkflowFailure = append(gr.WorkflowFailure, WorkflowFailure{Name: w.Name, WorkflowID: w.ID, SampledRuns: total, FailureRate: float64(fails) / float64(total), RecentFailure: recentFail})
+	}
+
+	// PRs
+	type ghPR struct {
+		Number    int       `json:"number"`
+		Title     string    `json:"title"`
+		Draft     bool      `json:"draft"`
+		UpdatedAt time.Time `json:"updated_at"`
+		User      struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	var prs []ghPR
+	for url := base + "/pulls?state=open&per_page=100"; url != ""; {
+		var page []ghPR
+		next, err := cli.getPage(ctx, url, &page)
+		if err != nil {
+			return fail(err)
+		}
+		prs = append(prs, page...)
+		if opts.MaxPRs > 0 && len(prs) >= opts.MaxPRs {
+			gr.PRsTruncated = next != "" || len(prs) > opts.MaxPRs
+			prs = prs[:opts.MaxPRs]
+			break
+		}
+		url = next
+	}
+	for _, p := range prs {
+		stale := time.Since(p.UpdatedAt) > (time.Duration(daysStale) * 24 * time.Hour)
+		gr.PRs = append(gr.PRs, PRReport{Number: p.Number, Title: p.Title, Author: p.User.Login, Draft: p.Draft, UpdatedAt: p.UpdatedAt, Stale: stale, HeadSHA: p.Head.SHA})
+	}
+
+	// Environments. The list returns full environment objects, protection_rules included, so
+	// there is no need to fetch /environments/{name} one by one.
+	type ghEnvironment struct {
+		Name            string `json:"name"`
+		ProtectionRules []struct {
+			Type      string `json:"type"`
+			WaitTimer int    `json:"wait_timer"`
+		} `json:"protection_rules"`
+	}
+	var environments []ghEnvironment
+	for url := base + "/environments?per_page=100"; url != ""; {
+		var envs struct {
+			Environments []ghEnvironment `json:"environments"`
+		}
+		next, err := cli.getPage(ctx, url, &envs)
+		if err != nil {
+			return fail(err)
+		}
+		environments = append(environments, envs.Environments...)
+		url = next
+	}
+	for _, e := range environments {
+		// deployments (most recent)
+		var deps []struct {
+			UpdatedAt time.Time `json:"updated_at"`
+		}
+		if err := cli.get(ctx, base+"/deployments?per_page=1&environment="+urlQueryEscape(e.Name), &deps); err != nil {
+			if ctx.Err() != nil {
+				return fail(err)
+			}
+			continue
+		}
+		var last *time.Time
+		if len(deps) > 0 {
+			last = &deps[0].UpdatedAt
+		}
+		stale := true
+		if last != nil {
+			stale = time.Since(*last) > (time.Duration(daysStale) * 24 * time.Hour)
+		}
+		probe := EnvironmentProbe{Name: e.Name, LastDeployed: last, IsStale: stale, Unprotected: len(e.ProtectionRules) == 0}
+		for _, r := range e.ProtectionRules {
+			rule := r.Type
+			if r.Type == "wait_timer" {
+				rule = fmt.Sprintf("wait_timer(%dm)", r.WaitTimer)
+			}
+			probe.ProtectionRules = append(probe.ProtectionRules, rule)
+		}
+		// Listing secrets needs admin access; without it the scope check is skipped for this environment
+		if names, err := listSecretNames(ctx, cli, base+"/environments/"+urlQueryEscape(e.Name)+"/secrets"); err == nil {
+			probe.Secrets, probe.SecretsListed = names, true
+		}
+		gr.Environments = append(gr.Environments, probe)
+	}
+	gr.RepoSecrets, _ = listSecretNames(ctx, cli, base+"/actions/secrets")
+
+	// Caches are optional insight; a failed listing leaves the section out
+	gr.Caches, _ = fetchCaches(ctx, cli, base, daysStale)
+	if ctx.Err() != nil {
+		return fail(ctx.Err())
+	}
+	return gr, nil
+}
+
+// oldestCachesShown bounds the oldest-caches list in the report
+const oldestCachesShown = 5
+
+// fetchCaches pages through the Actions caches API and summarizes size, the oldest entries, and
+// entries not accessed within daysStale
+func fetchCaches(ctx context.Context, cli *ghClient, base string, daysStale int) (*CacheReport, error) {
+	type ghCache struct {
+		ID             int64     `json:"id"`
+		Key            string    `json:"key"`
+		Ref            string    `json:"ref"`
+		SizeInBytes    int64     `json:"size_in_bytes"`
+		CreatedAt      time.Time `json:"created_at"`
+		LastAccessedAt time.Time `json:"last_accessed_at"`
+	}
+	var all []CacheEntry
+	for page := 1; ; page++ {
+		var resp struct {
+			TotalCount int       `json:"total_count"`
+			Caches     []ghCache `json:"actions_caches"`
+		}
+		if err := cli.get(ctx, fmt.Sprintf("%s/actions/caches?per_page=100&page=%d&sort=created_at&direction=asc", base, page), &resp); err != nil {
+			return nil, fmt.Errorf("fetch caches page %d: %w", page, err)
+		}
+		for _, c := range resp.Caches {
+			all = append(all, CacheEntry{ID: c.ID, Key: c.Key, Ref: c.Ref, SizeBytes: c.SizeInBytes, CreatedAt: c.CreatedAt, LastAccessedAt: c.LastAccessedAt})
+		}
+		if len(resp.Caches) < 100 || len(all) >= resp.TotalCount {
+			break
+		}
+	}
+
+	cr := &CacheReport{Count: len(all)}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].CreatedAt.Before(all[j].CreatedAt) })
+	for _, c := range all {
+		cr.TotalSizeBytes += c.SizeBytes
+		if time.Since(c.LastAccessedAt) > time.Duration(daysStale)*24*time.Hour {
+			cr.Stale = append(cr.Stale, c)
+			cr.StaleSizeBytes += c.SizeBytes
+		}
+	}
+	cr.Oldest = all[:min(len(all), oldestCachesShown)]
+	return cr, nil
+}
+
+// listSecretNames returns the secret names from a repository or environment secrets endpoint
+func listSecretNames(ctx context.Context, cli *ghClient, url string) ([]string, error) {
+	var resp struct {
+		Secrets []struct {
+			Name string `json:"name"`
+		} `json:"secrets"`
+	}
+	if err := cli.get(ctx, url+"?per_page=100", &resp); err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, s := range resp.Secrets {
+		names = append(names, s.Name)
+	}
+	return names, nil
+}
+
+type workflowRun struct {
+	Conclusion string `json:"conclusion"`
+}
+
+// fetchWorkflowRuns pages through a workflow's most recent runs until sampleRuns are collected.
+// Transient failures are retried by the client (GitHubOptions.Retries); one that persists skips
+// the workflow.
+func fetchWorkflowRuns(ctx context.Context, cli *ghClient, base string, workflowID int64, sampleRuns int) ([]workflowRun, error) {
+	perPage := sampleRuns
+	if perPage > 100 {
+		perPage = 100
+	}
+	var runs []workflowRun
+	for page := 1; len(runs) < sampleRuns; page++ {
+		var rr struct {
+			WorkflowRuns []workflowRun `json:"workflow_runs"`
+		}
+		url := fmt.Sprintf("%s/actions/workflows/%d/runs?per_page=%d&page=%d", base, workflowID, perPage, page)
+		if err := cli.get(ctx, url, &rr); err != nil {
+			return nil, fmt.Errorf("fetch runs page %d: %w", page, err)
+		}
+		runs = append(runs, rr.WorkflowRuns...)
+		if len(rr.WorkflowRuns) < perPage {
+			break
+		}
+	}
+	if len(runs) > sampleRuns {
+		runs = runs[:sampleRuns]
+	}
+	return runs, nil
+}
+
+// ResolveActionSHA resolves an action repository's tag or branch (e.g. actions/checkout, v4) to
+// the full commit SHA it currently points at, through the API, token and retries of opts (Owner,
+// Repo and the enrichment settings are ignored). The token may be empty for public repositories.
+func ResolveActionSHA(ctx context.Context, opts GitHubOptions, repo, ref string) (string, error) {
+	var commit struct {
+		SHA string `json:"sha"`
+	}
+	if err := newGHClient(opts).get(ctx, fmt.Sprintf("%s/repos/%s/commits/%s", opts.apiURL(), repo, urlQueryEscape(ref)), &commit); err != nil {
+		return "", fmt.Errorf("resolve %s@%s: %w", repo, ref, err)
+	}
+	if len(commit.SHA) != 40 {
+		return "", fmt.Errorf("resolve %s@%s: unexpected sha %q", repo, ref, commit.SHA)
+	}
+	return commit.SHA, nil
+}
+
+// DefaultGitHubAPIURL is the REST API root of github.com
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+// DefaultGitHubRetries is the GitHubOptions.Retries rrctl uses unless --github-retries says otherwise
+const DefaultGitHubRetries = 3
+
+func (o GitHubOptions) apiURL() string {
+	if o.APIURL == "" {
+		return DefaultGitHubAPIURL
+	}
+	return strings.TrimRight(o.APIURL, "/")
+}
+
+// ParseGitHubAPIURL validates an API root given by a user, e.g. https://github.mycorp.com/api/v3/,
+// and returns it without trailing slashes
+func ParseGitHubAPIURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("invalid GitHub API URL %q: %w", raw, err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", fmt.Errorf("invalid GitHub API URL %q: want http(s)://host[/path], e.g. https://github.mycorp.com/api/v3", raw)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("invalid GitHub API URL %q: must not carry a query or fragment", raw)
+	}
+	return strings.TrimRight(u.String(), "/"), nil
+}
+
+const (
+	// maxRateLimitWait is the longest rrctl sleeps for a rate limit before giving up on the request
+	maxRateLimitWait = time.Minute
+	// retryBaseDelay is the backoff before the first retry of a failed request; it doubles per retry
+	retryBaseDelay = time.Second
+	// maxRetryDelay caps the backoff between retries
+	maxRetryDelay = 30 * time.Second
+)
+
+// ghClient sends the GET requests of one EnrichFromGitHub or ResolveActionSHA call with the
+// token, retries and response cache of its GitHubOptions
+type ghClient struct {
+	http     *http.Client
+	auth     ghAuth
+	retries  int
+	cacheDir string
+}
+
+func newGHClient(opts GitHubOptions) *ghClient {
+	c := &ghClient{http: &http.Client{Timeout: 15 * time.Second}, retries: opts.Retries, cacheDir: opts.CacheDir}
+	if opts.Token != "" {
+		RegisterSecret(opts.Token)
+		c.auth = ghAuth("token " + opts.Token)
+	}
+	return c
+}
+
+func (c *ghClient) get(ctx context.Context, url string, v any) error {
+	_, err := c.getPage(ctx, url, v)
+	return err
+}
+
+// getPage is get for list endpoints: it also returns the URL of the next page from the Link
+// header, or "" on the last page
+func (c *ghClient) getPage(ctx context.Context, url string, v any) (string, error) {
+	cached := c.loadCache(url)
+	for attempt := 1; ; attempt++ {
+		Tracef("GET %s", url)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return "", err
+		}
+		if c.auth != "" {
+			req.Header.Set("Authorization", string(c.auth))
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
+		if cached != nil {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		res, err := c.http.Do(req)
+		if err != nil {
+			// A cancelled or expired context is not transient
+			if ctx.Err() != nil || attempt > c.retries {
+				return "", err
+			}
+			if err := c.retryAfterBackoff(ctx, url, attempt, err.Error()); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if wait, limited := rateLimitWait(res); limited && attempt <= c.retries {
+			res.Body.Close()
+			if wait > maxRateLimitWait {
+				return "", fmt.Errorf("github rate limit exceeded; resets in %s", wait.Round(time.Second))
+			}
+			Tracef("GET %s rate limited; retrying in %s", url, wait.Round(time.Second))
+			if err := sleepCtx(ctx, wait); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if res.StatusCode >= 500 && attempt <= c.retries {
+			res.Body.Close()
+			if err := c.retryAfterBackoff(ctx, url, attempt, res.Status); err != nil {
+				return "", err
+			}
+			continue
+		}
+		return c.readResponse(res, url, cached, v)
+	}
+}
+
+// retryAfterBackoff sleeps before retry number attempt of a request that failed with reason:
+// retryBaseDelay doubled per earlier retry, capped at maxRetryDelay, with jitter so parallel
+// requests do not retry in lockstep
+func (c *ghClient) retryAfterBackoff(ctx context.Context, url string, attempt int, reason string) error {
+	d := min(retryBaseDelay<<(attempt-1), maxRetryDelay)
+	// Wait between half and all of the backoff
+	d = d/2 + rand.N(d/2+1)
+	Tracef("GET %s failed (%s); retry %d/%d in %s", url, reason, attempt, c.retries, d.Round(time.Millisecond))
+	return sleepCtx(ctx, d)
+}
+
+// sleepCtx waits for d, or returns the context's error as soon as it is cancelled
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// rateLimitWait reports whether res is a rate-limit rejection and how long to wait, from
+// Retry-After (secondary limits) or X-RateLimit-Reset once X-RateLimit-Remaining hits 0
+func rateLimitWait(res *http.Response) (time.Duration, bool) {
+	if res.StatusCode != http.StatusForbidden && res.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if res.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(res.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return time.Until(time.Unix(reset, 0)) + time.Second, true
+		}
+	}
+	if res.StatusCode == http.StatusTooManyRequests {
+		// GitHub asks clients to wait at least a minute when no header says otherwise
+		return time.Minute, true
+	}
+	return 0, false
+}
+
+func (c *ghClient) readResponse(res *http.Response, url string, cached *ghCacheEntry, v any) (string, error) {
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotModified && cached != nil {
+		return cached.Next, json.Unmarshal(cached.Body, v)
+	}
+	if res.StatusCode == 404 {
+		return "", errors.New("resource not found: " + url)
+	}
+	if res.StatusCode == 401 {
+		return "", errors.New("unauthorized: bad token or permissions")
+	}
+	if res.StatusCode >= 300 {
+		b, _ := io.ReadAll(res.Body)
+		return "", fmt.Errorf("github %d: %s", res.StatusCode, string(b))
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	next := nextPageLink(res.Header.Get("Link"))
+	if etag := res.Header.Get("ETag"); etag != "" {
+		c.storeCache(url, etag, next, body)
+	}
+	return next, json.Unmarshal(body, v)
+}
+
+// nextPageLink returns the rel="next" URL of a Link header such as
+// `<https://api.github.com/...&page=2>; rel="next", <https://api.github.com/...&page=5>; rel="last"`
+func nextPageLink(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		target, params, ok := strings.Cut(part, ";")
+		if !ok {
+			continue
+		}
+		for _, p := range strings.Split(params, ";") {
+			if strings.TrimSpace(p) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(target), "<>")
+			}
+		}
+	}
+	return ""
+}
+
+// ghCacheEntry is a cached GitHub API response body with the ETag it was served with and, for
+// list endpoints, the next page URL (a 304 carries no Link header)
+type ghCacheEntry struct {
+	URL  string          `json:"url"`
+	ETag string          `json:"etag"`
+	Next string          `json:"next,omitempty"`
+	Body json.RawMessage `json:"body"`
+}
+
+func (c *ghClient) cachePath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.cacheDir, hex.EncodeToString(sum[:16])+".json")
+}
+
+func (c *ghClient) loadCache(url string) *ghCacheEntry {
+	if c.cacheDir == "" {
+		return nil
+	}
+	b, err := os.ReadFile(c.cachePath(url))
+	if err != nil {
+		return nil
+	}
+	var e ghCacheEntry
+	if json.Unmarshal(b, &e) != nil || e.URL != url || e.ETag == "" {
+		return nil
+	}
+	return &e
+}
+
+// storeCache saves a response for revalidation; a cache that cannot be written only costs quota
+func (c *ghClient) storeCache(url, etag, next string, body []byte) {
+	if c.cacheDir == "" || !json.Valid(body) {
+		return
+	}
+	b, err := json.Marshal(ghCacheEntry{URL: url, ETag: etag, Next: next, Body: body})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.cacheDir, 0o755); err != nil {
+		return
+	}
+	// Responses can include secret names and PR metadata, so keep them private to the user
+	_ = os.WriteFile(c.cachePath(url), b, 0o600)
+}
+
+func urlQueryEscape(s string) string {
+	// minimal escape for spaces -> %20 and plus signs -> %2B
+	r := strings.NewReplacer(" ", "%20", "+", "%2B")
+	return r.Replace(s)
+}
@@ -0,0 +1,55 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// TestFetchWorkflowRunsRetries checks that a runs page is retried only by the client: once per
+// GitHubOptions.Retries after a 5xx, never after another 4xx
+func TestFetchWorkflowRunsRetries(t *testing.T) {
+	cases := map[string]struct {
+		statuses []int
+		retries  int
+		requests int32
+		wantErr  bool
+	}{
+		"not found":         {statuses: []int{http.StatusNotFound}, retries: 3, requests: 1, wantErr: true},
+		"unauthorized":      {statuses: []int{http.StatusUnauthorized}, retries: 3, requests: 1, wantErr: true},
+		"transient 5xx":     {statuses: []int{http.StatusServiceUnavailable, http.StatusOK}, retries: 1, requests: 2},
+		"persistent 5xx":    {statuses: []int{http.StatusBadGateway, http.StatusBadGateway}, retries: 1, requests: 2, wantErr: true},
+		"retries disabled":  {statuses: []int{http.StatusBadGateway}, retries: 0, requests: 1, wantErr: true},
+		"success first try": {statuses: []int{http.StatusOK}, retries: 3, requests: 1},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var n atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				i := int(n.Add(1)) - 1
+				status := c.statuses[min(i, len(c.statuses)-1)]
+				w.WriteHeader(status)
+				if status == http.StatusOK {
+					fmt.Fprint(w, `{"workflow_runs":[{"conclusion":"success"}]}`)
+				}
+			}))
+			defer srv.Close()
+			cli := newGHClient(GitHubOptions{Retries: c.retries})
+			cli.http = srv.Client()
+
+			runs, err := fetchWorkflowRuns(context.Background(), cli, srv.URL, 1, 5)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, c.wantErr)
+			}
+			if !c.wantErr && len(runs) != 1 {
+				t.Errorf("got %d runs, want 1", len(runs))
+			}
+			if got := n.Load(); got != c.requests {
+				t.Errorf("%d requests, want %d", got, c.requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package analyzer
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// gitignoreRule is one pattern line of a .gitignore file
+type gitignoreRule struct {
+	segments []string
+	negate   bool
+	dirOnly  bool
+	// anchored patterns (containing a slash) match from the root; others match any basename
+	anchored bool
+}
+
+// gitignore is the subset of git's ignore rules rrctl honors: the patterns of a single
+// .gitignore, with !negation, trailing-slash directory patterns, leading-slash anchoring and **
+type gitignore struct {
+	rules []gitignoreRule
+}
+
+// loadGitignore parses root/.gitignore; a missing file yields an empty matcher
+func loadGitignore(root string) *gitignore {
+	g := &gitignore{}
+	content, err := os.ReadFile(filepath.Join(root, ".gitignore"))
+	if err != nil {
+		return g
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var r gitignoreRule
+		if strings.HasPrefix(line, "!") {
+			r.negate, line = true, line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly, line = true, strings.TrimRight(line, "/")
+		}
+		r.anchored = strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+		r.segments = strings.Split(line, "/")
+		g.rules = append(g.rules, r)
+	}
+	return g
+}
+
+// Ignored reports whether rel (relative to the .gitignore's directory) is ignored; the last
+// matching rule wins. Parents are not checked: callers walk top-down and skip ignored directories.
+func (g *gitignore) Ignored(rel string, isDir bool) bool {
+	rel = filepath.ToSlash(rel)
+	parts := strings.Split(rel, "/")
+	ignored := false
+	for _, r := range g.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		var ok bool
+		if r.anchored {
+			ok = matchSegments(r.segments, parts)
+		} else {
+			ok, _ = path.Match(r.segments[0], parts[len(parts)-1])
+		}
+		if ok {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// matchSegments matches path segments against pattern segments, where ** spans any number of them
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
@@ -0,0 +1,223 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GitLabReport describes a repository's .gitlab-ci.yml (repo-defrag --provider gitlab)
+type GitLabReport struct {
+	File         string      `json:"file"`
+	Jobs         []GitLabJob `json:"jobs"`
+	LastModified *time.Time  `json:"lastModified,omitempty"`
+	Findings     []Finding   `json:"findings"`
+}
+
+// GitLabJob summarizes one pipeline job after extends: templates are applied
+type GitLabJob struct {
+	Name          string `json:"name"`
+	Stage         string `json:"stage,omitempty"`
+	Image         string `json:"image,omitempty"`
+	HasRules      bool   `json:"hasRules"`
+	Interruptible bool   `json:"interruptible"`
+	Scheduled     bool   `json:"scheduled"`
+}
+
+// gitlabKeywords are the top-level keys of .gitlab-ci.yml that are not jobs
+var gitlabKeywords = map[string]bool{
+	"default": true, "include": true, "stages": true, "types": true, "variables": true, "workflow": true,
+	"image": true, "services": true, "cache": true, "before_script": true, "after_script": true, "spec": true,
+}
+
+// ScanGitLabCI analyzes root/.gitlab-ci.yml for the GitLab counterparts of the workflow checks:
+// unguarded jobs, floating image tags, jobs that are not interruptible and stale scheduled jobs
+func ScanGitLabCI(root string, daysStale int, useGit bool, opts ScanOptions) (*GitLabReport, error) {
+	path := filepath.Join(root, ".gitlab-ci.yml")
+	Tracef("Scanning GitLab CI config %s", path)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read GitLab CI config: %w", err)
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	gr := &GitLabReport{File: path, Jobs: []GitLabJob{}}
+	if !useGit {
+		if info, err := os.Stat(path); err == nil {
+			ts := info.ModTime()
+			gr.LastModified = &ts
+		}
+	} else if ts, err := gitLastModified(path); err == nil {
+		gr.LastModified = &ts
+	}
+
+	defaults, _ := doc["default"].(map[string]any)
+	_, workflowRules := asMap(doc["workflow"])["rules"]
+	defaultImage := gitlabImage(defaults["image"])
+	if defaultImage == "" {
+		defaultImage = gitlabImage(doc["image"])
+	}
+	defaultInterruptible, _ := defaults["interruptible"].(bool)
+	for _, img := range append(gitlabServices(doc["services"]), gitlabServices(defaults["services"])...) {
+		if floatingImageTag(img) {
+			gr.Findings = append(gr.Findings, newFinding("gitlab.unpinned-image", path,
+				fmt.Sprintf("Service image %s uses a floating tag; pin a version or digest", img)))
+		}
+	}
+	if floatingImageTag(defaultImage) {
+		gr.Findings = append(gr.Findings, newFinding("gitlab.unpinned-image", path,
+			fmt.Sprintf("Default image %s uses a floating tag; pin a version or digest", defaultImage)))
+	}
+
+	var names []string
+	for name := range doc {
+		if !gitlabKeywords[name] && !strings.HasPrefix(name, ".") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		jm := resolveGitLabJob(doc, name, 0)
+		if jm == nil {
+			continue
+		}
+		job := GitLabJob{Name: name, Image: gitlabImage(jm["image"])}
+		job.Stage, _ = jm["stage"].(string)
+		_, hasRules := jm["rules"]
+		_, hasOnly := jm["only"]
+		_, hasExcept := jm["except"]
+		job.HasRules = hasRules || hasOnly || hasExcept
+		job.Interruptible = defaultInterruptible
+		if v, ok := jm["interruptible"].(bool); ok {
+			job.Interruptible = v
+		}
+		job.Scheduled = runsOnSchedule(jm)
+		gr.Jobs = append(gr.Jobs, job)
+
+		if !job.HasRules && !workflowRules {
+			f := newFinding("gitlab.no-rules", path, fmt.Sprintf("job:%s has no rules/only/except, so it runs in every pipeline; add rules to limit when it runs", name))
+			f.Job = name
+			gr.Findings = append(gr.Findings, f)
+		}
+		if job.Image != "" && floatingImageTag(job.Image) {
+			f := newFinding("gitlab.unpinned-image", path, fmt.Sprintf("job:%s image %s uses a floating tag; pin a version or digest", name, job.Image))
+			f.Job = name
+			gr.Findings = append(gr.Findings, f)
+		}
+		for _, img := range gitlabServices(jm["services"]) {
+			if floatingImageTag(img) {
+				f := newFinding("gitlab.unpinned-image", path, fmt.Sprintf("job:%s service image %s uses a floating tag; pin a version or digest", name, img))
+				f.Job = name
+				gr.Findings = append(gr.Findings, f)
+			}
+		}
+		// Deploy jobs should run to completion, so only non-environment jobs need interruptible
+		if !job.Interruptible && jm["environment"] == nil {
+			f := newFinding("gitlab.no-interruptible", path, fmt.Sprintf("job:%s is not interruptible: true, so superseded pipelines keep running it", name))
+			f.Job = name
+			gr.Findings = append(gr.Findings, f)
+		}
+		if job.Scheduled && gr.LastModified != nil && time.Since(*gr.LastModified) > time.Duration(daysStale)*24*time.Hour {
+			f := newFinding("gitlab.stale-schedule", path,
+				fmt.Sprintf("job:%s runs on pipeline schedules but .gitlab-ci.yml has not changed in over %d days; check the schedule is still needed", name, daysStale))
+			f.Job = name
+			gr.Findings = append(gr.Findings, f)
+		}
+	}
+	return gr, opts.finish(gr.Findings)
+}
+
+// resolveGitLabJob returns the job's mapping with its extends: templates merged underneath it
+// (shallowly, as GitLab does for top-level job keys), or nil when name is not a job
+func resolveGitLabJob(doc map[string]any, name string, depth int) map[string]any {
+	jm, ok := doc[name].(map[string]any)
+	if !ok || depth > 10 {
+		return nil
+	}
+	merged := map[string]any{}
+	for _, parent := range stringList(jm["extends"]) {
+		for k, v := range resolveGitLabJob(doc, parent, depth+1) {
+			merged[k] = v
+		}
+	}
+	for k, v := range jm {
+		merged[k] = v
+	}
+	delete(merged, "extends")
+	// Templates and jobs alike are mappings; only those that run something are jobs
+	if depth == 0 && merged["script"] == nil && merged["trigger"] == nil && merged["run"] == nil {
+		return nil
+	}
+	return merged
+}
+
+func asMap(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+// gitlabImage returns the image name of the string or {name: ...} form
+func gitlabImage(v any) string {
+	switch img := v.(type) {
+	case string:
+		return img
+	case map[string]any:
+		name, _ := img["name"].(string)
+		return name
+	}
+	return ""
+}
+
+func gitlabServices(v any) []string {
+	list, _ := v.([]any)
+	var out []string
+	for _, s := range list {
+		if img := gitlabImage(s); img != "" {
+			out = append(out, img)
+		}
+	}
+	return out
+}
+
+// floatingImageTag reports whether an image reference has no tag or the latest tag and no digest.
+// Images built from CI variables are skipped: their tag is unknown until the pipeline runs.
+func floatingImageTag(image string) bool {
+	if image == "" || strings.Contains(image, "$") || strings.Contains(image, "@sha256:") {
+		return false
+	}
+	last := image[strings.LastIndex(image, "/")+1:]
+	tag := ""
+	if i := strings.LastIndex(last, ":"); i >= 0 {
+		tag = last[i+1:]
+	}
+	return tag == "" || tag == "latest"
+}
+
+// runsOnSchedule reports whether a job is limited to (or explicitly includes) scheduled pipelines,
+// via only: [schedules] / only: {refs: [schedules]} or a rules: if on CI_PIPELINE_SOURCE "schedule"
+func runsOnSchedule(jm map[string]any) bool {
+	only := jm["only"]
+	if m, ok := only.(map[string]any); ok {
+		only = m["refs"]
+	}
+	for _, ref := range stringList(only) {
+		if ref == "schedules" {
+			return true
+		}
+	}
+	rules, _ := jm["rules"].([]any)
+	for _, r := range rules {
+		cond, _ := asMap(r)["if"].(string)
+		if strings.Contains(cond, "CI_PIPELINE_SOURCE") && strings.Contains(cond, "schedule") && !strings.Contains(cond, "!=") {
+			return true
+		}
+	}
+	return false
+}
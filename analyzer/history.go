@@ -0,0 +1,142 @@
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HistorySecret is a secret found on a line added by a commit, possibly removed again since
+type HistorySecret struct {
+	Finding
+	Commit string    `json:"commit"`
+	Author string    `json:"author"`
+	Date   time.Time `json:"date"`
+}
+
+// historyCommitMark starts the header line of each commit in the git log output
+const historyCommitMark = "\x1e"
+
+// ScanSecretHistory runs the pattern, keyword and entropy detectors of opts over the lines added
+// by the last depth commits reachable from HEAD in the git repository at path (0 = all commits).
+// git log -p is streamed one file diff at a time, so memory stays bounded by the largest diff.
+// A secret added by several commits is reported once, at the oldest commit that introduced it.
+// File paths are relative to path joined onto it, as ScanSecrets reports them.
+func ScanSecretHistory(path string, depth int, opts SecretScanOptions) ([]HistorySecret, error) {
+	args := []string{"-c", "core.quotepath=off", "log", "-p", "--no-color", "--no-ext-diff", "--no-renames", "--unified=0", "--relative",
+		"--format=" + historyCommitMark + "%H%x1f%an <%ae>%x1f%aI"}
+	if depth > 0 {
+		args = append(args, "-n", strconv.Itoa(depth))
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = path
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	traceCommand(cmd)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+
+	var out []HistorySecret
+	// Position in out of each (rule, file, message), so a secret is reported once
+	index := map[string]int{}
+	var sha, author string
+	var date time.Time
+	// The added lines of the current file diff and their line numbers in the new file
+	var file string
+	var lines []string
+	var lineNos []int
+	flush := func() {
+		if file == "" || len(lines) == 0 {
+			lines, lineNos = lines[:0], lineNos[:0]
+			return
+		}
+		fs, _ := scanSecretLines(filepath.Join(path, filepath.FromSlash(file)), lines, 0, opts)
+		for _, f := range capFileFindings(fs, opts.MaxMatchesPerFile) {
+			f.Line = lineNos[f.Line-1]
+			hs := HistorySecret{Finding: f, Commit: sha, Author: author, Date: date}
+			// Commits arrive newest first: an older introduction replaces the one seen before
+			key := f.RuleID + "|" + f.File + "|" + f.Message
+			if i, ok := index[key]; ok {
+				out[i] = hs
+				continue
+			}
+			index[key] = len(out)
+			out = append(out, hs)
+		}
+		lines, lineNos = lines[:0], lineNos[:0]
+	}
+
+	r := bufio.NewReaderSize(stdout, 64<<10)
+	next, commits := 0, 0
+	for {
+		line, err := r.ReadString('\n')
+		if line == "" && err != nil {
+			if err != io.EOF {
+				_ = cmd.Wait()
+				return nil, fmt.Errorf("read git log: %w", err)
+			}
+			break
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, historyCommitMark):
+			flush()
+			file = ""
+			commits++
+			Progress("commits", commits, 0)
+			parts := strings.SplitN(strings.TrimPrefix(line, historyCommitMark), "\x1f", 3)
+			sha, author, date = parts[0], "", time.Time{}
+			if len(parts) == 3 {
+				author = parts[1]
+				date, _ = time.Parse(time.RFC3339, parts[2])
+			}
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			file = ""
+		case strings.HasPrefix(line, "+++ "):
+			// "+++ /dev/null" is a deletion, which adds nothing
+			if name, ok := strings.CutPrefix(line, "+++ b/"); ok {
+				file = name
+			}
+		case strings.HasPrefix(line, "@@ "):
+			next = hunkNewStart(line)
+		case strings.HasPrefix(line, "+") && file != "":
+			lines = append(lines, line[1:])
+			lineNos = append(lineNos, next)
+			next++
+		}
+	}
+	flush()
+	if err := cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git log: %s", msg)
+		}
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+	return out, nil
+}
+
+// hunkNewStart parses the new-file start line of a unified diff hunk header "@@ -a,b +c,d @@"
+func hunkNewStart(header string) int {
+	_, rest, ok := strings.Cut(header, " +")
+	if !ok {
+		return 0
+	}
+	end := strings.IndexAny(rest, ", ")
+	if end < 0 {
+		end = len(rest)
+	}
+	n, _ := strconv.Atoi(rest[:end])
+	return n
+}
@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileName lists workflow path patterns to skip, one per line, in the repository root
+const IgnoreFileName = ".rrctlignore"
+
+// LoadIgnorePatterns reads the patterns of root's .rrctlignore; blank lines and # comments are
+// skipped and a missing file yields no patterns
+func LoadIgnorePatterns(root string) ([]string, error) {
+	f, err := os.Open(filepath.Join(root, IgnoreFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", IgnoreFileName, err)
+	}
+	defer f.Close()
+	var patterns []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if line := strings.TrimSpace(sc.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			patterns = append(patterns, line)
+		}
+	}
+	return patterns, sc.Err()
+}
+
+// ValidateIgnorePatterns reports the first malformed glob
+func ValidateIgnorePatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(strings.TrimSuffix(p, "/**"), ""); err != nil {
+			return fmt.Errorf("invalid ignore pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// MatchesIgnore reports whether a slash-separated, root-relative workflow path matches any
+// pattern. Patterns are path.Match globs against the whole path, or against the file name when
+// they contain no slash; a trailing /** matches everything beneath a directory.
+func MatchesIgnore(patterns []string, rel string) bool {
+	rel = strings.TrimPrefix(path.Clean(filepath.ToSlash(rel)), "./")
+	for _, p := range patterns {
+		p = strings.TrimPrefix(p, "./")
+		if dir, ok := strings.CutSuffix(p, "/**"); ok {
+			if m, _ := path.Match(dir, rel); m {
+				return true
+			}
+			for d := path.Dir(rel); d != "."; d = path.Dir(d) {
+				if m, _ := path.Match(dir, d); m {
+					return true
+				}
+			}
+			continue
+		}
+		target := rel
+		if !strings.Contains(p, "/") {
+			target = path.Base(rel)
+		}
+		if m, _ := path.Match(p, target); m {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// reCredentialKey matches keys that name a credential: GITHUB_TOKEN, db-password, client_secret,
+// apiKey. The word must end the key, so token-format or secret_name are not credentials.
+var reCredentialKey = regexp.MustCompile(`(?i)(token|passw(or)?d|secret|api[-_]?key|access[-_]?key|private[-_]?key)$`)
+
+// reShellReference matches values that read the credential from elsewhere at run time:
+// $VAR, ${VAR}, $(command) and %VAR%
+var reShellReference = regexp.MustCompile(`^(\$\{?\w+\}?|\$\(.*\)|%\w+%)$`)
+
+// detectInlineSecrets flags literal values assigned to credential-named keys anywhere in a
+// workflow (env:, with:, container credentials, ...), reporting the key and its line. Values that
+// use an expression such as ${{ secrets.TOKEN }}, or a shell variable, are not literals; the
+// permissions: block (id-token: write) is skipped.
+func detectInlineSecrets(file string, raw []byte) []Finding {
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	var out []Finding
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if !errors.Is(err, io.EOF) {
+				Tracef("inline secret check skipped for %s: %v", file, err)
+			}
+			return out
+		}
+		out = append(out, inlineSecretsIn(file, &doc, "", "")...)
+	}
+}
+
+// inlineSecretsIn walks n, tracking the job and step a credential is set in
+func inlineSecretsIn(file string, n *yaml.Node, job, step string) []Finding {
+	var out []Finding
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			out = append(out, inlineSecretsIn(file, c, job, step)...)
+		}
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			out = append(out, inlineSecretsIn(file, c, job, step)...)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			switch {
+			case k.Value == "permissions":
+				continue
+			case k.Value == "jobs" && job == "" && v.Kind == yaml.MappingNode:
+				for j := 0; j+1 < len(v.Content); j += 2 {
+					out = append(out, inlineSecretsIn(file, v.Content[j+1], v.Content[j].Value, "")...)
+				}
+				continue
+			case k.Value == "steps" && job != "" && step == "" && v.Kind == yaml.SequenceNode:
+				for j, s := range v.Content {
+					var sm map[string]any
+					_ = s.Decode(&sm)
+					out = append(out, inlineSecretsIn(file, s, job, stepLabel(sm, j))...)
+				}
+				continue
+			}
+			if v.Kind == yaml.ScalarNode && reCredentialKey.MatchString(k.Value) && isInlineSecret(v) {
+				msg := fmt.Sprintf("%s is set to a literal value %s in the workflow file; store it as a repository or environment secret and use ${{ secrets.%s }}",
+					k.Value, redactToken(v.Value), secretNameFor(k.Value))
+				switch {
+				case step != "":
+					msg = fmt.Sprintf("job:%s step:%s: %s", job, step, msg)
+				case job != "":
+					msg = fmt.Sprintf("job:%s: %s", job, msg)
+				}
+				f := nodeFinding("workflow.inline-secret", file, k, msg)
+				f.Job, f.Step = job, step
+				out = append(out, f)
+				continue
+			}
+			out = append(out, inlineSecretsIn(file, v, job, step)...)
+		}
+	}
+	return out
+}
+
+// isInlineSecret reports whether a scalar is a hardcoded string rather than an expression, a run-time
+// reference, or a boolean/number setting (token: true)
+func isInlineSecret(v *yaml.Node) bool {
+	s := strings.TrimSpace(v.Value)
+	if v.Tag != "!!str" || len(s) < 4 || strings.Contains(s, "${{") {
+		return false
+	}
+	return !reShellReference.MatchString(s)
+}
+
+// secretNameFor suggests a secret name for a key: "db-password" becomes DB_PASSWORD
+func secretNameFor(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
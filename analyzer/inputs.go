@@ -0,0 +1,89 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DeprecatedInput describes a setup-action input that was renamed or removed in a major version
+type DeprecatedInput struct {
+	Action      string `yaml:"action" json:"action"`
+	Input       string `yaml:"input" json:"input"`
+	Replacement string `yaml:"replacement,omitempty" json:"replacement,omitempty"`
+	// SinceMajor is the first major version where the input no longer works (0 = all versions)
+	SinceMajor int `yaml:"sinceMajor,omitempty" json:"sinceMajor,omitempty"`
+}
+
+// DefaultDeprecatedInputs is the built-in knowledge base; ScanOptions.DeprecatedInputs (from
+// --deprecated-inputs) replaces it
+var DefaultDeprecatedInputs = []DeprecatedInput{
+	{Action: "actions/setup-node", Input: "version", Replacement: "node-version", SinceMajor: 2},
+	{Action: "actions/setup-python", Input: "version", Replacement: "python-version", SinceMajor: 2},
+	{Action: "actions/setup-dotnet", Input: "version", Replacement: "dotnet-version", SinceMajor: 2},
+	{Action: "actions/setup-go", Input: "stable", Replacement: "go-version (stable is implied)", SinceMajor: 3},
+}
+
+// LoadDeprecatedInputs reads a knowledge base to use instead of the built-in one from a YAML list
+func LoadDeprecatedInputs(path string) ([]DeprecatedInput, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read deprecated inputs: %w", err)
+	}
+	var kb []DeprecatedInput
+	if err := yaml.Unmarshal(b, &kb); err != nil {
+		return nil, fmt.Errorf("parse deprecated inputs %s: %w", path, err)
+	}
+	for i, d := range kb {
+		if d.Action == "" || d.Input == "" {
+			return nil, fmt.Errorf("deprecated inputs %s: entry %d needs action and input", path, i+1)
+		}
+	}
+	// An empty file still replaces the built-in entries
+	if kb == nil {
+		kb = []DeprecatedInput{}
+	}
+	return kb, nil
+}
+
+// actionMajor parses the major version from refs like v4, v4.1.0 or 4; ok is false for SHAs/branches
+func actionMajor(ref string) (int, bool) {
+	ref = strings.TrimPrefix(ref, "v")
+	major, _, _ := strings.Cut(ref, ".")
+	n, err := strconv.Atoi(major)
+	return n, err == nil
+}
+
+// detectDeprecatedInputs flags setup-action steps still passing inputs that are ignored in the
+// referenced major version, per the kb knowledge base
+func detectDeprecatedInputs(w WorkflowReport, root map[string]any, kb []DeprecatedInput) []Finding {
+	var out []Finding
+	forEachStep(root, func(job string, _ map[string]any, step string, sm map[string]any) {
+		with, ok := sm["with"].(map[string]any)
+		if !ok {
+			return
+		}
+		u, _ := sm["uses"].(string)
+		_, ref, _ := strings.Cut(u, "@")
+		for _, d := range kb {
+			if !usesAction(sm, d.Action) {
+				continue
+			}
+			if _, set := with[d.Input]; !set {
+				continue
+			}
+			if major, ok := actionMajor(ref); d.SinceMajor > 0 && ok && major < d.SinceMajor {
+				continue
+			}
+			msg := fmt.Sprintf("job:%s step:%s passes deprecated input %q to %s@%s", job, step, d.Input, d.Action, valueOr(ref, "(none)"))
+			if d.Replacement != "" {
+				msg += "; use " + d.Replacement
+			}
+			out = append(out, newStepFinding("workflow.deprecated-input", w.File, job, step, msg))
+		}
+	})
+	return out
+}
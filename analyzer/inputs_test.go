@@ -0,0 +1,38 @@
+package analyzer
+
+import "testing"
+
+func TestDeprecatedInputs(t *testing.T) {
+	cases := map[string]struct {
+		workflow string
+		want     int
+	}{
+		"setup-java java-package is valid": {
+			workflow: "on: push\njobs:\n  a:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/setup-java@v4\n        with:\n          distribution: temurin\n          java-package: jdk\n          java-version: '21'\n",
+		},
+		"setup-node version on v2": {
+			workflow: "on: push\njobs:\n  a:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/setup-node@v2\n        with:\n          version: '20'\n",
+			want:     1,
+		},
+		"setup-node version on v1": {
+			workflow: "on: push\njobs:\n  a:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/setup-node@v1\n        with:\n          version: '20'\n",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			wr, err := analyzeWorkflow("ci.yml", []byte(c.workflow), ScanOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := 0
+			for _, f := range wr.Findings {
+				if f.RuleID == "workflow.deprecated-input" {
+					got++
+				}
+			}
+			if got != c.want {
+				t.Errorf("%d workflow.deprecated-input findings, want %d: %v", got, c.want, FindingMessages(wr.Findings))
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	reDeployJob = regexp.MustCompile(`(?i)deploy|release|publish|rollout`)
+	// reDeployWord matches workflow names and files such as "Deploy", deploy-prod.yml
+	reDeployWord = regexp.MustCompile(`(?i)deploy`)
+	reGateJob    = regexp.MustCompile(`(?i)build|test|lint|check|verify|\bci\b`)
+	// reDeployCmd matches run: commands that push changes to a live environment
+	reDeployCmd = regexp.MustCompile(`(?i)\b(kubectl\s+(apply|rollout|set\s+image)|helm\s+(upgrade|install)|terraform\s+apply|pulumi\s+up|serverless\s+deploy|sls\s+deploy|cdk\s+deploy|flyctl\s+deploy|vercel\s+(deploy|--prod)|firebase\s+deploy|gcloud\s+(app|run)\s+deploy|aws\s+(ecs\s+update-service|deploy)|az\s+webapp\s+deploy)\b`)
+)
+
+// extractJobNeeds returns job -> needs edges for every job in the workflow
+func extractJobNeeds(root map[string]any) map[string][]string {
+	jobs, ok := root["jobs"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	out := map[string][]string{}
+	for jname, jv := range jobs {
+		jm, _ := jv.(map[string]any)
+		needs := stringList(jm["needs"])
+		sort.Strings(needs)
+		out[jname] = needs
+	}
+	return out
+}
+
+// extractJobsWithoutTimeout returns the sorted IDs of jobs that set no timeout-minutes and so run
+// for up to the 6-hour default. Reusable workflow calls (uses:) are skipped: they cannot set one.
+func extractJobsWithoutTimeout(root map[string]any) []string {
+	jobs, _ := root["jobs"].(map[string]any)
+	var out []string
+	for id, jv := range jobs {
+		jm, _ := jv.(map[string]any)
+		if jm["uses"] != nil {
+			continue
+		}
+		if _, ok := jm["timeout-minutes"]; !ok {
+			out = append(out, id)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// jobsWithoutTimeoutFallback is the text-based extractJobsWithoutTimeout for files that do not
+// parse: job keys are the first indentation level under the column-0 jobs: key, and only keys at
+// the job body's own indentation count (so a step's timeout-minutes does not)
+func jobsWithoutTimeoutFallback(text string) []string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	start := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "jobs:") {
+			start = i + 1
+			break
+		}
+	}
+	if start == -1 {
+		return nil
+	}
+	type job struct {
+		id             string
+		bodyIndent     int
+		timeout, calls bool
+	}
+	var jobs []*job
+	jobIndent := -1
+	for _, line := range lines[start:] {
+		trim := strings.TrimSpace(line)
+		if trim == "" || strings.HasPrefix(trim, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent == 0 {
+			break
+		}
+		key, _, isKey := strings.Cut(trim, ":")
+		key = strings.Trim(key, `"'`)
+		if jobIndent == -1 {
+			jobIndent = indent
+		}
+		if indent <= jobIndent {
+			if isKey {
+				jobs = append(jobs, &job{id: key, bodyIndent: -1})
+			}
+			continue
+		}
+		if len(jobs) == 0 {
+			continue
+		}
+		j := jobs[len(jobs)-1]
+		if j.bodyIndent == -1 {
+			j.bodyIndent = indent
+		}
+		if indent == j.bodyIndent && isKey {
+			switch key {
+			case "timeout-minutes":
+				j.timeout = true
+			case "uses":
+				j.calls = true
+			}
+		}
+	}
+	var out []string
+	for _, j := range jobs {
+		if !j.timeout && !j.calls {
+			out = append(out, j.id)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// isDeployJob reports whether a job targets an environment or is named like a deployment
+func isDeployJob(jobs map[string]any, id string) bool {
+	jm, _ := jobs[id].(map[string]any)
+	return jm["environment"] != nil || reDeployJob.MatchString(id) || reDeployJob.MatchString(jobDisplayName(jobs, id))
+}
+
+// IsDeployWorkflow reports whether a workflow deploys: a job targets an environment or is named
+// like a deployment, or the workflow's name mentions deploy. Such runs should not be cancelled
+// part-way by a newer one.
+func IsDeployWorkflow(root map[string]any, filename string) bool {
+	if name, _ := root["name"].(string); reDeployWord.MatchString(name) || reDeployWord.MatchString(filename) {
+		return true
+	}
+	jobs, _ := root["jobs"].(map[string]any)
+	for id := range jobs {
+		if isDeployJob(jobs, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// runsDeployCommand reports whether any run: step of the job invokes a known deploy command
+func runsDeployCommand(jm map[string]any) bool {
+	steps, _ := jm["steps"].([]any)
+	for _, sv := range steps {
+		sm, _ := sv.(map[string]any)
+		if run, ok := sm["run"].(string); ok && reDeployCmd.MatchString(run) {
+			return true
+		}
+	}
+	return false
+}
+
+// jobEnvironment returns the environment name of a job (string or {name: ...} form)
+func jobEnvironment(jm map[string]any) string {
+	switch env := jm["environment"].(type) {
+	case string:
+		return env
+	case map[string]any:
+		n, _ := env["name"].(string)
+		return n
+	}
+	return ""
+}
+
+func jobDisplayName(jobs map[string]any, id string) string {
+	if jm, ok := jobs[id].(map[string]any); ok {
+		if n, ok := jm["name"].(string); ok && n != "" {
+			return n
+		}
+	}
+	return id
+}
+
+// detectDeployOrdering flags deploy-looking jobs that do not transitively need any build/test job
+// in the same workflow, which lets them run in parallel with (or before) the checks
+func detectDeployOrdering(w WorkflowReport, root map[string]any) []Finding {
+	jobs, _ := root["jobs"].(map[string]any)
+	if len(w.JobNeeds) < 2 {
+		return nil
+	}
+	isDeploy := func(id string) bool { return isDeployJob(jobs, id) }
+	isGate := func(id string) bool {
+		return !isDeploy(id) && (reGateJob.MatchString(id) || reGateJob.MatchString(jobDisplayName(jobs, id)))
+	}
+	var gates []string
+	for id := range w.JobNeeds {
+		if isGate(id) {
+			gates = append(gates, id)
+		}
+	}
+	if len(gates) == 0 {
+		return nil
+	}
+	sort.Strings(gates)
+
+	var ids []string
+	for id := range w.JobNeeds {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var out []Finding
+	for _, id := range ids {
+		if !isDeploy(id) {
+			continue
+		}
+		reach := reachableNeeds(w.JobNeeds, id)
+		gated := false
+		for _, g := range gates {
+			if reach[g] {
+				gated = true
+				break
+			}
+		}
+		if gated {
+			continue
+		}
+		f := newFinding("workflow.deploy-without-needs", w.File,
+			fmt.Sprintf("job:%s looks like a deployment but does not need any build/test job (%s); add `needs:` so it runs only after checks pass", id, strings.Join(gates, ", ")))
+		f.Job = id
+		out = append(out, f)
+	}
+	return out
+}
+
+// reachableNeeds returns every job reachable through needs edges from start (excluding start)
+func reachableNeeds(graph map[string][]string, start string) map[string]bool {
+	seen := map[string]bool{}
+	stack := append([]string(nil), graph[start]...)
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		stack = append(stack, graph[n]...)
+	}
+	return seen
+}
+
+// detectDeployWithoutConcurrency flags deploy jobs that run without workflow- or job-level
+// concurrency, so two runs can deploy to the same environment at once
+func detectDeployWithoutConcurrency(w WorkflowReport, root map[string]any) []Finding {
+	if root["concurrency"] != nil {
+		return nil
+	}
+	jobs, _ := root["jobs"].(map[string]any)
+	var ids []string
+	for id := range jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var out []Finding
+	for _, id := range ids {
+		jm, ok := jobs[id].(map[string]any)
+		if !ok || jm["concurrency"] != nil || !(isDeployJob(jobs, id) || runsDeployCommand(jm)) {
+			continue
+		}
+		race := "race each other"
+		if env := jobEnvironment(jm); env != "" {
+			race = "race to environment " + env
+		}
+		f := newFinding("workflow.deploy-no-concurrency", w.File,
+			fmt.Sprintf("job:%s deploys without concurrency control, so overlapping runs can %s; add e.g. `concurrency: {group: deploy-${{ github.ref }}, cancel-in-progress: false}`", id, race))
+		f.Job = id
+		out = append(out, f)
+	}
+	return out
+}
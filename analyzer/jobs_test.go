@@ -0,0 +1,33 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDeployWithoutConcurrencyPerJob checks that every unguarded deploy job gets its own finding
+// tagged with the job, like workflow.deploy-without-needs
+func TestDeployWithoutConcurrencyPerJob(t *testing.T) {
+	workflow := "on: push\njobs:\n" +
+		"  deploy-staging:\n    runs-on: ubuntu-latest\n    environment: staging\n    steps:\n      - run: ./deploy.sh\n" +
+		"  deploy-prod:\n    runs-on: ubuntu-latest\n    environment: production\n    steps:\n      - run: ./deploy.sh\n" +
+		"  deploy-docs:\n    runs-on: ubuntu-latest\n    environment: docs\n    concurrency: docs\n    steps:\n      - run: ./deploy.sh\n" +
+		"  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make test\n"
+	wr, err := analyzeWorkflow("ci.yml", []byte(workflow), ScanOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jobs []string
+	for _, f := range wr.Findings {
+		if f.RuleID != "workflow.deploy-no-concurrency" {
+			continue
+		}
+		jobs = append(jobs, f.Job)
+		if !strings.HasPrefix(f.Message, "job:"+f.Job+" ") {
+			t.Errorf("message does not start with the job: %s", f.Message)
+		}
+	}
+	if got := strings.Join(jobs, ","); got != "deploy-prod,deploy-staging" {
+		t.Errorf("findings for jobs %q, want deploy-prod,deploy-staging", got)
+	}
+}
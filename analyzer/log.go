@@ -0,0 +1,28 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Diagnostic hooks. rrctl routes them through its --quiet/--verbose logger; library callers may
+// point them at their own logger, passing the text through Redact. Both must be safe for
+// concurrent use: workflows scan in parallel.
+var (
+	// Warnf receives non-fatal problems, such as a workflows directory that cannot be read
+	Warnf = func(format string, args ...any) {
+		fmt.Fprint(os.Stderr, Redact(fmt.Sprintf("Warning: "+format+"\n", args...)))
+	}
+	// Tracef receives verbose progress: files scanned, git commands run and GitHub URLs fetched
+	Tracef = func(format string, args ...any) {}
+	// Progress receives scan progress: done of total items ("workflows", "files", "commits"); total
+	// is 0 when it is not known up front, as for the secret scan's directory walk
+	Progress = func(items string, done, total int) {}
+)
+
+// traceCommand reports a subprocess about to run, with its working directory
+func traceCommand(cmd *exec.Cmd) {
+	Tracef("Running %s (in %s)", strings.Join(cmd.Args, " "), valueOr(cmd.Dir, "."))
+}
@@ -0,0 +1,246 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultMatrixThreshold is the matrix size above which workflow.matrix-large flags a job when
+// ScanOptions.MatrixThreshold is unset
+const DefaultMatrixThreshold = 20
+
+// maxMatrixCombinations bounds how many combinations are expanded to apply include and exclude;
+// larger matrices are sized from their dimensions alone (GitHub caps a matrix at 256 jobs anyway)
+const maxMatrixCombinations = 4096
+
+// MatrixJob describes a job's strategy.matrix
+type MatrixJob struct {
+	Job string `json:"job"`
+	// Dimensions maps each matrix key other than include/exclude to its number of values
+	Dimensions map[string]int `json:"dimensions,omitempty"`
+	Include    int            `json:"include,omitempty"`
+	Exclude    int            `json:"exclude,omitempty"`
+	// Size is the number of jobs the matrix expands to after exclude and include (0 when Dynamic)
+	Size int `json:"size"`
+	// Dynamic is set when the matrix or a dimension is an expression such as fromJSON(...), so
+	// its size is only known at run time
+	Dynamic bool `json:"dynamic,omitempty"`
+	// FailFast and MaxParallel are the strategy settings as written ("" when not set)
+	FailFast    string `json:"failFast,omitempty"`
+	MaxParallel string `json:"maxParallel,omitempty"`
+}
+
+// extractMatrices sizes the strategy.matrix of every job, in job order, and reports exclude
+// entries that match no combination and include entries that repeat one
+func extractMatrices(file string, root map[string]any) ([]MatrixJob, []Finding) {
+	jobs, _ := root["jobs"].(map[string]any)
+	var ids []string
+	for id := range jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var out []MatrixJob
+	var findings []Finding
+	for _, id := range ids {
+		strategy := asMap(asMap(jobs[id])["strategy"])
+		mv, ok := strategy["matrix"]
+		if !ok {
+			continue
+		}
+		m := MatrixJob{Job: id}
+		if v, ok := strategy["fail-fast"]; ok {
+			m.FailFast = fmt.Sprint(v)
+		}
+		if v, ok := strategy["max-parallel"]; ok {
+			m.MaxParallel = fmt.Sprint(v)
+		}
+		matrix, ok := mv.(map[string]any)
+		if !ok {
+			m.Dynamic = true
+			out = append(out, m)
+			continue
+		}
+		var keys []string
+		values := map[string][]any{}
+		m.Dimensions = map[string]int{}
+		for k, v := range matrix {
+			if k == "include" || k == "exclude" {
+				continue
+			}
+			list, ok := v.([]any)
+			if !ok {
+				m.Dynamic = true
+				continue
+			}
+			keys = append(keys, k)
+			values[k] = list
+			m.Dimensions[k] = len(list)
+		}
+		sort.Strings(keys)
+		include, includeOK := matrix["include"].([]any)
+		exclude, excludeOK := matrix["exclude"].([]any)
+		if (matrix["include"] != nil && !includeOK) || (matrix["exclude"] != nil && !excludeOK) {
+			m.Dynamic = true
+		}
+		m.Include, m.Exclude = len(include), len(exclude)
+		if m.Dynamic {
+			out = append(out, m)
+			continue
+		}
+		var fs []Finding
+		m.Size, fs = matrixSize(file, id, keys, values, include, exclude)
+		findings = append(findings, fs...)
+		out = append(out, m)
+	}
+	return out, findings
+}
+
+// matrixSize expands the matrix the way GitHub does: every combination of the dimensions, minus
+// those matching an exclude entry, plus one job per include entry that cannot be merged into any
+// remaining combination without overwriting one of its dimension values
+func matrixSize(file, job string, keys []string, values map[string][]any, include, exclude []any) (int, []Finding) {
+	product := 1
+	if len(keys) == 0 {
+		product = 0
+	}
+	for _, k := range keys {
+		product *= len(values[k])
+	}
+	if product > maxMatrixCombinations {
+		return product + len(include), nil
+	}
+	combos := []map[string]any{}
+	if product > 0 {
+		combos = append(combos, map[string]any{})
+	}
+	for _, k := range keys {
+		var next []map[string]any
+		for _, c := range combos {
+			for _, v := range values[k] {
+				nc := map[string]any{k: v}
+				for ck, cv := range c {
+					nc[ck] = cv
+				}
+				next = append(next, nc)
+			}
+		}
+		combos = next
+	}
+
+	var findings []Finding
+	for i, e := range exclude {
+		em := asMap(e)
+		kept := combos[:0:0]
+		for _, c := range combos {
+			if !matrixEntryMatches(em, c, nil) {
+				kept = append(kept, c)
+			}
+		}
+		if len(kept) == len(combos) {
+			findings = append(findings, matrixRedundant(file, job, fmt.Sprintf("exclude entry %d (%s) matches no combination", i+1, matrixEntryString(em))))
+		}
+		combos = kept
+	}
+	size := len(combos)
+	for i, in := range include {
+		im := asMap(in)
+		merged := false
+		for _, c := range combos {
+			if matrixEntryMatches(im, c, values) {
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			size++
+			continue
+		}
+		// An entry made only of dimension values adds nothing to the combination it matches
+		dimsOnly := len(im) > 0
+		for k := range im {
+			if _, dim := values[k]; !dim {
+				dimsOnly = false
+			}
+		}
+		if dimsOnly {
+			findings = append(findings, matrixRedundant(file, job, fmt.Sprintf("include entry %d (%s) repeats an existing combination", i+1, matrixEntryString(im))))
+		}
+	}
+	return size, findings
+}
+
+// matrixEntryMatches reports whether an exclude (dims nil: every key) or include (only keys that
+// are dimensions) entry agrees with a combination
+func matrixEntryMatches(entry, combo map[string]any, dims map[string][]any) bool {
+	for k, v := range entry {
+		if dims != nil {
+			if _, dim := dims[k]; !dim {
+				continue
+			}
+		}
+		cv, ok := combo[k]
+		if !ok || fmt.Sprint(cv) != fmt.Sprint(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func matrixEntryString(entry map[string]any) string {
+	var parts []string
+	for k, v := range entry {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, v))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
+
+func matrixRedundant(file, job, what string) Finding {
+	f := newFinding("workflow.matrix-redundant", file, fmt.Sprintf("job:%s matrix %s; remove it", job, what))
+	f.Job = job
+	return f
+}
+
+// detectMatrixIssues flags matrices larger than threshold (recommending max-parallel when unset)
+// and matrix jobs that leave fail-fast at its implicit default
+func detectMatrixIssues(w WorkflowReport, threshold int) []Finding {
+	var out []Finding
+	for _, m := range w.Matrices {
+		size := fmt.Sprintf("%d jobs", m.Size)
+		if m.Dynamic {
+			size = "a run-time number of jobs"
+		}
+		if m.Size > threshold {
+			msg := fmt.Sprintf("job:%s matrix expands to %d jobs (%s), above the threshold of %d; consolidate dimensions or move rarely needed combinations to a scheduled workflow", m.Job, m.Size, matrixDimensionsString(m), threshold)
+			if m.MaxParallel == "" {
+				msg += ", and set strategy.max-parallel to protect runner capacity"
+			}
+			f := newFinding("workflow.matrix-large", w.File, msg)
+			f.Job = m.Job
+			out = append(out, f)
+		}
+		if m.FailFast == "" && (m.Dynamic || m.Size > 1) {
+			f := newFinding("workflow.matrix-no-fail-fast", w.File, fmt.Sprintf("job:%s matrix (%s) does not set strategy.fail-fast, which defaults to true: the first failing combination cancels the rest; set fail-fast: false to see every failure, or true to make the intent explicit", m.Job, size))
+			f.Job = m.Job
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// matrixDimensionsString renders dimensions as "go×3, os×2" plus include/exclude counts
+func matrixDimensionsString(m MatrixJob) string {
+	var parts []string
+	for k, n := range m.Dimensions {
+		parts = append(parts, fmt.Sprintf("%s×%d", k, n))
+	}
+	sort.Strings(parts)
+	if m.Include > 0 {
+		parts = append(parts, fmt.Sprintf("%d include", m.Include))
+	}
+	if m.Exclude > 0 {
+		parts = append(parts, fmt.Sprintf("%d exclude", m.Exclude))
+	}
+	return strings.Join(parts, ", ")
+}
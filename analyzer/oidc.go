@@ -0,0 +1,157 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// oidcConsumers are actions that request the job's OIDC token by design
+var oidcConsumers = []string{
+	"actions/attest", "actions/attest-build-provenance", "actions/attest-sbom", "actions/deploy-pages",
+	"sigstore/gh-action-sigstore-python", "pypa/gh-action-pypi-publish",
+}
+
+// longLivedCloudEnv maps env vars that carry static cloud credentials to the provider they belong to
+var longLivedCloudEnv = map[string]string{
+	"AWS_SECRET_ACCESS_KEY": "AWS",
+	"ARM_CLIENT_SECRET":     "Azure",
+	"AZURE_CLIENT_SECRET":   "Azure",
+	"GOOGLE_CREDENTIALS":    "GCP",
+}
+
+// oidcHints tells users which input switches each provider's login to OIDC
+var oidcHints = map[string]string{
+	"AWS":   "aws-actions/configure-aws-credentials with role-to-assume",
+	"Azure": "azure/login with client-id/tenant-id and a federated credential",
+	"GCP":   "google-github-actions/auth with workload_identity_provider",
+}
+
+func grantsIDTokenWrite(perms any) bool {
+	m, ok := perms.(map[string]any)
+	return ok && m["id-token"] == "write"
+}
+
+// stepCloudAuth classifies a step as using the OIDC token or as authenticating to a cloud provider
+// with long-lived credentials (returning the provider)
+func stepCloudAuth(sm map[string]any) (usesOIDC bool, longLived string) {
+	switch {
+	case usesAction(sm, "aws-actions/configure-aws-credentials"):
+		if stepWith(sm, "aws-secret-access-key") != "" {
+			return false, "AWS"
+		}
+		return stepWith(sm, "role-to-assume") != "", ""
+	case usesAction(sm, "google-github-actions/auth"):
+		if stepWith(sm, "credentials_json") != "" {
+			return false, "GCP"
+		}
+		return stepWith(sm, "workload_identity_provider") != "", ""
+	case usesAction(sm, "azure/login"):
+		if stepWith(sm, "creds") != "" {
+			return false, "Azure"
+		}
+		return stepWith(sm, "client-id") != "", ""
+	case usesAction(sm, "hashicorp/vault-action"):
+		return strings.EqualFold(stepWith(sm, "method"), "jwt"), ""
+	case usesAction(sm, "pypa/gh-action-pypi-publish"):
+		// Trusted publishing only applies when no API token is passed
+		return stepWith(sm, "password") == "", ""
+	}
+	for _, a := range oidcConsumers {
+		if usesAction(sm, a) {
+			return true, ""
+		}
+	}
+	run, _ := sm["run"].(string)
+	if strings.Contains(run, "ACTIONS_ID_TOKEN_REQUEST") || strings.Contains(run, "--provenance") ||
+		(strings.Contains(run, "cosign sign") && !strings.Contains(run, "--key")) {
+		return true, ""
+	}
+	return false, envCloudProvider(sm["env"])
+}
+
+// envCloudProvider returns the provider of the first secret-backed long-lived credential in env
+func envCloudProvider(env any) string {
+	m, _ := env.(map[string]any)
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v, _ := m[k].(string)
+		if p, ok := longLivedCloudEnv[strings.ToUpper(k)]; ok && reSecretExpr.MatchString(v) {
+			return p
+		}
+	}
+	return ""
+}
+
+func jobUsesOIDC(jm map[string]any) bool {
+	if jm["uses"] != nil {
+		// Reusable workflows may need the token; their steps are not visible here
+		return true
+	}
+	steps, _ := jm["steps"].([]any)
+	for _, sv := range steps {
+		sm, _ := sv.(map[string]any)
+		if ok, _ := stepCloudAuth(sm); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// detectOIDCMisuse flags id-token: write grants that no step uses, and cloud logins using
+// long-lived secrets where OIDC federation is available
+func detectOIDCMisuse(w WorkflowReport, root map[string]any) []Finding {
+	jobs, _ := root["jobs"].(map[string]any)
+	var ids []string
+	for id := range jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var out []Finding
+	var inheritUse, inheritUnused []string
+	for _, id := range ids {
+		jm, ok := jobs[id].(map[string]any)
+		if !ok {
+			continue
+		}
+		if _, own := jm["permissions"]; own {
+			if grantsIDTokenWrite(jm["permissions"]) && !jobUsesOIDC(jm) {
+				f := newFinding("workflow.id-token-unused", w.File,
+					fmt.Sprintf("job:%s grants id-token: write but no step requests an OIDC token; remove the permission", id))
+				f.Job = id
+				out = append(out, f)
+			}
+		} else if jobUsesOIDC(jm) {
+			inheritUse = append(inheritUse, id)
+		} else {
+			inheritUnused = append(inheritUnused, id)
+		}
+		if p := envCloudProvider(jm["env"]); p != "" {
+			f := newFinding("workflow.long-lived-cloud-creds", w.File,
+				fmt.Sprintf("job:%s exposes long-lived %s credentials from secrets via env; use OIDC (%s) instead", id, p, oidcHints[p]))
+			f.Job = id
+			out = append(out, f)
+		}
+	}
+	if grantsIDTokenWrite(root["permissions"]) && len(inheritUnused) > 0 {
+		msg := "Grants id-token: write at workflow level but no step requests an OIDC token; remove the permission"
+		if len(inheritUse) > 0 {
+			msg = fmt.Sprintf("Grants id-token: write at workflow level but only these jobs use OIDC: %s; move the permission to them so %s do not receive it",
+				strings.Join(inheritUse, ", "), strings.Join(inheritUnused, ", "))
+		}
+		out = append(out, newFinding("workflow.id-token-unused", w.File, msg))
+	}
+
+	forEachStep(root, func(job string, _ map[string]any, step string, sm map[string]any) {
+		if _, p := stepCloudAuth(sm); p != "" {
+			out = append(out, newStepFinding("workflow.long-lived-cloud-creds", w.File, job, step,
+				fmt.Sprintf("job:%s step:%s authenticates to %s with long-lived secrets; use OIDC (%s) instead", job, step, p, oidcHints[p])))
+		}
+	})
+	return out
+}
@@ -0,0 +1,157 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PinLevel grades how immutable an action reference is, from most to least secure
+type PinLevel string
+
+const (
+	// PinSHA is a full 40-hex-character commit SHA
+	PinSHA PinLevel = "sha"
+	// PinTag is a version tag such as v4 or v4.1.2 (acceptable, but tags can be moved)
+	PinTag PinLevel = "tag"
+	// PinBranch is main/master/HEAD/latest or any other ref that is not a version
+	PinBranch PinLevel = "branch"
+	// PinNone is a reference without @ref
+	PinNone PinLevel = "none"
+)
+
+// ActionPin is one step-level uses: reference to a public action and its pin level
+type ActionPin struct {
+	Job  string `json:"job,omitempty"`
+	Step string `json:"step,omitempty"`
+	// Line is the line of the uses: value (0 for files analyzed by the text fallback)
+	Line  int      `json:"line,omitempty"`
+	Uses  string   `json:"uses"`
+	Level PinLevel `json:"level"`
+}
+
+// pinRank orders pin levels from least to most immutable
+var pinRank = map[PinLevel]int{PinNone: 0, PinBranch: 1, PinTag: 2, PinSHA: 3}
+
+// AtLeast reports whether l is pinned at least as immutably as min (a sha satisfies tag)
+func (l PinLevel) AtLeast(min PinLevel) bool {
+	return pinRank[l] >= pinRank[min]
+}
+
+// PinCounts counts action references by pin level
+type PinCounts struct {
+	SHA    int `json:"sha"`
+	Tag    int `json:"tag"`
+	Branch int `json:"branch"`
+	None   int `json:"none"`
+}
+
+// Add counts the references of pins
+func (c *PinCounts) Add(pins []ActionPin) {
+	for _, p := range pins {
+		switch p.Level {
+		case PinSHA:
+			c.SHA++
+		case PinTag:
+			c.Tag++
+		case PinBranch:
+			c.Branch++
+		case PinNone:
+			c.None++
+		}
+	}
+}
+
+var (
+	reFullSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	// reVersionTag matches v4, 4.1, v4.1.2, v1.0.0-beta.1
+	reVersionTag = regexp.MustCompile(`^v?\d+(\.\d+)*([-+][\w.-]+)?$`)
+)
+
+// ClassifyPin returns the pin level of an owner/repo[/path]@ref reference
+func ClassifyPin(uses string) PinLevel {
+	_, ref, ok := strings.Cut(strings.TrimSpace(uses), "@")
+	switch {
+	case !ok || ref == "":
+		return PinNone
+	case reFullSHA.MatchString(ref):
+		return PinSHA
+	case reVersionTag.MatchString(ref):
+		return PinTag
+	}
+	return PinBranch
+}
+
+// unpinnedActions lists the references pinned below a version tag (a branch, or no @ref at all),
+// as "job:<id> uses:<ref>", for UsesUnpinnedAction and UnpinnedDetails
+func unpinnedActions(pins []ActionPin) (bool, []string) {
+	var details []string
+	for _, p := range pins {
+		if p.Level.AtLeast(PinTag) {
+			continue
+		}
+		if p.Job != "" {
+			details = append(details, fmt.Sprintf("job:%s uses:%s", p.Job, p.Uses))
+		} else {
+			details = append(details, "uses:"+p.Uses)
+		}
+	}
+	return len(details) > 0, details
+}
+
+// extractActionPins grades every public action referenced by a step, with the job, step and line
+// it is used at; local and docker:// actions are skipped
+func extractActionPins(root map[string]any, raw []byte) []ActionPin {
+	lines, _ := usesLines(raw)
+	var out []ActionPin
+	forEachStep(root, func(job string, _ map[string]any, step string, sm map[string]any) {
+		if u, ok := sm["uses"].(string); ok && !isLocalAction(u) {
+			p := ActionPin{Job: job, Step: step, Uses: u, Level: ClassifyPin(u)}
+			// Steps are visited in file order within a job, so repeated references pop in order
+			key := job + "\x00" + u
+			if l := lines[key]; len(l) > 0 {
+				p.Line, lines[key] = l[0], l[1:]
+			}
+			out = append(out, p)
+		}
+	})
+	return out
+}
+
+// usesLines maps job and uses: value to the lines of the step-level references, in step order, and
+// each job to the line of its job-level uses: (a reusable workflow call), for the first document
+// that has jobs
+func usesLines(raw []byte) (steps map[string][]int, jobs map[string]int) {
+	steps, jobs = map[string][]int{}, map[string]int{}
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil || len(doc.Content) == 0 {
+			return steps, jobs
+		}
+		jn := mappingValue(doc.Content[0], "jobs")
+		if jn == nil || jn.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(jn.Content); i += 2 {
+			job := jn.Content[i].Value
+			if u := mappingValue(jn.Content[i+1], "uses"); u != nil && u.Kind == yaml.ScalarNode {
+				jobs[job] = u.Line
+			}
+			sn := mappingValue(jn.Content[i+1], "steps")
+			if sn == nil || sn.Kind != yaml.SequenceNode {
+				continue
+			}
+			for _, s := range sn.Content {
+				if u := mappingValue(s, "uses"); u != nil && u.Kind == yaml.ScalarNode {
+					key := job + "\x00" + u.Value
+					steps[key] = append(steps[key], u.Line)
+				}
+			}
+		}
+		return steps, jobs
+	}
+}
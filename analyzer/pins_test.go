@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestClassifyPin(t *testing.T) {
+	cases := map[string]PinLevel{
+		"actions/checkout@0c45773b623bea8c8e75f6c82b208c3cf94ea4f9": PinSHA,
+		"actions/checkout@v4":                    PinTag,
+		"actions/checkout@v4.1.2":                PinTag,
+		"actions/checkout@4":                     PinTag,
+		"org/tool@v1.0.0-beta.1":                 PinTag,
+		"actions/checkout@main":                  PinBranch,
+		"some/act@release-1":                     PinBranch,
+		"some/act@0c45773":                       PinBranch,
+		"some/act":                               PinNone,
+		"some/act@":                              PinNone,
+		"org/repo/.github/workflows/ci.yml@main": PinBranch,
+		"org/repo/.github/workflows/ci.yml@v2":   PinTag,
+	}
+	for uses, want := range cases {
+		if got := ClassifyPin(uses); got != want {
+			t.Errorf("ClassifyPin(%q) = %s, want %s", uses, got, want)
+		}
+	}
+}
+
+// TestUnpinnedMatchesPinLevels checks that usesUnpinnedAction and unpinnedDetails agree with the
+// pin levels, for parsed files and the text fallback alike
+func TestUnpinnedMatchesPinLevels(t *testing.T) {
+	workflow := "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n      - uses: some/act@release-1\n      - uses: other/act\n"
+	for name, raw := range map[string]string{
+		"parsed":   workflow,
+		"fallback": workflow + "  broken: [\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := writeTempWorkflow(t, raw)
+			wr, err := analyzeWorkflow(path, []byte(raw), ScanOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if wr.TextFallback != (name == "fallback") {
+				t.Fatalf("TextFallback = %v", wr.TextFallback)
+			}
+			var counts PinCounts
+			counts.Add(wr.ActionPins)
+			if counts != (PinCounts{Tag: 1, Branch: 1, None: 1}) {
+				t.Errorf("pin counts %+v", counts)
+			}
+			if !wr.UsesUnpinnedAction || len(wr.UnpinnedDetails) != 2 {
+				t.Errorf("UsesUnpinnedAction=%v UnpinnedDetails=%v, want the branch and the missing ref", wr.UsesUnpinnedAction, wr.UnpinnedDetails)
+			}
+			if !slices.ContainsFunc(recommendForWorkflow(wr, 60), func(f Finding) bool { return f.RuleID == "workflow.unpinned-action" }) {
+				t.Error("no workflow.unpinned-action finding")
+			}
+		})
+	}
+}
+
+// writeTempWorkflow writes raw to .github/workflows/ci.yml under a temporary directory
+func writeTempWorkflow(t *testing.T, raw string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), ".github", "workflows")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "ci.yml")
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,59 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ghAuth is the Authorization header value of GitHub API requests. It prints as "token ***", so
+// passing it to Tracef, Warnf or an error message cannot leak the token; only the request header
+// gets the real value.
+type ghAuth string
+
+func (a ghAuth) String() string {
+	if a == "" {
+		return ""
+	}
+	return "token ***"
+}
+
+func (a ghAuth) GoString() string { return a.String() }
+
+// registeredSecrets are credentials Redact masks in log text (see RegisterSecret)
+var registeredSecrets struct {
+	sync.RWMutex
+	values []string
+}
+
+// reAuthorizationHeader matches an Authorization header and its scheme, up to the credential
+var reAuthorizationHeader = regexp.MustCompile(`(?i)(authorization:\s*(?:token|bearer|basic)\s+)\S+`)
+
+// RegisterSecret adds a credential, such as the --github-token value, for Redact to mask.
+// Values shorter than 4 characters are ignored: masking them would garble unrelated text.
+func RegisterSecret(s string) {
+	if len(s) < 4 {
+		return
+	}
+	registeredSecrets.Lock()
+	defer registeredSecrets.Unlock()
+	for _, v := range registeredSecrets.values {
+		if v == s {
+			return
+		}
+	}
+	registeredSecrets.values = append(registeredSecrets.values, s)
+}
+
+// Redact masks credentials in text bound for a log: registered secrets become ***, Authorization
+// header values "token ***" (or "Bearer ***"), and URL passwords user:***@host. rrctl passes every
+// warning and --verbose line through it; library callers logging analyzer output should too.
+func Redact(s string) string {
+	registeredSecrets.RLock()
+	for _, v := range registeredSecrets.values {
+		s = strings.ReplaceAll(s, v, "***")
+	}
+	registeredSecrets.RUnlock()
+	s = reAuthorizationHeader.ReplaceAllString(s, "${1}***")
+	return reURLCredentials.ReplaceAllString(s, "${1}${2}:***@${4}")
+}
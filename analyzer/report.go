@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+)
+
+// Report is the top-level report structure
+type Report struct {
+	GeneratedAt   time.Time `json:"generatedAt"`
+	RootPath      string    `json:"rootPath"`
+	WorkflowsPath string    `json:"workflowsPath"`
+	WorkflowsDirs []string  `json:"workflowsDirs,omitempty"`
+	StaleDays     int       `json:"staleDays"`
+	// Since is the --since date or git ref the workflows were filtered by
+	Since string `json:"since,omitempty"`
+	// IgnoredWorkflows counts workflow files skipped by --ignore or .rrctlignore
+	IgnoredWorkflows int                 `json:"ignoredWorkflows,omitempty"`
+	Workflows        []WorkflowReport    `json:"workflows"`
+	Actions          []ActionReport      `json:"actions,omitempty"`
+	GitLab           *GitLabReport       `json:"gitlab,omitempty"`
+	Azure            *AzureReport        `json:"azure,omitempty"`
+	GitHub           *GitHubReport       `json:"github,omitempty"`
+	Baseline         *BaselineComparison `json:"baseline,omitempty"`
+	// Delta is the comparison with the --baseline report
+	Delta   *RepoDefragDelta `json:"delta,omitempty"`
+	Summary Summary          `json:"summary"`
+}
+
+// Summary aggregates quick stats
+type Summary struct {
+	WorkflowCount               int `json:"workflowCount"`
+	WorkflowsStale              int `json:"workflowsStale"`
+	WorkflowsWithUnpinned       int `json:"workflowsWithUnpinned"`
+	WorkflowsWithoutConcurrency int `json:"workflowsWithoutConcurrency"`
+	WorkflowsWithoutPermissions int `json:"workflowsWithoutPermissions"`
+	JobsWithoutTimeout          int `json:"jobsWithoutTimeout"`
+	ActionsDeprecatedRuntime    int `json:"actionsDeprecatedRuntime"`
+	// ActionPins counts step-level action references by pin level (sha, tag, branch, none)
+	ActionPins PinCounts `json:"actionPins"`
+	// HygieneScore is the repository's 0-100 score; see ScoreReport
+	HygieneScore int `json:"hygieneScore"`
+}
+
+// SortReport orders each file's findings by key (see SortFindings). With "severity", workflows and
+// actions holding the most severe findings are also listed first; otherwise they stay in file order.
+func SortReport(r *Report, by string) {
+	for i := range r.Workflows {
+		SortFindings(r.Workflows[i].Findings, by)
+	}
+	for i := range r.Actions {
+		SortFindings(r.Actions[i].Findings, by)
+	}
+	if r.GitLab != nil {
+		SortFindings(r.GitLab.Findings, by)
+	}
+	if r.Azure != nil {
+		SortFindings(r.Azure.Findings, by)
+	}
+	if by != "severity" {
+		return
+	}
+	sort.SliceStable(r.Workflows, func(i, j int) bool {
+		return topSeverityRank(r.Workflows[i].Findings) > topSeverityRank(r.Workflows[j].Findings)
+	})
+	sort.SliceStable(r.Actions, func(i, j int) bool {
+		return topSeverityRank(r.Actions[i].Findings) > topSeverityRank(r.Actions[j].Findings)
+	})
+}
@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ReusableWorkflow is a job that calls another workflow (jobs.<id>.uses), either local
+// (./.github/workflows/foo.yml) or remote (owner/repo/.github/workflows/foo.yml@ref)
+type ReusableWorkflow struct {
+	Job   string `json:"job"`
+	Uses  string `json:"uses"`
+	Local bool   `json:"local"`
+	Ref   string `json:"ref,omitempty"`
+	// Mutable is set for remote calls without a ref or pinned to a branch (see ClassifyPin)
+	Mutable bool `json:"mutable,omitempty"`
+	// Exists reports whether a local callee is present in the repository (nil for remote calls)
+	Exists *bool `json:"exists,omitempty"`
+	// Line is the line of the job's uses: value (0 for files analyzed by the text fallback)
+	Line int `json:"line,omitempty"`
+}
+
+// extractReusableWorkflows lists the jobs of a workflow that call reusable workflows. Local
+// callees are resolved against the repository holding the .github directory of path.
+func extractReusableWorkflows(path string, root map[string]any, raw []byte) []ReusableWorkflow {
+	jobs, ok := root["jobs"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	_, lines := usesLines(raw)
+	var names []string
+	for name := range jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var out []ReusableWorkflow
+	for _, name := range names {
+		jm, _ := jobs[name].(map[string]any)
+		u, _ := jm["uses"].(string)
+		if u = strings.TrimSpace(u); u == "" {
+			continue
+		}
+		rw := ReusableWorkflow{Job: name, Uses: u, Local: strings.HasPrefix(u, "./"), Line: lines[name]}
+		if rw.Local {
+			_, err := os.Stat(filepath.Join(workflowRepoRoot(path), filepath.FromSlash(u)))
+			exists := err == nil
+			rw.Exists = &exists
+		} else {
+			_, rw.Ref, _ = strings.Cut(u, "@")
+			rw.Mutable = !ClassifyPin(u).AtLeast(PinTag)
+		}
+		out = append(out, rw)
+	}
+	return out
+}
+
+// workflowRepoRoot is the directory holding the .github directory a workflow file lives in, or
+// the workflow's own directory when it is not under one
+func workflowRepoRoot(path string) string {
+	for dir := filepath.Dir(path); ; {
+		if filepath.Base(dir) == ".github" {
+			return filepath.Dir(dir)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return filepath.Dir(path)
+		}
+		dir = parent
+	}
+}
+
+// detectReusableWorkflowIssues flags remote reusable workflows on mutable refs and local ones
+// whose file does not exist
+func detectReusableWorkflowIssues(w WorkflowReport) []Finding {
+	var out []Finding
+	for _, rw := range w.ReusableWorkflows {
+		var f Finding
+		switch {
+		case rw.Mutable:
+			f = newFinding("workflow.reusable-unpinned", w.File, fmt.Sprintf("job:%s calls reusable workflow %s on a mutable ref; pin it to a release tag or commit SHA", rw.Job, rw.Uses))
+		case rw.Exists != nil && !*rw.Exists:
+			f = newFinding("workflow.reusable-missing", w.File, fmt.Sprintf("job:%s calls reusable workflow %s, which does not exist in this repository", rw.Job, rw.Uses))
+		default:
+			continue
+		}
+		f.Job = rw.Job
+		out = append(out, f)
+	}
+	return out
+}
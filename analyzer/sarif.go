@@ -0,0 +1,219 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	// sarifSrcRoot is the uriBaseId artifact URIs are relative to (the scanned repository root)
+	sarifSrcRoot = "%SRCROOT%"
+)
+
+// SarifLog is the top-level SARIF 2.1.0 document (the subset rrctl emits)
+type SarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SarifRun `json:"runs"`
+}
+
+// SarifRun is one tool invocation: the rules it knows and the results it produced
+type SarifRun struct {
+	Tool    SarifTool     `json:"tool"`
+	Results []SarifResult `json:"results"`
+}
+
+type SarifTool struct {
+	Driver SarifDriver `json:"driver"`
+}
+
+type SarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []SarifRule `json:"rules"`
+}
+
+// SarifRule describes a check; results refer to it by ruleId and ruleIndex
+type SarifRule struct {
+	ID                   string              `json:"id"`
+	ShortDescription     SarifMessage        `json:"shortDescription"`
+	DefaultConfiguration SarifConfiguration  `json:"defaultConfiguration"`
+	Properties           SarifRuleProperties `json:"properties"`
+}
+
+type SarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+// SarifRuleProperties carries the GitHub code scanning security-severity score (0.0-10.0)
+type SarifRuleProperties struct {
+	SecuritySeverity string   `json:"security-severity,omitempty"`
+	Tags             []string `json:"tags,omitempty"`
+}
+
+type SarifMessage struct {
+	Text string `json:"text"`
+}
+
+// SarifResult is one finding at one location
+type SarifResult struct {
+	RuleID              string             `json:"ruleId"`
+	RuleIndex           int                `json:"ruleIndex"`
+	Level               string             `json:"level"`
+	Message             SarifMessage       `json:"message"`
+	Locations           []SarifLocation    `json:"locations"`
+	PartialFingerprints map[string]string  `json:"partialFingerprints,omitempty"`
+	Suppressions        []SarifSuppression `json:"suppressions,omitempty"`
+}
+
+type SarifLocation struct {
+	PhysicalLocation SarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type SarifPhysicalLocation struct {
+	ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+	Region           SarifRegion           `json:"region"`
+}
+
+type SarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// SarifRegion is 1-based, as SARIF requires
+type SarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// SarifSuppression marks a result accepted outside the code (e.g. a secrets baseline entry)
+type SarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// SarifFinding is a finding plus, when it was accepted (baseline), the justification
+type SarifFinding struct {
+	Finding
+	Suppressed    bool
+	Justification string
+}
+
+// sarifLevels maps severities to SARIF result levels
+var sarifLevels = map[Severity]string{
+	SeverityHigh:   "error",
+	SeverityMedium: "warning",
+	SeverityLow:    "note",
+}
+
+// sarifSecuritySeverity scores each severity in the middle of GitHub's high/medium/low bands
+var sarifSecuritySeverity = map[Severity]string{
+	SeverityHigh:   "8.0",
+	SeverityMedium: "5.5",
+	SeverityLow:    "2.0",
+}
+
+// NewSarifLog builds a single-run SARIF log for the findings, in the order given. Artifact URIs are
+// made relative to root; findings without a line are placed on line 1, since regions are 1-based.
+func NewSarifLog(toolVersion, root string, findings []SarifFinding) *SarifLog {
+	// A rule defaults to the severity its findings carry, so a --severity-override shows here too;
+	// findings of one rule that disagree fall back to the catalog severity
+	ids := map[string]Severity{}
+	for _, f := range findings {
+		if sev, seen := ids[f.RuleID]; !seen {
+			ids[f.RuleID] = f.Severity
+		} else if sev != f.Severity {
+			ids[f.RuleID] = ""
+		}
+	}
+	var sortedIDs []string
+	for id := range ids {
+		sortedIDs = append(sortedIDs, id)
+	}
+	sort.Strings(sortedIDs)
+
+	driver := SarifDriver{Name: "rrctl", Version: toolVersion, InformationURI: "https://github.com/kushin77/rrctl", Rules: []SarifRule{}}
+	index := map[string]int{}
+	for i, id := range sortedIDs {
+		desc, sev := valueOr(ruleCatalog[id].Description, id), ids[id]
+		if sev == "" {
+			sev = ruleCatalog[id].Severity
+		}
+		if sev == "" {
+			sev = SeverityMedium
+		}
+		index[id] = i
+		driver.Rules = append(driver.Rules, SarifRule{
+			ID:                   id,
+			ShortDescription:     SarifMessage{Text: desc},
+			DefaultConfiguration: SarifConfiguration{Level: sarifLevels[sev]},
+			// The rule ID prefix (workflow, secret, gitlab, ...) groups results in code scanning
+			Properties: SarifRuleProperties{SecuritySeverity: sarifSecuritySeverity[sev], Tags: []string{strings.SplitN(id, ".", 2)[0]}},
+		})
+	}
+
+	run := SarifRun{Tool: SarifTool{Driver: driver}, Results: []SarifResult{}}
+	for _, f := range findings {
+		line := f.Line
+		if line < 1 {
+			line = 1
+		}
+		res := SarifResult{
+			RuleID:    f.RuleID,
+			RuleIndex: index[f.RuleID],
+			Level:     sarifLevels[f.Severity],
+			Message:   SarifMessage{Text: f.Message},
+			Locations: []SarifLocation{{PhysicalLocation: SarifPhysicalLocation{
+				ArtifactLocation: SarifArtifactLocation{URI: sarifURI(root, f.File), URIBaseID: sarifSrcRoot},
+				Region:           SarifRegion{StartLine: line, StartColumn: f.Column},
+			}}},
+		}
+		if res.Level == "" {
+			res.Level = "warning"
+		}
+		if f.Fingerprint != "" {
+			res.PartialFingerprints = map[string]string{"rrctlFingerprint/v1": f.Fingerprint}
+		}
+		if f.Suppressed {
+			res.Suppressions = []SarifSuppression{{Kind: "external", Justification: f.Justification}}
+		}
+		run.Results = append(run.Results, res)
+	}
+	return &SarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []SarifRun{run}}
+}
+
+// sarifURI returns file relative to root with forward slashes, or the cleaned path when it is
+// outside root
+func sarifURI(root, file string) string {
+	if rel, err := filepath.Rel(root, file); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(filepath.Clean(file))
+}
+
+// Write saves the log as indented JSON
+func (l *SarifLog) Write(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := l.Encode(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Encode writes the log as indented JSON to w
+func (l *SarifLog) Encode(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(l)
+}
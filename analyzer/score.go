@@ -0,0 +1,52 @@
+package analyzer
+
+// scorePenalty is how many hygiene points one finding of each severity costs
+var scorePenalty = map[Severity]int{SeverityHigh: 15, SeverityMedium: 5, SeverityLow: 1}
+
+// HygieneScore rates a set of findings from 100 (none) down to 0, weighting high findings at 15
+// points, medium at 5 and low at 1
+func HygieneScore(fs []Finding) int {
+	score := 100
+	for _, f := range fs {
+		score -= scorePenalty[f.Severity]
+	}
+	return max(score, 0)
+}
+
+// ScoreReport sets the hygiene score of every workflow and the repository score in the summary:
+// the mean of the workflow and local action scores, or the score of the GitLab CI or Azure Pipelines
+// config. Analyze calls it
+// before severity filtering and baseline comparison, so the score covers every finding.
+func ScoreReport(r *Report) {
+	total, n := 0, 0
+	for i := range r.Workflows {
+		w := &r.Workflows[i]
+		w.HygieneScore = HygieneScore(w.Findings)
+		total, n = total+w.HygieneScore, n+1
+	}
+	for _, a := range r.Actions {
+		total, n = total+HygieneScore(a.Findings), n+1
+	}
+	if r.GitLab != nil {
+		total, n = total+HygieneScore(r.GitLab.Findings), n+1
+	}
+	if r.Azure != nil {
+		total, n = total+HygieneScore(r.Azure.Findings), n+1
+	}
+	r.Summary.HygieneScore = 100
+	if n > 0 {
+		// Rounded to the nearest point
+		r.Summary.HygieneScore = (total + n/2) / n
+	}
+}
+
+// TopSeverity is the most severe severity among fs, or "" when there are none
+func TopSeverity(fs []Finding) Severity {
+	var top Severity
+	for _, f := range fs {
+		if top == "" || !top.AtLeast(f.Severity) {
+			top = f.Severity
+		}
+	}
+	return top
+}
@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SecretRule is a named regular expression for a known token format
+type SecretRule struct {
+	Name    string `yaml:"name" json:"name"`
+	Pattern string `yaml:"pattern" json:"pattern"`
+	// Severity defaults to high: a format match is far more specific than the keyword or entropy checks
+	Severity Severity `yaml:"severity,omitempty" json:"severity,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// BuiltinSecretRules are provider token formats applied unless --no-builtin-rules is set
+var BuiltinSecretRules = []SecretRule{
+	mustSecretRule("aws-access-key-id", `\bAKIA[0-9A-Z]{16}\b`),
+	mustSecretRule("github-token", `\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`),
+	mustSecretRule("slack-token", `\bxox[baprs]-[A-Za-z0-9-]{10,}\b`),
+}
+
+func mustSecretRule(name, pattern string) SecretRule {
+	return SecretRule{Name: name, Pattern: pattern, Severity: SeverityHigh, re: regexp.MustCompile(pattern)}
+}
+
+// LoadSecretRules reads a YAML list of {name, pattern, severity} rules, compiling every pattern
+func LoadSecretRules(path string) ([]SecretRule, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read secret rules: %w", err)
+	}
+	var rules []SecretRule
+	if err := yaml.Unmarshal(b, &rules); err != nil {
+		return nil, fmt.Errorf("parse secret rules %s: %w", path, err)
+	}
+	seen := map[string]bool{}
+	for i := range rules {
+		r := &rules[i]
+		if r.Name == "" || r.Pattern == "" {
+			return nil, fmt.Errorf("secret rules %s: entry %d needs name and pattern", path, i+1)
+		}
+		if seen[r.Name] {
+			return nil, fmt.Errorf("secret rules %s: duplicate rule name %q", path, r.Name)
+		}
+		seen[r.Name] = true
+		if r.re, err = regexp.Compile(r.Pattern); err != nil {
+			return nil, fmt.Errorf("secret rules %s: rule %q: invalid pattern: %w", path, r.Name, err)
+		}
+		if r.Severity == "" {
+			r.Severity = SeverityHigh
+		} else if r.Severity, err = ParseSeverity(string(r.Severity)); err != nil {
+			return nil, fmt.Errorf("secret rules %s: rule %q: %w", path, r.Name, err)
+		}
+	}
+	return rules, nil
+}
+
+// scanPatterns reports every match of the rules in lines[from:], returning the matched text per
+// line so the entropy detector does not report the same token again
+func scanPatterns(file string, lines []string, from int, rules []SecretRule) (findings []Finding, suppressed int, matched map[int][]string) {
+	matched = map[int][]string{}
+	for i := from; i < len(lines); i++ {
+		line := stripInlineIgnore(lines[i])
+		for _, r := range rules {
+			for _, m := range r.re.FindAllString(line, -1) {
+				matched[i] = append(matched[i], m)
+				if suppressedInline(lines, i, "secret.pattern") {
+					suppressed++
+					continue
+				}
+				f := newFinding("secret.pattern", file, fmt.Sprintf("matches rule %q: %s", r.Name, redactToken(m)))
+				f.Severity, f.Line = r.Severity, i+1
+				findings = append(findings, f)
+			}
+		}
+	}
+	return findings, suppressed, matched
+}
@@ -0,0 +1,85 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// SecretBaseline lists known-acceptable secret-scan matches (security-scan --baseline)
+type SecretBaseline struct {
+	Entries []SecretBaselineEntry `json:"entries"`
+}
+
+// SecretBaselineEntry approves one match by file, line and rule; Reason records why it is acceptable
+type SecretBaselineEntry struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Rule   string `json:"rule"`
+	Reason string `json:"reason"`
+}
+
+// defaultBaselineReason marks entries accepted in bulk by --write-baseline, for review later
+const defaultBaselineReason = "accepted by --write-baseline"
+
+// LoadSecretBaseline reads a baseline written by security-scan --write-baseline (or by hand)
+func LoadSecretBaseline(path string) (*SecretBaseline, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read secrets baseline: %w", err)
+	}
+	var sb SecretBaseline
+	if err := json.Unmarshal(b, &sb); err != nil {
+		return nil, fmt.Errorf("parse secrets baseline %s: %w", path, err)
+	}
+	return &sb, nil
+}
+
+func secretBaselineKey(file string, line int, rule string) string {
+	return fmt.Sprintf("%s:%d:%s", filepath.ToSlash(filepath.Clean(file)), line, rule)
+}
+
+// Reason returns the approval reason of a finding present in the baseline
+func (b *SecretBaseline) Reason(f Finding) (string, bool) {
+	if b == nil {
+		return "", false
+	}
+	key := secretBaselineKey(f.File, f.Line, f.RuleID)
+	for _, e := range b.Entries {
+		if secretBaselineKey(e.File, e.Line, e.Rule) == key {
+			return e.Reason, true
+		}
+	}
+	return "", false
+}
+
+// NewSecretBaseline approves every finding, keeping the reasons of entries already in prev
+func NewSecretBaseline(findings []Finding, prev *SecretBaseline) *SecretBaseline {
+	sb := &SecretBaseline{Entries: []SecretBaselineEntry{}}
+	for _, f := range findings {
+		reason, ok := prev.Reason(f)
+		if !ok {
+			reason = defaultBaselineReason
+		}
+		sb.Entries = append(sb.Entries, SecretBaselineEntry{File: filepath.ToSlash(filepath.Clean(f.File)), Line: f.Line, Rule: f.RuleID, Reason: reason})
+	}
+	sort.SliceStable(sb.Entries, func(i, j int) bool {
+		a, c := sb.Entries[i], sb.Entries[j]
+		if a.File != c.File {
+			return a.File < c.File
+		}
+		return a.Line < c.Line
+	})
+	return sb
+}
+
+// Write saves the baseline as indented JSON
+func (b *SecretBaseline) Write(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
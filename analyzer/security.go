@@ -0,0 +1,355 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// detectWorkflowSecurity runs the security-oriented analyzers over a parsed workflow
+func detectWorkflowSecurity(w WorkflowReport, root map[string]any, raw string, trustedInstallers []string) []Finding {
+	var out []Finding
+	out = append(out, detectUnguardedIssueComment(w, raw)...)
+	out = append(out, detectSecretsInRunArgs(w, root)...)
+	out = append(out, detectEnvFileInjection(w, root)...)
+	out = append(out, detectWorkflowRunUntrusted(w, root, raw)...)
+	out = append(out, detectPipeToShell(w, root, trustedInstallers)...)
+	out = append(out, detectSecretArtifacts(w, root)...)
+	out = append(out, detectOIDCMisuse(w, root)...)
+	return out
+}
+
+// forEachStep visits every step of every job in sorted job order
+func forEachStep(root map[string]any, fn func(job string, jm map[string]any, step string, sm map[string]any)) {
+	jobs, ok := root["jobs"].(map[string]any)
+	if !ok {
+		return
+	}
+	var names []string
+	for k := range jobs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, jname := range names {
+		jm, ok := jobs[jname].(map[string]any)
+		if !ok {
+			continue
+		}
+		steps, _ := jm["steps"].([]any)
+		for i, sv := range steps {
+			sm, ok := sv.(map[string]any)
+			if !ok {
+				continue
+			}
+			fn(jname, jm, stepLabel(sm, i), sm)
+		}
+	}
+}
+
+// stepLabel names a step by its name, id, or uses, falling back to its 1-based position
+func stepLabel(sm map[string]any, idx int) string {
+	for _, k := range []string{"name", "id", "uses"} {
+		if v, ok := sm[k].(string); ok && strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return fmt.Sprintf("step %d", idx+1)
+}
+
+var reSecretExpr = regexp.MustCompile(`\$\{\{\s*secrets\.[A-Za-z0-9_]+\s*\}\}`)
+
+// detectSecretsInRunArgs flags secrets expanded directly into run: scripts, where they end up as
+// command-line arguments visible in process listings, instead of being passed through env:
+func detectSecretsInRunArgs(w WorkflowReport, root map[string]any) []Finding {
+	var out []Finding
+	forEachStep(root, func(job string, _ map[string]any, step string, sm map[string]any) {
+		run, ok := sm["run"].(string)
+		if !ok {
+			return
+		}
+		seen := map[string]bool{}
+		for _, expr := range reSecretExpr.FindAllString(run, -1) {
+			if seen[expr] {
+				continue
+			}
+			seen[expr] = true
+			out = append(out, newStepFinding("workflow.secret-in-run-args", w.File, job, step,
+				fmt.Sprintf("job:%s step:%s passes %s on the command line; map it to an env: variable and reference $VAR instead", job, step, expr)))
+		}
+	})
+	return out
+}
+
+// detectUnguardedIssueComment flags chatops workflows that any commenter can trigger
+func detectUnguardedIssueComment(w WorkflowReport, raw string) []Finding {
+	if !containsString(w.Triggers, "issue_comment") {
+		return nil
+	}
+	if strings.Contains(raw, "author_association") {
+		return nil
+	}
+	return []Finding{newFinding("workflow.issue-comment-unguarded", w.File,
+		fmt.Sprintf("Triggered by issue_comment without checking github.event.comment.author_association; anyone who can comment can run %s. Guard jobs with e.g. `if: contains(fromJSON('[\"OWNER\",\"MEMBER\",\"COLLABORATOR\"]'), github.event.comment.author_association)`", valueOr(w.Name, "this workflow")))}
+}
+
+// reUntrustedExpr matches expressions carrying attacker-controllable event data
+var reUntrustedExpr = regexp.MustCompile(`\$\{\{\s*(github\.event\.[A-Za-z0-9_.\-*\[\]]+|github\.head_ref)\s*\}\}`)
+
+// detectEnvFileInjection flags run: lines that write untrusted event data into $GITHUB_ENV or
+// $GITHUB_PATH, which lets an attacker inject variables (e.g. LD_PRELOAD) into later steps
+func detectEnvFileInjection(w WorkflowReport, root map[string]any) []Finding {
+	var out []Finding
+	forEachStep(root, func(job string, _ map[string]any, step string, sm map[string]any) {
+		run, ok := sm["run"].(string)
+		if !ok {
+			return
+		}
+		for _, line := range strings.Split(run, "\n") {
+			target := ""
+			switch {
+			case strings.Contains(line, "GITHUB_ENV"):
+				target = "$GITHUB_ENV"
+			case strings.Contains(line, "GITHUB_PATH"):
+				target = "$GITHUB_PATH"
+			default:
+				continue
+			}
+			for _, m := range reUntrustedExpr.FindAllStringSubmatch(line, -1) {
+				out = append(out, newStepFinding("workflow.env-file-injection", w.File, job, step,
+					fmt.Sprintf("job:%s step:%s writes untrusted %s into %s; sanitize the value or avoid persisting it for later steps", job, step, m[1], target)))
+			}
+		}
+	})
+	return out
+}
+
+// reInputExpr matches an expression referencing a workflow_dispatch or workflow_call input
+var reInputExpr = regexp.MustCompile(`\$\{\{[^}]*?\b((?:github\.event\.)?inputs\.[A-Za-z0-9_\-]+)`)
+
+// detectInputInjection flags run: scripts that interpolate workflow_dispatch/workflow_call inputs:
+// the value is pasted into the shell before it runs, so a crafted input executes as a command.
+// Inputs declared as boolean, number or choice cannot carry arbitrary text and are not reported.
+func detectInputInjection(w WorkflowReport, root map[string]any) []Finding {
+	typed := map[string]bool{}
+	if on, ok := root["on"].(map[string]any); ok {
+		for _, trigger := range []string{"workflow_dispatch", "workflow_call"} {
+			tm, _ := on[trigger].(map[string]any)
+			inputs, _ := tm["inputs"].(map[string]any)
+			for name, v := range inputs {
+				im, _ := v.(map[string]any)
+				switch im["type"] {
+				case "boolean", "number", "choice":
+					typed[name] = true
+				}
+			}
+		}
+	}
+	var out []Finding
+	forEachStep(root, func(job string, _ map[string]any, step string, sm map[string]any) {
+		run, ok := sm["run"].(string)
+		if !ok {
+			return
+		}
+		seen := map[string]bool{}
+		for _, m := range reInputExpr.FindAllStringSubmatch(run, -1) {
+			ref := m[1]
+			name := ref[strings.LastIndex(ref, ".")+1:]
+			if seen[ref] || typed[name] {
+				continue
+			}
+			seen[ref] = true
+			env := "INPUT_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+			out = append(out, newStepFinding("workflow.input-injection", w.File, job, step,
+				fmt.Sprintf("job:%s step:%s interpolates ${{ %s }} into run:, a script-injection point; pass it via env: (e.g. %s: ${{ %s }}) and use \"$%s\"", job, step, ref, env, ref, env)))
+		}
+	})
+	return out
+}
+
+// usesAction reports whether a step's uses: references the given owner/repo action
+func usesAction(sm map[string]any, action string) bool {
+	u, _ := sm["uses"].(string)
+	name, _, _ := strings.Cut(strings.TrimSpace(u), "@")
+	return strings.EqualFold(name, action)
+}
+
+// stepWith returns a step's with: input as a string
+func stepWith(sm map[string]any, key string) string {
+	with, _ := sm["with"].(map[string]any)
+	if v, ok := with[key]; ok && v != nil {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// detectWorkflowRunUntrusted flags workflow_run workflows that hold secrets while checking out or
+// downloading artifacts produced by the triggering (possibly fork) run
+func detectWorkflowRunUntrusted(w WorkflowReport, root map[string]any, raw string) []Finding {
+	if !containsString(w.Triggers, "workflow_run") || !reSecretExpr.MatchString(raw) {
+		return nil
+	}
+	var out []Finding
+	forEachStep(root, func(job string, _ map[string]any, step string, sm map[string]any) {
+		var risk string
+		switch {
+		case usesAction(sm, "actions/checkout") && strings.Contains(stepWith(sm, "ref"), "github.event.workflow_run"):
+			risk = "checks out code from the triggering run (" + stepWith(sm, "ref") + ")"
+		case usesAction(sm, "actions/download-artifact") && stepWith(sm, "run-id") != "":
+			risk = "downloads artifacts from the triggering run"
+		case usesAction(sm, "dawidd6/action-download-artifact"):
+			risk = "downloads artifacts from another workflow run"
+		default:
+			if run, ok := sm["run"].(string); ok && strings.Contains(run, "gh run download") {
+				risk = "downloads artifacts via `gh run download`"
+			}
+		}
+		if risk == "" {
+			return
+		}
+		out = append(out, newStepFinding("workflow.workflow-run-untrusted", w.File, job, step,
+			fmt.Sprintf("job:%s step:%s %s in a workflow_run workflow that has access to secrets; treat artifacts as untrusted data, never execute them, and keep secrets out of jobs that touch them (see https://securitylab.github.com/research/github-actions-preventing-pwn-requests/)", job, step, risk)))
+	})
+	return out
+}
+
+var (
+	// rePRHeadExpr matches expressions naming the pull request head (fork-controlled code)
+	rePRHeadExpr = regexp.MustCompile(`\$\{\{\s*(github\.event\.pull_request\.head\.(sha|ref)|github\.head_ref)\s*\}\}`)
+	// rePRTargetOn matches a pull_request_target trigger as a key, list item or scalar in raw YAML
+	rePRTargetOn = regexp.MustCompile(`(?m)^\s*(-\s*)?pull_request_target\s*(:|$)|^on:.*\bpull_request_target\b`)
+	// rePRHeadCheckout matches a checkout ref: (or git/gh command) using the head expression in raw YAML
+	rePRHeadCheckout = regexp.MustCompile(`(?m)^\s*(ref:|.*\b(git\s+(checkout|fetch)|gh\s+pr\s+checkout)\b).*\$\{\{\s*(github\.event\.pull_request\.head\.(sha|ref)|github\.head_ref)\s*\}\}`)
+)
+
+// prTargetHint explains the pull_request_target checkout risk and links the remediation guide
+const prTargetHint = "runs on pull_request_target (with repository secrets and, unless permissions restrict it, a write token) but checks out the pull request head, so fork code runs with those privileges; " +
+	"use pull_request for building untrusted code, or split into pull_request + workflow_run (see https://securitylab.github.com/research/github-actions-preventing-pwn-requests/)"
+
+// detectPRTargetCheckout flags pull_request_target workflows that check out the PR head via
+// actions/checkout ref: or a git/gh command in a run: step
+func detectPRTargetCheckout(w WorkflowReport, root map[string]any) []Finding {
+	if !containsString(w.Triggers, "pull_request_target") {
+		return nil
+	}
+	var out []Finding
+	forEachStep(root, func(job string, _ map[string]any, step string, sm map[string]any) {
+		checksOut := usesAction(sm, "actions/checkout") && rePRHeadExpr.MatchString(stepWith(sm, "ref"))
+		if run, ok := sm["run"].(string); ok && rePRHeadCheckout.MatchString(run) {
+			checksOut = true
+		}
+		if checksOut {
+			out = append(out, newStepFinding("workflow.pr-target-checkout", w.File, job, step, fmt.Sprintf("job:%s step:%s %s", job, step, prTargetHint)))
+		}
+	})
+	return out
+}
+
+// detectPRTargetCheckoutFallback is the text-based detectPRTargetCheckout for files that do not parse
+func detectPRTargetCheckoutFallback(w WorkflowReport, raw string) []Finding {
+	if !rePRTargetOn.MatchString(raw) || !rePRHeadCheckout.MatchString(raw) {
+		return nil
+	}
+	return []Finding{newFinding("workflow.pr-target-checkout", w.File, "Workflow "+prTargetHint)}
+}
+
+var (
+	// curl ... | sh, wget -O- | sudo bash
+	rePipeToShell = regexp.MustCompile(`\b(curl|wget)\b[^|;&]*\|\s*(sudo\s+)?(-E\s+)?(ba|z|k|da)?sh\b`)
+	// bash <(curl ...), sh -c "$(curl ...)"
+	reShellSubst = regexp.MustCompile(`\b(ba|z|k|da)?sh\s+(-c\s+)?["']?(<\(|\$\()\s*(curl|wget)\b`)
+	reURL        = regexp.MustCompile(`https?://[^\s'"|)]+`)
+)
+
+// isTrustedInstaller reports whether every URL on line starts with one of the trusted prefixes
+// (ScanOptions.TrustedInstallers)
+func isTrustedInstaller(line string, trusted []string) bool {
+	urls := reURL.FindAllString(line, -1)
+	if len(urls) == 0 {
+		return false
+	}
+	for _, u := range urls {
+		ok := false
+		for _, prefix := range trusted {
+			if strings.HasPrefix(u, prefix) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// detectPipeToShell flags run: lines that execute a downloaded script without verifying it
+func detectPipeToShell(w WorkflowReport, root map[string]any, trustedInstallers []string) []Finding {
+	var out []Finding
+	forEachStep(root, func(job string, _ map[string]any, step string, sm map[string]any) {
+		run, ok := sm["run"].(string)
+		if !ok {
+			return
+		}
+		for _, line := range strings.Split(run, "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "#") || (!rePipeToShell.MatchString(line) && !reShellSubst.MatchString(line)) {
+				continue
+			}
+			if isTrustedInstaller(line, trustedInstallers) {
+				continue
+			}
+			out = append(out, newStepFinding("workflow.pipe-to-shell", w.File, job, step,
+				fmt.Sprintf("job:%s step:%s pipes a remote script into a shell (`%s`); download it, verify a pinned checksum, then execute", job, step, line)))
+		}
+	})
+	return out
+}
+
+// broadArtifactPaths upload (nearly) the whole workspace
+var broadArtifactPaths = map[string]bool{
+	".": true, "./": true, "*": true, "**": true, "./**": true, "**/*": true, "~": true, "$HOME": true,
+	"${{ github.workspace }}": true, "${{ github.workspace }}/": true,
+}
+
+// detectSecretArtifacts flags actions/upload-artifact steps whose paths are likely to include
+// credential files, which anyone with read access to the repository can then download
+func detectSecretArtifacts(w WorkflowReport, root map[string]any) []Finding {
+	var out []Finding
+	forEachStep(root, func(job string, _ map[string]any, step string, sm map[string]any) {
+		if !usesAction(sm, "actions/upload-artifact") {
+			return
+		}
+		u, _ := sm["uses"].(string)
+		_, ref, _ := strings.Cut(u, "@")
+		// upload-artifact v4.4+ skips hidden files (.env, .npmrc, ...) unless include-hidden-files is set
+		hidden := stepWith(sm, "include-hidden-files") == "true" || strings.HasPrefix(ref, "v1") || strings.HasPrefix(ref, "v2") || strings.HasPrefix(ref, "v3")
+
+		var included, excluded []string
+		for _, p := range strings.Split(stepWith(sm, "path"), "\n") {
+			p = strings.TrimSpace(p)
+			if p == "" {
+				continue
+			}
+			if strings.HasPrefix(p, "!") {
+				excluded = append(excluded, p)
+			} else {
+				included = append(included, p)
+			}
+		}
+		var risky []string
+		for _, p := range included {
+			if IsSensitiveName(p) {
+				risky = append(risky, p)
+			} else if broadArtifactPaths[p] && hidden && len(excluded) == 0 {
+				risky = append(risky, p+" (whole workspace, including hidden files)")
+			}
+		}
+		if len(risky) == 0 {
+			return
+		}
+		out = append(out, newStepFinding("workflow.artifact-secrets", w.File, job, step,
+			fmt.Sprintf("job:%s step:%s uploads an artifact that may contain secrets: %s; narrow the path or add explicit excludes like !**/.env and !**/*.pem", job, step, strings.Join(risky, ", "))))
+	})
+	return out
+}
@@ -0,0 +1,44 @@
+package analyzer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultSensitiveFilePatterns are base-name globs for files that usually hold credentials
+var DefaultSensitiveFilePatterns = []string{
+	".env", ".env.*", "*.pem", "*.key", "*.p12", "*.pfx", "id_rsa", "id_dsa", "id_ecdsa", "id_ed25519",
+	".netrc", ".npmrc", ".pypirc", "credentials", "credentials.json", "kubeconfig", "*.kubeconfig",
+}
+
+// IsSensitiveName reports whether a file name (or a path/glob ending in one) looks like a credential file
+func IsSensitiveName(name string) bool {
+	return MatchesSensitiveName(DefaultSensitiveFilePatterns, name)
+}
+
+// MatchesSensitiveName is IsSensitiveName against a custom list of base-name globs
+func MatchesSensitiveName(patterns []string, name string) bool {
+	base := filepath.Base(strings.TrimRight(filepath.ToSlash(name), "/"))
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateSensitivePatterns reports the first malformed glob in patterns
+func ValidateSensitivePatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid sensitive file pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// SensitiveReadableFinding reports a credential-like file that group or other users can read
+func SensitiveReadableFinding(file, mode string) Finding {
+	return newFinding("file.sensitive-readable", file, fmt.Sprintf("Sensitive file is group/world-readable (mode %s); restrict it with chmod go-r (or 0600)", mode))
+}
@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FilterChangedSince keeps the workflows changed after since, which is either a date (RFC3339 or
+// YYYY-MM-DD, compared with LastModified) or a git ref (workflows listed by `git diff --name-only
+// <ref>` in root, i.e. changed in later commits or in the working tree). Workflows with an unknown
+// last-modified time are dropped by a date filter.
+func FilterChangedSince(reports []WorkflowReport, root, since string) ([]WorkflowReport, error) {
+	if t, ok := parseSinceDate(since); ok {
+		var out []WorkflowReport
+		for _, w := range reports {
+			if w.LastModified != nil && w.LastModified.After(t) {
+				out = append(out, w)
+			}
+		}
+		return out, nil
+	}
+	changed, err := gitChangedSince(root, since)
+	if err != nil {
+		return nil, fmt.Errorf("--since %q is neither an RFC3339 date nor a git ref: %w", since, err)
+	}
+	var out []WorkflowReport
+	for _, w := range reports {
+		if rel, err := filepath.Rel(root, w.File); err == nil && changed[filepath.ToSlash(rel)] {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+
+func parseSinceDate(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// gitChangedSince returns the root-relative paths changed between ref and the working tree
+func gitChangedSince(root, ref string) (map[string]bool, error) {
+	// "--" ends the revisions, so a ref that is also a file name is not read as a path
+	cmd := exec.Command("git", "-c", "core.quotepath=off", "diff", "--name-only", "--relative", ref, "--")
+	cmd.Dir = root
+	traceCommand(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return nil, fmt.Errorf("%s", strings.TrimSpace(string(ee.Stderr)))
+		}
+		return nil, err
+	}
+	changed := map[string]bool{}
+	for _, name := range strings.Split(string(out), "\n") {
+		if name = strings.TrimSpace(name); name != "" {
+			changed[name] = true
+		}
+	}
+	return changed, nil
+}
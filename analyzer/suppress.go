@@ -0,0 +1,45 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// reInlineIgnore matches an rrctl:ignore directive inside a #, //, /*, <!-- or -- comment.
+// The optional list after the directive names the rule IDs to suppress; an empty list suppresses all.
+var reInlineIgnore = regexp.MustCompile(`(?:#|//|/\*|<!--|--)\s*rrctl:ignore\b([A-Za-z0-9_.\-, \t]*)`)
+
+// inlineIgnore parses the directive on a line, returning the suppressed rule IDs (nil = all rules)
+func inlineIgnore(line string) (rules []string, ok bool) {
+	m := reInlineIgnore.FindStringSubmatch(line)
+	if m == nil {
+		return nil, false
+	}
+	for _, tok := range strings.FieldsFunc(m[1], func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		rules = append(rules, tok)
+	}
+	return rules, true
+}
+
+// suppressedInline reports whether rule is suppressed for lines[i] by a directive on that line or
+// by a comment-only directive line directly above it
+func suppressedInline(lines []string, i int, rule string) bool {
+	for _, j := range []int{i, i - 1} {
+		if j < 0 {
+			continue
+		}
+		rules, ok := inlineIgnore(lines[j])
+		if !ok || (j < i && strings.TrimSpace(stripInlineIgnore(lines[j])) != "") {
+			continue
+		}
+		if len(rules) == 0 || containsString(rules, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripInlineIgnore removes the directive so its own rule IDs are not scanned as content
+func stripInlineIgnore(line string) string {
+	return reInlineIgnore.ReplaceAllString(line, "")
+}
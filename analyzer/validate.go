@@ -0,0 +1,133 @@
+package analyzer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var reYAMLErrLine = regexp.MustCompile(`line (\d+)(?:, column (\d+))?:?\s*`)
+
+// ValidateWorkflowYAML strictly decodes every document and checks the basic workflow shape
+func ValidateWorkflowYAML(file string, content []byte) []Finding {
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	var out []Finding
+	docs := 0
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			msg := strings.TrimPrefix(err.Error(), "yaml: ")
+			f := newFinding("workflow.invalid-yaml", file, msg)
+			if m := reYAMLErrLine.FindStringSubmatch(msg); m != nil {
+				f.Line, _ = strconv.Atoi(m[1])
+				f.Column, _ = strconv.Atoi(m[2])
+				if strings.HasPrefix(msg, m[0]) {
+					f.Message = strings.TrimPrefix(msg, m[0])
+				}
+			}
+			return append(out, f)
+		}
+		docs++
+		out = append(out, duplicateKeys(file, &doc)...)
+		out = append(out, validateWorkflowNode(file, &doc)...)
+	}
+	if docs == 0 {
+		out = append(out, newFinding("workflow.invalid-structure", file, "file contains no YAML document"))
+	}
+	return out
+}
+
+func nodeFinding(rule, file string, n *yaml.Node, msg string) Finding {
+	f := newFinding(rule, file, msg)
+	f.Line, f.Column = n.Line, n.Column
+	return f
+}
+
+// mappingValue returns the value node for key in a mapping node
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// duplicateKeys reports repeated keys in any mapping; GitHub rejects these but yaml.Node decoding accepts them
+func duplicateKeys(file string, n *yaml.Node) []Finding {
+	var out []Finding
+	if n.Kind == yaml.MappingNode {
+		seen := map[string]int{}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k := n.Content[i]
+			if first, dup := seen[k.Value]; dup {
+				out = append(out, nodeFinding("workflow.invalid-yaml", file, k, fmt.Sprintf("key %q already defined at line %d", k.Value, first)))
+				continue
+			}
+			seen[k.Value] = k.Line
+		}
+	}
+	for _, c := range n.Content {
+		out = append(out, duplicateKeys(file, c)...)
+	}
+	return out
+}
+
+func validateWorkflowNode(file string, doc *yaml.Node) []Finding {
+	root := doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return []Finding{nodeFinding("workflow.invalid-structure", file, root, "workflow must be a mapping")}
+	}
+	var out []Finding
+	if mappingValue(root, "on") == nil {
+		out = append(out, nodeFinding("workflow.invalid-structure", file, root, "missing top-level 'on' (triggers)"))
+	}
+	jobs := mappingValue(root, "jobs")
+	if jobs == nil {
+		return append(out, nodeFinding("workflow.invalid-structure", file, root, "missing top-level 'jobs'"))
+	}
+	if jobs.Kind != yaml.MappingNode {
+		return append(out, nodeFinding("workflow.invalid-structure", file, jobs, "'jobs' must be a mapping"))
+	}
+	for i := 0; i+1 < len(jobs.Content); i += 2 {
+		name, job := jobs.Content[i].Value, jobs.Content[i+1]
+		if job.Kind != yaml.MappingNode {
+			out = append(out, nodeFinding("workflow.invalid-structure", file, job, fmt.Sprintf("job %q must be a mapping", name)))
+			continue
+		}
+		if mappingValue(job, "runs-on") == nil && mappingValue(job, "uses") == nil {
+			out = append(out, nodeFinding("workflow.invalid-structure", file, jobs.Content[i], fmt.Sprintf("job %q has neither runs-on nor uses", name)))
+		}
+		steps := mappingValue(job, "steps")
+		if steps == nil {
+			continue
+		}
+		if steps.Kind != yaml.SequenceNode {
+			out = append(out, nodeFinding("workflow.invalid-structure", file, steps, fmt.Sprintf("job %q steps must be a list", name)))
+			continue
+		}
+		for si, step := range steps.Content {
+			hasRun, hasUses := mappingValue(step, "run") != nil, mappingValue(step, "uses") != nil
+			if hasRun == hasUses {
+				out = append(out, nodeFinding("workflow.invalid-structure", file, step, fmt.Sprintf("job %q step %d must have exactly one of run or uses", name, si+1)))
+			}
+		}
+	}
+	return out
+}
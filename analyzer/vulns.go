@@ -0,0 +1,124 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// ErrGovulncheckMissing is returned by ScanGoVulns when govulncheck is not on PATH
+var ErrGovulncheckMissing = errors.New("govulncheck not found on PATH (install with `go install golang.org/x/vuln/cmd/govulncheck@latest`)")
+
+// Vulnerability is a known vulnerability affecting a module dependency, as reported by govulncheck
+type Vulnerability struct {
+	ID           string   `json:"id"`
+	Aliases      []string `json:"aliases,omitempty"`
+	Module       string   `json:"module"`
+	Version      string   `json:"version"`
+	FixedVersion string   `json:"fixedVersion,omitempty"`
+	Summary      string   `json:"summary,omitempty"`
+	// Called is true when the vulnerable code is reachable from the module's own code
+	Called bool `json:"called"`
+}
+
+// govulncheckMessage is one object of the `govulncheck -json` stream (only the fields rrctl uses)
+type govulncheckMessage struct {
+	OSV *struct {
+		ID      string   `json:"id"`
+		Aliases []string `json:"aliases"`
+		Summary string   `json:"summary"`
+	} `json:"osv"`
+	Finding *struct {
+		OSV          string `json:"osv"`
+		FixedVersion string `json:"fixed_version"`
+		Trace        []struct {
+			Module   string `json:"module"`
+			Version  string `json:"version"`
+			Function string `json:"function"`
+		} `json:"trace"`
+	} `json:"finding"`
+}
+
+// ScanGoVulns runs `govulncheck -json ./...` in the Go module at dir and returns one entry per
+// vulnerability and module, sorted by ID
+func ScanGoVulns(dir string) ([]Vulnerability, error) {
+	bin, err := exec.LookPath("govulncheck")
+	if err != nil {
+		return nil, ErrGovulncheckMissing
+	}
+	cmd := exec.Command(bin, "-json", "./...")
+	cmd.Dir = dir
+	traceCommand(cmd)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("govulncheck: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseGovulncheck(bytes.NewReader(out))
+}
+
+func parseGovulncheck(r io.Reader) ([]Vulnerability, error) {
+	type osvInfo struct {
+		aliases []string
+		summary string
+	}
+	osvs := map[string]osvInfo{}
+	byKey := map[string]*Vulnerability{}
+	dec := json.NewDecoder(r)
+	for {
+		var m govulncheckMessage
+		if err := dec.Decode(&m); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("parse govulncheck output: %w", err)
+		}
+		if m.OSV != nil {
+			osvs[m.OSV.ID] = osvInfo{aliases: m.OSV.Aliases, summary: m.OSV.Summary}
+		}
+		if m.Finding == nil || len(m.Finding.Trace) == 0 {
+			continue
+		}
+		// Traces start at the vulnerable symbol; it only has a function when the scan found it reachable
+		frame := m.Finding.Trace[0]
+		key := m.Finding.OSV + "|" + frame.Module
+		v := byKey[key]
+		if v == nil {
+			v = &Vulnerability{ID: m.Finding.OSV, Module: frame.Module, Version: frame.Version, FixedVersion: m.Finding.FixedVersion}
+			byKey[key] = v
+		}
+		if frame.Function != "" {
+			v.Called = true
+		}
+	}
+
+	var out []Vulnerability
+	for _, v := range byKey {
+		info := osvs[v.ID]
+		v.Aliases, v.Summary = info.aliases, info.summary
+		out = append(out, *v)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ID != out[j].ID {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].Module < out[j].Module
+	})
+	return out, nil
+}
+
+// CVEs returns the CVE aliases of a vulnerability
+func (v Vulnerability) CVEs() []string {
+	var cves []string
+	for _, a := range v.Aliases {
+		if strings.HasPrefix(a, "CVE-") {
+			cves = append(cves, a)
+		}
+	}
+	return cves
+}
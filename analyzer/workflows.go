@@ -0,0 +1,970 @@
+package analyzer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type WorkflowReport struct {
+	File            string                   `json:"file"`
+	SourceDir       string                   `json:"sourceDir,omitempty"`
+	Name            string                   `json:"name"`
+	Triggers        []string                 `json:"triggers"`
+	TriggerFilters  map[string]TriggerFilter `json:"triggerFilters,omitempty"`
+	Schedules       []string                 `json:"schedules"`
+	Runners         []string                 `json:"runners"`
+	JobNeeds        map[string][]string      `json:"jobNeeds,omitempty"`
+	EnvironmentJobs []EnvironmentJob         `json:"environmentJobs,omitempty"`
+	HasConcurrency  bool                     `json:"hasConcurrency"`
+	// HasWorkflowConcurrency is set for a top-level concurrency block; JobsWithConcurrency are the
+	// jobs with their own (not known for files that do not parse)
+	HasWorkflowConcurrency bool               `json:"hasWorkflowConcurrency"`
+	JobsWithConcurrency    []string           `json:"jobsWithConcurrency,omitempty"`
+	HasPermissions         bool               `json:"hasPermissions"`
+	PermissionsScope       string             `json:"permissionsScope,omitempty"`
+	JobsWithoutTimeout     []string           `json:"jobsWithoutTimeout,omitempty"`
+	Matrices               []MatrixJob        `json:"matrices,omitempty"`
+	UsesUnpinnedAction     bool               `json:"usesUnpinnedAction"`
+	UnpinnedDetails        []string           `json:"unpinnedDetails"`
+	ActionPins             []ActionPin        `json:"actionPins,omitempty"`
+	ReusableWorkflows      []ReusableWorkflow `json:"reusableWorkflows,omitempty"`
+	DeprecatedHints        []string           `json:"deprecatedHints"`
+	SecurityHints          []string           `json:"securityHints,omitempty"`
+	CachingHints           []string           `json:"cachingHints,omitempty"`
+	LastModified           *time.Time         `json:"lastModified,omitempty"`
+	Recommendations        []string           `json:"recommendations"`
+	Findings               []Finding          `json:"findings"`
+	// HygieneScore rates the workflow from 100 (no findings) to 0; see HygieneScore
+	HygieneScore int `json:"hygieneScore"`
+	// TextFallback is set when the file does not parse as YAML and was analyzed from its text, so
+	// triggers, jobs and calls are best-effort guesses
+	TextFallback bool `json:"textFallback,omitempty"`
+}
+
+// TriggerFilter holds the branch, path and (push only) tag filters declared on a push/pull_request
+// style trigger
+type TriggerFilter struct {
+	Branches       []string `json:"branches,omitempty"`
+	BranchesIgnore []string `json:"branchesIgnore,omitempty"`
+	Paths          []string `json:"paths,omitempty"`
+	PathsIgnore    []string `json:"pathsIgnore,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	TagsIgnore     []string `json:"tagsIgnore,omitempty"`
+}
+
+// String renders the declared filters, e.g. "branches=main paths=src/**,go.mod"
+func (tf TriggerFilter) String() string {
+	var parts []string
+	for _, f := range []struct {
+		key  string
+		list []string
+	}{
+		{"branches", tf.Branches}, {"branches-ignore", tf.BranchesIgnore},
+		{"paths", tf.Paths}, {"paths-ignore", tf.PathsIgnore},
+		{"tags", tf.Tags}, {"tags-ignore", tf.TagsIgnore},
+	} {
+		if len(f.list) > 0 {
+			parts = append(parts, f.key+"="+strings.Join(f.list, ","))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// FilterSummary lists each filtered trigger as "trigger(filters)", sorted by trigger
+func (w WorkflowReport) FilterSummary() []string {
+	var out []string
+	for t, tf := range w.TriggerFilters {
+		out = append(out, fmt.Sprintf("%s(%s)", t, tf))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// hasPathFilter reports whether the trigger is limited to changes under some paths
+func (tf TriggerFilter) hasPathFilter() bool {
+	return len(tf.Paths) > 0 || len(tf.PathsIgnore) > 0
+}
+
+// tagsOnly reports whether a push trigger fires only for tag pushes (tags filter, no branch filter)
+func (tf TriggerFilter) tagsOnly() bool {
+	return (len(tf.Tags) > 0 || len(tf.TagsIgnore) > 0) && len(tf.Branches) == 0 && len(tf.BranchesIgnore) == 0
+}
+
+// IsWorkflowFile reports whether name has a workflow YAML extension
+func IsWorkflowFile(name string) bool {
+	return strings.HasSuffix(name, ".yml") || strings.HasSuffix(name, ".yaml")
+}
+
+// WorkflowIsStale reports whether the workflow was last modified more than daysStale days ago
+func WorkflowIsStale(w WorkflowReport, daysStale int) bool {
+	return w.LastModified != nil && time.Since(*w.LastModified) > (time.Duration(daysStale)*24*time.Hour)
+}
+
+// ScanWorkflowDirs scans several workflows directories and merges the results, tagging each
+// workflow with its source directory. Missing directories only warn unless all of them are missing.
+func ScanWorkflowDirs(dirs []string, daysStale int, useGit bool, opts ScanOptions) ([]WorkflowReport, error) {
+	if len(dirs) == 0 {
+		return nil, errors.New("no workflows directory given")
+	}
+	var out []WorkflowReport
+	var errs []error
+	for _, dir := range dirs {
+		reports, err := ScanWorkflows(dir, daysStale, useGit, opts)
+		var stop *StopError
+		if errors.As(err, &stop) {
+			return append(out, reports...), err
+		}
+		if err != nil {
+			errs = append(errs, err)
+			if len(dirs) > 1 {
+				Warnf("skipping %s: %v", dir, err)
+			}
+			continue
+		}
+		for i := range reports {
+			reports[i].SourceDir = dir
+		}
+		out = append(out, reports...)
+	}
+	if len(errs) == len(dirs) {
+		return nil, errors.Join(errs...)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].File < out[j].File })
+	return out, nil
+}
+
+// FindWorkflowDirs returns every .github/workflows directory beneath root, in lexical order, for
+// monorepos whose packages carry their own workflows. Hidden directories other than .github,
+// node_modules and vendor are not descended into.
+func FindWorkflowDirs(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() || p == root {
+			return nil
+		}
+		name := d.Name()
+		if name == "node_modules" || name == "vendor" || (strings.HasPrefix(name, ".") && name != ".github") {
+			return filepath.SkipDir
+		}
+		if name == "workflows" && filepath.Base(filepath.Dir(p)) == ".github" {
+			dirs = append(dirs, p)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("find workflows dirs: %w", err)
+	}
+	return dirs, nil
+}
+
+// ScanWorkflows walks a workflows directory for YAML files and analyzes them on a pool of
+// opts.Concurrency workers. When useGit is false, last-modified comes from the filesystem mtime
+// instead of git history. Files that fail to analyze are logged to stderr and skipped.
+func ScanWorkflows(dir string, daysStale int, useGit bool, opts ScanOptions) ([]WorkflowReport, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read workflows dir: %w", err)
+	}
+	var files []os.DirEntry
+	for _, e := range entries {
+		if e.IsDir() || !IsWorkflowFile(e.Name()) {
+			continue
+		}
+		if opts.Skip != nil && opts.Skip(filepath.Join(dir, e.Name())) {
+			Tracef("Ignoring %s", filepath.Join(dir, e.Name()))
+			continue
+		}
+		files = append(files, e)
+	}
+
+	// One git call for the whole directory; nil falls back to a git log per file
+	var lastCommit map[string]time.Time
+	if useGit {
+		if lastCommit, err = gitLastModifiedBatch(dir); err != nil {
+			lastCommit = nil
+		}
+	}
+
+	type result struct {
+		wr WorkflowReport
+		ok bool
+	}
+	results := make([]result, len(files))
+	// stopAt is the lowest file index with a StopAt finding; later files need not be analyzed
+	var mu sync.Mutex
+	stopAt := len(files)
+	scanned := 0
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(opts.workers(), len(files)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				mu.Lock()
+				skip := i > stopAt
+				mu.Unlock()
+				if skip {
+					continue
+				}
+				wr, ok := scanWorkflowFile(filepath.Join(dir, files[i].Name()), files[i], daysStale, useGit, lastCommit, opts)
+				results[i] = result{wr, ok}
+				mu.Lock()
+				if ok && opts.finish(wr.Findings) != nil {
+					stopAt = min(stopAt, i)
+				}
+				scanned++
+				Progress("workflows", scanned, len(files))
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Collect in directory order so --fail-fast stops at the same file as a sequential scan
+	var out []WorkflowReport
+	for _, r := range results {
+		if !r.ok {
+			continue
+		}
+		out = append(out, r.wr)
+		if err := opts.finish(r.wr.Findings); err != nil {
+			return out, err
+		}
+	}
+	// sort by file for stable output
+	sort.Slice(out, func(i, j int) bool { return out[i].File < out[j].File })
+	return out, nil
+}
+
+// scanWorkflowFile analyzes one workflow and adds its last-modified time and recommendations.
+// lastCommit holds batched git times; when nil, git is queried for this file alone.
+func scanWorkflowFile(full string, e os.DirEntry, daysStale int, useGit bool, lastCommit map[string]time.Time, opts ScanOptions) (WorkflowReport, bool) {
+	Tracef("Scanning workflow %s", full)
+	wr, err := AnalyzeWorkflowFile(full, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to analyze %s: %v\n", full, err)
+		return wr, false
+	}
+	// Last modified from git (or filesystem mtime with --no-git)
+	if !useGit {
+		if info, err := e.Info(); err == nil {
+			ts := info.ModTime()
+			wr.LastModified = &ts
+		}
+	} else if lastCommit != nil {
+		// Untracked files have no commit time, as with the per-file lookup
+		if ts, ok := lastCommit[full]; ok {
+			wr.LastModified = &ts
+		}
+	} else if ts, err := gitLastModified(full); err == nil {
+		wr.LastModified = &ts
+	}
+	// Recommendations
+	wr.Findings = append(wr.Findings, recommendForWorkflow(wr, daysStale)...)
+	OverrideSeverities(wr.Findings, opts.SeverityOverrides)
+	wr.Recommendations = FindingMessages(wr.Findings)
+	return wr, true
+}
+
+// AnalyzeWorkflowFile parses a single workflow and runs every per-file detector on it. Files that
+// are not valid YAML are analyzed with a tolerant regex-based fallback instead of failing.
+func AnalyzeWorkflowFile(path string, opts ScanOptions) (WorkflowReport, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return WorkflowReport{}, err
+	}
+	wr, err := analyzeWorkflow(path, raw, opts)
+	if err != nil {
+		return wr, err
+	}
+	// Encoding problems are reported for both the parsed and the fallback analysis
+	wr.Findings = append(detectEncodingIssues(path, raw), wr.Findings...)
+	return wr, nil
+}
+
+func analyzeWorkflow(path string, raw []byte, opts ScanOptions) (WorkflowReport, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	var selected map[string]any
+	for {
+		var m map[string]any
+		if err := dec.Decode(&m); err != nil {
+			if err == io.EOF {
+				break
+			}
+			// YAML parsing failed; use tolerant fallback via regex-based extraction
+			return analyzeWorkflowTextFallback(path, opts)
+		}
+		// Choose the first doc that looks like a workflow (has 'on' at top level or 'jobs')
+		if m != nil && (m["on"] != nil || m["jobs"] != nil) {
+			selected = m
+			break
+		}
+		if selected == nil && m != nil {
+			// fallback to first mapping document
+			selected = m
+		}
+	}
+	if selected == nil {
+		// nothing decoded; fallback to text scan
+		return analyzeWorkflowTextFallback(path, opts)
+	}
+	wr := WorkflowReport{File: path}
+	if n, _ := selected["name"].(string); n != "" {
+		wr.Name = n
+	}
+
+	// triggers
+	wr.Triggers = extractTriggers(selected["on"])
+	// schedules
+	wr.Schedules = extractSchedules(selected["on"])
+	// branch, path and tag filters
+	wr.TriggerFilters = extractTriggerFilters(selected["on"])
+	wr.Findings = append(wr.Findings, detectUnfilteredPush(wr)...)
+	// runners
+	wr.Runners = extractRunners(selected)
+	// job dependency graph
+	wr.JobNeeds = extractJobNeeds(selected)
+	wr.Findings = append(wr.Findings, detectDeployOrdering(wr, selected)...)
+	wr.Findings = append(wr.Findings, detectDeployWithoutConcurrency(wr, selected)...)
+	wr.EnvironmentJobs = extractEnvironmentJobs(selected)
+	// concurrency (workflow or job level)
+	wr.HasConcurrency = HasConcurrency(selected)
+	wr.HasWorkflowConcurrency = selected["concurrency"] != nil
+	wr.JobsWithConcurrency = extractJobsWithConcurrency(selected)
+	if opts.ConcurrencyScope == "workflow" {
+		wr.HasConcurrency = wr.HasWorkflowConcurrency
+	}
+	// token permissions
+	wr.HasPermissions, wr.PermissionsScope = DeclaresPermissions(selected)
+	// job timeouts
+	wr.JobsWithoutTimeout = extractJobsWithoutTimeout(selected)
+	// matrix strategies
+	var matrixFindings []Finding
+	wr.Matrices, matrixFindings = extractMatrices(path, selected)
+	wr.Findings = append(wr.Findings, matrixFindings...)
+	wr.Findings = append(wr.Findings, detectMatrixIssues(wr, opts.matrixThreshold())...)
+	// actions pinning
+	wr.ActionPins = extractActionPins(selected, raw)
+	wr.UsesUnpinnedAction, wr.UnpinnedDetails = unpinnedActions(wr.ActionPins)
+	// reusable workflow calls
+	wr.ReusableWorkflows = extractReusableWorkflows(path, selected, raw)
+	wr.Findings = append(wr.Findings, detectReusableWorkflowIssues(wr)...)
+	// deprecated hints
+	wr.DeprecatedHints = detectDeprecated(wr)
+	wr.addHints(&wr.DeprecatedHints, detectDeprecatedActions(wr, selected))
+	wr.Findings = append(wr.Findings, detectDeprecatedInputs(wr, selected, opts.deprecatedInputs())...)
+	// workflow security checks
+	wr.Findings = append(wr.Findings, detectWorkflowSecurity(wr, selected, string(raw), opts.TrustedInstallers)...)
+	wr.addHints(&wr.SecurityHints, detectPRTargetCheckout(wr, selected))
+	wr.addHints(&wr.SecurityHints, detectInputInjection(wr, selected))
+	wr.addHints(&wr.SecurityHints, detectInlineSecrets(path, raw))
+	// dependency caching
+	wr.addHints(&wr.CachingHints, detectMissingSetupCache(wr, selected))
+	return wr, nil
+}
+
+var (
+	reName       = regexp.MustCompile(`(?m)^\s*name:\s*(.+?)\s*$`)
+	reCron       = regexp.MustCompile(`(?m)cron:\s*['"]?([^'"\n]+)['"]?`)
+	reRunsOnLine = regexp.MustCompile(`(?m)^\s*runs-on:\s*(.+)$`)
+	reRunsOnItem = regexp.MustCompile(`(?m)^\s*-\s*([\w\-\.]+)\s*$`)
+	// reUses matches `uses: owner/repo@ref` lines, also as a list item (- uses:), quoted or with a
+	// trailing comment
+	reUses = regexp.MustCompile(`(?m)^\s*(?:-\s+)?uses:\s*['"]?([^@\s'"#]+)(?:@([^\s'"#]+))?['"]?\s*(?:#.*)?$`)
+)
+
+func analyzeWorkflowTextFallback(path string, opts ScanOptions) (WorkflowReport, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return WorkflowReport{}, err
+	}
+	s := string(b)
+	wr := WorkflowReport{File: path, TextFallback: true}
+	if m := reName.FindStringSubmatch(s); len(m) == 2 {
+		wr.Name = strings.TrimSpace(m[1])
+	}
+	// heuristic triggers
+	known := []string{"push", "pull_request", "pull_request_target", "workflow_dispatch", "schedule", "release", "workflow_call"}
+	triggerSet := map[string]struct{}{}
+	for _, k := range known {
+		if regexp.MustCompile("(?m)^\\s*"+regexp.QuoteMeta(k)+"\\s*:").FindStringIndex(s) != nil {
+			triggerSet[k] = struct{}{}
+		}
+	}
+	for k := range triggerSet {
+		wr.Triggers = append(wr.Triggers, k)
+	}
+	sort.Strings(wr.Triggers)
+	// schedules
+	for _, m := range reCron.FindAllStringSubmatch(s, -1) {
+		if len(m) == 2 {
+			wr.Schedules = append(wr.Schedules, strings.TrimSpace(m[1]))
+		}
+	}
+	// runners (line form)
+	set := map[string]struct{}{}
+	for _, m := range reRunsOnLine.FindAllStringSubmatch(s, -1) {
+		raw := strings.TrimSpace(m[1])
+		if strings.HasPrefix(raw, "[") && strings.HasSuffix(raw, "]") {
+			raw = strings.Trim(raw, "[]")
+			for _, tok := range strings.Split(raw, ",") {
+				rr := strings.TrimSpace(strings.Trim(tok, "'\""))
+				if rr != "" {
+					set[rr] = struct{}{}
+				}
+			}
+		} else {
+			rr := strings.TrimSpace(strings.Trim(raw, "'\""))
+			if rr != "" {
+				set[rr] = struct{}{}
+			}
+		}
+	}
+	// list item form
+	for _, m := range reRunsOnItem.FindAllStringSubmatch(s, -1) {
+		rr := strings.TrimSpace(m[1])
+		if rr != "" {
+			set[rr] = struct{}{}
+		}
+	}
+	for k := range set {
+		wr.Runners = append(wr.Runners, k)
+	}
+	sort.Strings(wr.Runners)
+	// concurrency presence
+	wr.HasConcurrency = DetectConcurrencyFallback(s)
+	wr.HasWorkflowConcurrency = reTopLevelConcurrency.MatchString(s)
+	if opts.ConcurrencyScope == "workflow" {
+		wr.HasConcurrency = wr.HasWorkflowConcurrency
+	}
+	// top-level token permissions
+	wr.HasPermissions, wr.PermissionsScope = DetectPermissionsFallback(s)
+	// job timeouts
+	wr.JobsWithoutTimeout = jobsWithoutTimeoutFallback(s)
+	// action pins and unpinned uses
+	for _, m := range reUses.FindAllStringSubmatch(s, -1) {
+		ref := strings.TrimSpace(m[2])
+		usesVal := strings.TrimSpace(m[1])
+		if isLocalAction(usesVal) {
+			// Local reusable workflow calls, so the callee counts as used (the calling job is unknown)
+			if ext := filepath.Ext(usesVal); strings.HasPrefix(usesVal, "./") && (ext == ".yml" || ext == ".yaml") {
+				_, err := os.Stat(filepath.Join(workflowRepoRoot(path), filepath.FromSlash(usesVal)))
+				exists := err == nil
+				wr.ReusableWorkflows = append(wr.ReusableWorkflows, ReusableWorkflow{Uses: usesVal, Local: true, Exists: &exists})
+			}
+			continue
+		}
+		if ref != "" {
+			usesVal += "@" + ref
+		}
+		wr.ActionPins = append(wr.ActionPins, ActionPin{Uses: usesVal, Level: ClassifyPin(usesVal)})
+	}
+	wr.UsesUnpinnedAction, wr.UnpinnedDetails = unpinnedActions(wr.ActionPins)
+	// hints
+	wr.DeprecatedHints = detectDeprecated(wr)
+	wr.addHints(&wr.SecurityHints, detectPRTargetCheckoutFallback(wr, s))
+	return wr, nil
+}
+
+func extractTriggers(on any) []string {
+	var out []string
+	switch v := on.(type) {
+	case string:
+		out = append(out, v)
+	case []any:
+		for _, it := range v {
+			if s, ok := it.(string); ok {
+				out = append(out, s)
+			}
+		}
+	case map[string]any:
+		for k := range v {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func extractSchedules(on any) []string {
+	var out []string
+	m, ok := on.(map[string]any)
+	if !ok {
+		return out
+	}
+	if s, ok := m["schedule"].([]any); ok {
+		for _, it := range s {
+			if mm, ok := it.(map[string]any); ok {
+				if cron, ok := mm["cron"].(string); ok {
+					out = append(out, cron)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// branchFilterTriggers are the events that accept branches/branches-ignore (and paths) filters
+var branchFilterTriggers = []string{"push", "pull_request", "pull_request_target"}
+
+func extractTriggerFilters(on any) map[string]TriggerFilter {
+	m, ok := on.(map[string]any)
+	if !ok {
+		return nil
+	}
+	out := map[string]TriggerFilter{}
+	for _, t := range branchFilterTriggers {
+		tm, ok := m[t].(map[string]any)
+		if !ok {
+			continue
+		}
+		tf := TriggerFilter{
+			Branches: stringList(tm["branches"]), BranchesIgnore: stringList(tm["branches-ignore"]),
+			Paths: stringList(tm["paths"]), PathsIgnore: stringList(tm["paths-ignore"]),
+			Tags: stringList(tm["tags"]), TagsIgnore: stringList(tm["tags-ignore"]),
+		}
+		if tf.String() != "" {
+			out[t] = tf
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// stringList accepts a scalar or a sequence of scalars
+func stringList(v any) []string {
+	switch vv := v.(type) {
+	case string:
+		return []string{vv}
+	case []any:
+		var out []string
+		for _, it := range vv {
+			if s, ok := it.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// branchGlobMatch matches a branch name against a GitHub Actions filter pattern (*, **, ?)
+func branchGlobMatch(pattern, name string) bool {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '*' && i+1 < len(pattern) && pattern[i+1] == '*':
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return false
+	}
+	return re.MatchString(name)
+}
+
+// includesBranch evaluates a trigger's filters in order; later "!" patterns negate earlier matches
+func (tf TriggerFilter) includesBranch(branch string) bool {
+	if len(tf.Branches) > 0 {
+		included := false
+		for _, p := range tf.Branches {
+			if strings.HasPrefix(p, "!") {
+				if branchGlobMatch(p[1:], branch) {
+					included = false
+				}
+			} else if branchGlobMatch(p, branch) {
+				included = true
+			}
+		}
+		return included
+	}
+	for _, p := range tf.BranchesIgnore {
+		if branchGlobMatch(p, branch) {
+			return false
+		}
+	}
+	return true
+}
+
+// DetectDefaultBranchExcluded flags workflows whose branch-filtered triggers never fire for the default branch
+func DetectDefaultBranchExcluded(w WorkflowReport, defaultBranch string) []Finding {
+	var filtered []string
+	for _, t := range branchFilterTriggers {
+		if !containsString(w.Triggers, t) {
+			continue
+		}
+		tf, ok := w.TriggerFilters[t]
+		if !ok || tf.includesBranch(defaultBranch) {
+			return nil
+		}
+		filtered = append(filtered, fmt.Sprintf("%s(branches=%s branches-ignore=%s)", t, strings.Join(tf.Branches, ","), strings.Join(tf.BranchesIgnore, ",")))
+	}
+	if len(filtered) == 0 {
+		return nil
+	}
+	return []Finding{newFinding("workflow.skips-default-branch", w.File,
+		fmt.Sprintf("Branch filters never include default branch %q: %s; required checks may never run", defaultBranch, strings.Join(filtered, "; ")))}
+}
+
+// detectUnfilteredPush flags push triggers without paths/paths-ignore, which run on every push
+// (to the filtered branches, if any). Tag-only pushes are release workflows and are skipped.
+func detectUnfilteredPush(w WorkflowReport) []Finding {
+	if !containsString(w.Triggers, "push") {
+		return nil
+	}
+	tf := w.TriggerFilters["push"]
+	if tf.hasPathFilter() || tf.tagsOnly() {
+		return nil
+	}
+	scope := "every push"
+	if len(tf.Branches) > 0 || len(tf.BranchesIgnore) > 0 {
+		scope = "every push to the filtered branches"
+	}
+	return []Finding{newFinding("workflow.push-no-path-filter", w.File,
+		fmt.Sprintf("Runs on %s (no path filter); add paths or paths-ignore if only some changes need this workflow", scope))}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func extractRunners(root map[string]any) []string {
+	set := map[string]struct{}{}
+	jobs, ok := root["jobs"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	for _, jv := range jobs {
+		jm, ok := jv.(map[string]any)
+		if !ok {
+			continue
+		}
+		if r, ok := jm["runs-on"]; ok {
+			switch rv := r.(type) {
+			case string:
+				set[rv] = struct{}{}
+			case []any:
+				for _, it := range rv {
+					if s, ok := it.(string); ok {
+						set[s] = struct{}{}
+					}
+				}
+			}
+		}
+	}
+	var out []string
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// HasConcurrency reports whether a parsed workflow declares concurrency at workflow or job level
+func HasConcurrency(root map[string]any) bool {
+	if root == nil {
+		return false
+	}
+	if root["concurrency"] != nil {
+		return true
+	}
+	jobs, ok := root["jobs"].(map[string]any)
+	if !ok {
+		return false
+	}
+	for _, jv := range jobs {
+		jm, ok := jv.(map[string]any)
+		if !ok {
+			continue
+		}
+		if jm["concurrency"] != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// reTopLevelConcurrency finds a column-0 concurrency: key in files that do not parse
+var reTopLevelConcurrency = regexp.MustCompile(`(?m)^concurrency\s*:`)
+
+// extractJobsWithConcurrency returns the sorted IDs of jobs that declare their own concurrency
+func extractJobsWithConcurrency(root map[string]any) []string {
+	jobs, _ := root["jobs"].(map[string]any)
+	var out []string
+	for id, jv := range jobs {
+		if jm, ok := jv.(map[string]any); ok && jm["concurrency"] != nil {
+			out = append(out, id)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// DetectConcurrencyFallback is the text-based HasConcurrency for files that do not parse
+func DetectConcurrencyFallback(text string) bool {
+	lines := strings.Split(text, "\n")
+	for _, line := range lines {
+		trim := strings.TrimSpace(line)
+		if trim == "" || strings.HasPrefix(trim, "#") {
+			continue
+		}
+		if strings.HasPrefix(trim, "concurrency") {
+			// match `concurrency:` optionally followed by value
+			colon := strings.Index(trim, ":")
+			if colon != -1 {
+				key := strings.TrimSpace(trim[:colon])
+				if key == "concurrency" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// DeclaresPermissions reports whether the workflow scopes its GITHUB_TOKEN, and how: the top-level
+// permissions rendered as "read-all" or "contents: read, issues: write", or "per-job" when there
+// is no top-level block but every job declares its own
+func DeclaresPermissions(root map[string]any) (bool, string) {
+	if p, ok := root["permissions"]; ok {
+		return true, formatPermissions(p)
+	}
+	jobs, ok := root["jobs"].(map[string]any)
+	if !ok || len(jobs) == 0 {
+		return false, ""
+	}
+	for _, jv := range jobs {
+		jm, ok := jv.(map[string]any)
+		if !ok {
+			return false, ""
+		}
+		if _, own := jm["permissions"]; !own {
+			return false, ""
+		}
+	}
+	return true, "per-job"
+}
+
+func formatPermissions(p any) string {
+	switch v := p.(type) {
+	case string:
+		return v
+	case map[string]any:
+		if len(v) == 0 {
+			return "none"
+		}
+		var scopes []string
+		for k, lvl := range v {
+			scopes = append(scopes, fmt.Sprintf("%s: %v", k, lvl))
+		}
+		sort.Strings(scopes)
+		return strings.Join(scopes, ", ")
+	case nil:
+		return "none"
+	}
+	return fmt.Sprint(p)
+}
+
+var rePermissionsLine = regexp.MustCompile(`^permissions:\s*(.*?)\s*(?:#.*)?$`)
+
+// DetectPermissionsFallback is the text-based DeclaresPermissions for files that do not parse; it
+// only recognizes the top-level (column 0) block
+func DetectPermissionsFallback(text string) (bool, string) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		m := rePermissionsLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if v := strings.Trim(m[1], `"'`); v != "" {
+			if v == "{}" {
+				return true, "none"
+			}
+			return true, v
+		}
+		var scopes []string
+		for _, child := range lines[i+1:] {
+			trim := strings.TrimSpace(child)
+			if trim == "" || strings.HasPrefix(trim, "#") {
+				continue
+			}
+			if child[0] != ' ' && child[0] != '\t' {
+				break
+			}
+			if k, v, ok := strings.Cut(trim, ":"); ok {
+				v, _, _ = strings.Cut(v, "#")
+				scopes = append(scopes, strings.TrimSpace(k)+": "+strings.TrimSpace(v))
+			}
+		}
+		sort.Strings(scopes)
+		return true, valueOr(strings.Join(scopes, ", "), "none")
+	}
+	return false, ""
+}
+
+func isLocalAction(u string) bool {
+	trim := strings.TrimSpace(u)
+	return strings.HasPrefix(trim, "./") || strings.HasPrefix(trim, "../") || strings.HasPrefix(trim, "docker://")
+}
+
+// addHints records findings both as findings and as human-readable messages in a hints section
+// (SecurityHints, CachingHints, DeprecatedHints)
+func (w *WorkflowReport) addHints(hints *[]string, fs []Finding) {
+	w.Findings = append(w.Findings, fs...)
+	for _, f := range fs {
+		*hints = append(*hints, f.Message)
+	}
+}
+
+func detectDeprecated(w WorkflowReport) []string {
+	var hints []string
+	for _, r := range w.Runners {
+		if r == "ubuntu-22.04" {
+			hints = append(hints, "Consider ubuntu-24.04")
+		}
+		if r == "macos-12" {
+			hints = append(hints, "macos-12 deprecated; use macos-13/14/15")
+		}
+		if r == "self-hosted" {
+			hints = append(hints, "Ensure self-hosted runner labels are specific; add timeouts/concurrency")
+		}
+	}
+	if len(w.Schedules) > 5 {
+		hints = append(hints, "Too many schedules; consider consolidation")
+	}
+	if len(w.Triggers) > 5 {
+		hints = append(hints, "Many triggers; check for overlap with other workflows")
+	}
+	return hints
+}
+
+func recommendForWorkflow(w WorkflowReport, daysStale int) []Finding {
+	var rec []Finding
+	if WorkflowIsStale(w, daysStale) {
+		rec = append(rec, newFinding("workflow.stale", w.File, "Stale: review necessity or update tooling pins"))
+	}
+	if w.UsesUnpinnedAction {
+		rec = append(rec, newFinding("workflow.unpinned-action", w.File, "Pin actions to specific tags or SHAs"))
+	}
+	if !w.HasConcurrency {
+		rec = append(rec, newFinding("workflow.no-concurrency", w.File, "Add 'concurrency' to avoid duplicate runs on busy repos"))
+	} else if n := len(w.JobsWithConcurrency); !w.HasWorkflowConcurrency && n > 0 && n < len(w.JobNeeds) {
+		rec = append(rec, newFinding("workflow.partial-concurrency", w.File, fmt.Sprintf("Only %d of %d jobs set concurrency (%s), so runs of the other jobs can still overlap; add workflow-level concurrency (e.g. group: ${{ github.workflow }}-${{ github.ref }}) to cover every job", n, len(w.JobNeeds), strings.Join(w.JobsWithConcurrency, ", "))))
+	}
+	if !w.HasPermissions {
+		rec = append(rec, newFinding("workflow.no-permissions", w.File, "Set least-privilege token permissions at the top level (permissions: contents: read) instead of inheriting the repository default"))
+	}
+	for _, id := range w.JobsWithoutTimeout {
+		f := newFinding("workflow.job-no-timeout", w.File, fmt.Sprintf("job:%s has no timeout-minutes; set one so a hung run does not hold a runner for the 6-hour default", id))
+		f.Job = id
+		rec = append(rec, f)
+	}
+	// Jobs calling reusable workflows take their runners from the callee
+	if len(w.Runners) == 0 && len(w.ReusableWorkflows) == 0 {
+		rec = append(rec, newFinding("workflow.no-runs-on", w.File, "Specify runs-on for each job explicitly"))
+	}
+	return rec
+}
+
+func gitLastModified(path string) (time.Time, error) {
+	// Run from the file's directory, so the pathspec must be relative to it as well
+	cmd := exec.Command("git", "log", "-1", "--format=%ct", "--", filepath.Base(path))
+	cmd.Dir = filepath.Dir(path)
+	traceCommand(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return time.Time{}, err
+	}
+	secStr := strings.TrimSpace(string(out))
+	sec, err := parseInt64(secStr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0), nil
+}
+
+// gitLastModifiedBatch maps every file committed under dir (keyed as filepath.Join(dir, name)) to
+// the time of its most recent commit, using a single git log walk
+func gitLastModifiedBatch(dir string) (map[string]time.Time, error) {
+	cmd := exec.Command("git", "-c", "core.quotepath=off", "log", "--format=%x00%ct", "--name-only", "--relative", "--", ".")
+	cmd.Dir = dir
+	traceCommand(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	times := map[string]time.Time{}
+	// Each commit is "\x00<unix time>\n\n<path>\n...", newest first
+	for _, commit := range strings.Split(string(out), "\x00")[1:] {
+		ct, names, _ := strings.Cut(commit, "\n")
+		sec, err := parseInt64(strings.TrimSpace(ct))
+		if err != nil {
+			return nil, fmt.Errorf("parse git log time %q: %w", ct, err)
+		}
+		for _, name := range strings.Split(names, "\n") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			key := filepath.Join(dir, filepath.FromSlash(name))
+			if _, seen := times[key]; !seen {
+				times[key] = time.Unix(sec, 0)
+			}
+		}
+	}
+	return times, nil
+}
+
+func parseInt64(s string) (int64, error) {
+	var x int64
+	_, err := fmt.Sscanf(s, "%d", &x)
+	return x, err
+}
+
+func valueOr(s, alt string) string {
+	if strings.TrimSpace(s) == "" {
+		return alt
+	}
+	return s
+}
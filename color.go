@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/kushin77/rrctl/analyzer"
+)
+
+var (
+	// colorMode is the persistent --color flag: auto (TTY and NO_COLOR unset or empty), always, or never
+	colorMode string
+	// noColor is the persistent --no-color flag, shorthand for --color never
+	noColor bool
+)
+
+const (
+	ansiRed    = "31"
+	ansiGreen  = "32"
+	ansiYellow = "33"
+	// ansiBold is zero-padded so its escape has the same width as the color codes
+	ansiBold = "01"
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", "auto", "Colorize terminal output: auto|always|never (a non-empty NO_COLOR env disables auto)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (same as --color never; overrides it)")
+}
+
+// checkColorMode rejects a --color value other than auto, always or never
+func checkColorMode() error {
+	switch colorMode {
+	case "auto", "always", "never":
+		return nil
+	}
+	return fmt.Errorf("unsupported --color %q (want auto|always|never)", colorMode)
+}
+
+// useColor reports whether ANSI colors should be written to stdout
+func useColor() bool {
+	if noColor {
+		return false
+	}
+	switch colorMode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	// no-color.org: only a non-empty NO_COLOR disables color
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(os.Stdout)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorize wraps s in an ANSI color code when color output is enabled
+func colorize(code, s string) string {
+	if !useColor() {
+		return s
+	}
+	return fmt.Sprintf("\x1b[%sm%s\x1b[0m", code, s)
+}
+
+// severityColor picks the terminal color for a severity
+func severityColor(s analyzer.Severity) string {
+	switch s {
+	case analyzer.SeverityHigh:
+		return ansiRed
+	case analyzer.SeverityMedium:
+		return ansiYellow
+	}
+	return ansiGreen
+}
+
+// colorSeverity is a severity name in its terminal color
+func colorSeverity(s analyzer.Severity) string {
+	return colorize(severityColor(s), string(s))
+}
+
+// reSeverityTag matches the [high]/[medium]/[low] tags of Markdown finding lists
+var reSeverityTag = regexp.MustCompile(`\[(high|medium|low)\]`)
+
+// colorizeSeverityTags colors the severity tags of Markdown printed to the terminal; files and
+// pipes get the Markdown unchanged
+func colorizeSeverityTags(md []byte) []byte {
+	if !useColor() {
+		return md
+	}
+	return reSeverityTag.ReplaceAllFunc(md, func(tag []byte) []byte {
+		return []byte("[" + colorSeverity(analyzer.Severity(tag[1:len(tag)-1])) + "]")
+	})
+}
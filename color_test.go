@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestColorModeValidated(t *testing.T) {
+	root := t.TempDir()
+	for _, mode := range []string{"auto", "always", "never"} {
+		if out, err := runRRCTL(t, "pin-check", "--path", root, "--workflows", ".", "--color", mode); err != nil {
+			t.Errorf("--color %s: %v\n%s", mode, err, out)
+		}
+	}
+	_, err := runRRCTL(t, "pin-check", "--path", root, "--workflows", ".", "--color", "sometimes")
+	if err == nil || !strings.Contains(err.Error(), `unsupported --color "sometimes"`) {
+		t.Errorf("--color sometimes: got %v, want an unsupported --color error", err)
+	}
+}
+
+func TestNoColorDisablesAuto(t *testing.T) {
+	defer func(mode string) { colorMode = mode }(colorMode)
+	colorMode = "auto"
+	t.Setenv("NO_COLOR", "1")
+	if useColor() {
+		t.Error("NO_COLOR=1 should disable --color auto")
+	}
+	colorMode = "always"
+	if !useColor() {
+		t.Error("--color always should color despite NO_COLOR")
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// configPath is the persistent --config flag; empty means discover .rrctl.yaml
+var configPath string
+
+// configFileNames are looked up in the working directory, then the home directory
+var configFileNames = []string{".rrctl.yaml", ".rrctl.yml"}
+
+// flagEnvFallbacks are pre-existing environment variables read as flag defaults, besides RRCTL_<FLAG>
+var flagEnvFallbacks = map[string]string{
+	"github-token": "GITHUB_TOKEN",
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Config file of flag defaults (default: .rrctl.yaml in the working directory, then the home directory)")
+}
+
+// findConfigFile returns the explicit --config path or the first discovered config file ("" if none)
+func findConfigFile() (string, error) {
+	if configPath != "" {
+		return configPath, nil
+	}
+	dirs := []string{"."}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, home)
+	}
+	for _, dir := range dirs {
+		for _, name := range configFileNames {
+			p := filepath.Join(dir, name)
+			if _, err := os.Stat(p); err == nil {
+				return p, nil
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return "", err
+			}
+		}
+	}
+	return "", nil
+}
+
+// loadConfig reads a config file mapping flag names to values. Top-level values apply to every
+// command that has the flag; a mapping under a command name (e.g. repo-defrag, or
+// "repo-defrag validate") applies to that command only and wins over the top-level value.
+func loadConfig(path string) (map[string]any, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	cfg := map[string]any{}
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// applyConfigFile sets every flag not given on the command line from RRCTL_<FLAG> (or its legacy
+// variable, e.g. GITHUB_TOKEN) or else the config file. Precedence: flag > env > config > default.
+func applyConfigFile(cmd *cobra.Command) error {
+	path, err := findConfigFile()
+	if err != nil {
+		return err
+	}
+	cfg := map[string]any{}
+	if path != "" {
+		if cfg, err = loadConfig(path); err != nil {
+			return err
+		}
+	}
+	cmdPath := strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" ")
+	values := map[string]any{}
+	var section map[string]any
+	for k, v := range cfg {
+		if m, ok := v.(map[string]any); ok {
+			if k == cmdPath {
+				section = m
+			}
+			continue
+		}
+		values[k] = v
+	}
+	var unknown []string
+	for k, v := range section {
+		if cmd.Flags().Lookup(k) == nil {
+			unknown = append(unknown, k)
+		}
+		values[k] = v
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		warnf("%s: unknown flags for %s: %s", path, cmdPath, strings.Join(unknown, ", "))
+	}
+
+	var setErr error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if setErr != nil || f.Changed || f.Name == "config" || f.Name == "help" {
+			return
+		}
+		if v, ok := os.LookupEnv(flagEnvVar(f.Name)); ok {
+			setErr = setFlagValue(f, v)
+			return
+		}
+		if env := flagEnvFallbacks[f.Name]; env != "" && os.Getenv(env) != "" {
+			// Read by the command itself, ahead of the config file (see resolveGitHubToken)
+			return
+		}
+		if v, ok := values[f.Name]; ok {
+			if err := setFlagValue(f, v); err != nil {
+				setErr = fmt.Errorf("config %s: %w", path, err)
+			}
+		}
+	})
+	return setErr
+}
+
+// flagEnvVar is the environment variable for a flag, e.g. days-stale -> RRCTL_DAYS_STALE
+func flagEnvVar(name string) string {
+	return "RRCTL_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// setFlagValue sets a flag from a config or env value without marking it as changed on the
+// command line. Lists set repeatable flags once per item; mappings set key=value flags.
+func setFlagValue(f *pflag.Flag, v any) error {
+	var items []string
+	switch vv := v.(type) {
+	case []any:
+		for _, it := range vv {
+			items = append(items, fmt.Sprint(it))
+		}
+	case map[string]any:
+		var keys []string
+		for k := range vv {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			items = append(items, fmt.Sprintf("%s=%v", k, vv[k]))
+		}
+	case nil:
+		return nil
+	default:
+		items = []string{fmt.Sprint(vv)}
+	}
+	for _, it := range items {
+		if err := f.Value.Set(it); err != nil {
+			return fmt.Errorf("--%s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
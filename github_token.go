@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/kushin77/rrctl/analyzer"
+)
+
+// resolveGitHubToken picks the GitHub token in order: --github-token (also set from
+// RRCTL_GITHUB_TOKEN or the config file), --github-token-file, the GITHUB_TOKEN environment
+// variable, then `gh auth token` when the gh CLI is installed. It returns "" when none yields
+// a token; only an unreadable or empty token file is an error.
+func resolveGitHubToken(ctx context.Context, flagValue, tokenFile, apiURL string) (string, error) {
+	token, source := strings.TrimSpace(flagValue), "--github-token"
+	if token == "" && tokenFile != "" {
+		b, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return "", fmt.Errorf("--github-token-file: %w", err)
+		}
+		if token = strings.TrimSpace(string(b)); token == "" {
+			return "", fmt.Errorf("--github-token-file: %s is empty", tokenFile)
+		}
+		source = tokenFile
+	}
+	if token == "" {
+		token, source = strings.TrimSpace(os.Getenv("GITHUB_TOKEN")), "GITHUB_TOKEN"
+	}
+	if token == "" {
+		token, source = ghCLIToken(ctx, apiURL), "gh auth token"
+	}
+	if token == "" {
+		return "", nil
+	}
+	analyzer.RegisterSecret(token)
+	debugf("Using the GitHub token from %s", source)
+	return token, nil
+}
+
+// ghCLIToken asks the gh CLI for the token it is logged in with, for the host of apiURL
+// (--github-api-url) on GitHub Enterprise Server. It returns "" when gh is not installed or not
+// logged in.
+func ghCLIToken(ctx context.Context, apiURL string) string {
+	gh, err := exec.LookPath("gh")
+	if err != nil {
+		return ""
+	}
+	args := []string{"auth", "token"}
+	if apiURL != "" && apiURL != analyzer.DefaultGitHubAPIURL {
+		if u, err := url.Parse(apiURL); err == nil && u.Host != "" {
+			args = append(args, "--hostname", u.Host)
+		}
+	}
+	out, err := exec.CommandContext(ctx, gh, args...).Output()
+	if err != nil {
+		debugf("gh auth token: %v", err)
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
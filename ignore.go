@@ -0,0 +1,43 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/kushin77/rrctl/analyzer"
+)
+
+// workflowIgnorer skips workflow files matching --ignore or the repository's .rrctlignore and
+// counts them
+type workflowIgnorer struct {
+	root     string
+	patterns []string
+	skipped  int
+}
+
+func newWorkflowIgnorer(root string, flagPatterns []string) (*workflowIgnorer, error) {
+	filePatterns, err := analyzer.LoadIgnorePatterns(root)
+	if err != nil {
+		return nil, err
+	}
+	patterns := append(append([]string{}, flagPatterns...), filePatterns...)
+	if err := analyzer.ValidateIgnorePatterns(patterns); err != nil {
+		return nil, err
+	}
+	return &workflowIgnorer{root: root, patterns: patterns}, nil
+}
+
+// skip reports whether the workflow at path (under root) is ignored, counting it if so
+func (ig *workflowIgnorer) skip(path string) bool {
+	if len(ig.patterns) == 0 {
+		return false
+	}
+	rel, err := filepath.Rel(ig.root, path)
+	if err != nil {
+		rel = path
+	}
+	if analyzer.MatchesIgnore(ig.patterns, rel) {
+		ig.skipped++
+		return true
+	}
+	return false
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/kushin77/rrctl/analyzer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintPath          string
+	lintWorkflows     []string
+	lintDaysStale     int
+	lintMatrixMax     int
+	lintConcScope     string
+	lintThreshold     string
+	lintFormat        string
+	lintJUnit         string
+	lintSecrets       bool
+	lintPerms         bool
+	lintOverrides     []string
+	lintNoBuiltinRule bool
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "CI gate: workflow analysis and security scan with one pass/fail result",
+	Long: `Run the repo-defrag workflow and local action analysis together with the security-scan secret,
+credential URL and file permission checks, and report every finding in one list.
+Exits non-zero when any finding is at or above --severity-threshold.`,
+	RunE: runLint,
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().StringVarP(&lintPath, "path", "p", ".", "Root path of the repository")
+	lintCmd.Flags().StringSliceVar(&lintWorkflows, "workflows", []string{".github/workflows"}, "Relative path to a workflows directory (comma-separated or repeatable)")
+	lintCmd.Flags().IntVar(&lintDaysStale, "days-stale", 60, "Days without change considered stale for workflows")
+	lintCmd.Flags().IntVar(&lintMatrixMax, "matrix-threshold", 20, "Flag jobs whose strategy.matrix expands to more jobs than this")
+	lintCmd.Flags().StringVar(&lintConcScope, "concurrency-scope", "any", "Concurrency that satisfies workflow.no-concurrency: any (workflow or job level) or workflow (top-level block only)")
+	lintCmd.Flags().StringVar(&lintThreshold, "severity-threshold", "medium", "Fail when findings at or above this severity exist (low|medium|high)")
+	lintCmd.Flags().StringVar(&lintFormat, "format", "text", "Stdout format: text, json, sarif or junit (XML test report)")
+	lintCmd.Flags().StringVar(&lintJUnit, "junit", "", "Write a JUnit XML test report (one testcase per workflow per rule, failing with the recommendations) to path (optional)")
+	lintCmd.Flags().BoolVar(&lintSecrets, "secrets", true, "Scan for secrets and credentials embedded in URLs")
+	lintCmd.Flags().BoolVar(&lintPerms, "perms", true, "Check for group/world-writable files and group/world-readable sensitive files")
+	lintCmd.Flags().StringArrayVar(&lintOverrides, "severity-override", nil, "Remap a rule's severity as rule=level (repeatable), e.g. workflow.no-concurrency=high")
+	lintCmd.Flags().BoolVar(&lintNoBuiltinRule, "no-builtin-rules", false, "Disable the built-in AWS/GitHub/Slack token rules")
+}
+
+// LintReport is the --format json output of lint
+type LintReport struct {
+	Path      string             `json:"path"`
+	Threshold analyzer.Severity  `json:"severityThreshold"`
+	Passed    bool               `json:"passed"`
+	Failing   int                `json:"failingFindings"`
+	Findings  []analyzer.Finding `json:"findings"`
+	Errors    []string           `json:"errors,omitempty"`
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	switch lintFormat {
+	case "text", "json", "sarif", "junit":
+	default:
+		return fmt.Errorf("unsupported --format %q (want text|json|sarif|junit)", lintFormat)
+	}
+	threshold, err := analyzer.ParseSeverity(lintThreshold)
+	if err != nil {
+		return fmt.Errorf("--severity-threshold: %w", err)
+	}
+	scan, err := newScanOptions(lintConcScope, lintMatrixMax, lintOverrides)
+	if err != nil {
+		return err
+	}
+
+	report := LintReport{Path: lintPath, Threshold: threshold, Findings: []analyzer.Finding{}}
+	failed := func(check string, err error) {
+		report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", check, err))
+	}
+
+	var dirs []string
+	for _, p := range lintWorkflows {
+		dirs = append(dirs, filepath.Join(lintPath, p))
+	}
+	stopProgress := startProgress(lintFormat == "text")
+	workflows, err := analyzer.ScanWorkflowDirs(dirs, lintDaysStale, true, scan)
+	stopProgress()
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && !cmd.Flags().Changed("workflows"):
+		// A repository without GitHub Actions still gets the security checks
+		debugf("No workflows directory: %v", err)
+	case err != nil:
+		failed("Workflow scan", err)
+	}
+	for _, w := range workflows {
+		report.Findings = append(report.Findings, w.Findings...)
+	}
+	actions, err := analyzer.ScanActions(lintPath, scan)
+	if err != nil {
+		failed("Action scan", err)
+	}
+	for _, a := range actions {
+		report.Findings = append(report.Findings, a.Findings...)
+	}
+
+	// The security-scan checks print to securityOut and record into a SecurityReport
+	securityOut = io.Discard
+	secretsBaseline = nil
+	secretRules = nil
+	if !lintNoBuiltinRule {
+		secretRules = append(secretRules, analyzer.BuiltinSecretRules...)
+	}
+	fixPerms, applyFixes = false, false
+	sec := &SecurityReport{Path: lintPath}
+	if lintSecrets {
+		if err := scanForSecrets(lintPath, sec); err != nil {
+			failed("Secrets scan", err)
+		}
+		if err := scanCredentialURLs(lintPath, false, sec); err != nil {
+			failed("Credential URL scan", err)
+		}
+	}
+	if lintPerms {
+		if err := checkFilePermissions(lintPath, sec); err != nil {
+			failed("Permission check", err)
+		}
+	}
+	for _, sf := range sec.Secrets {
+		report.Findings = append(report.Findings, analyzer.Finding{RuleID: sf.Rule, Severity: sf.Severity, File: sf.File, Line: sf.Line, Message: sf.Message})
+	}
+	for _, pw := range sec.PermissionWarnings {
+		report.Findings = append(report.Findings, analyzer.PermissionFinding(pw.File, pw.Mode))
+	}
+	for _, pw := range sec.SensitiveFiles {
+		report.Findings = append(report.Findings, analyzer.SensitiveReadableFinding(pw.File, pw.Mode))
+	}
+
+	// The security-scan findings are built here rather than by a scan, so remap them as well
+	analyzer.OverrideSeverities(report.Findings, scan.SeverityOverrides)
+	analyzer.AssignFingerprints(report.Findings)
+	analyzer.SortFindings(report.Findings, "severity")
+	report.Failing, _ = analyzer.CountAtLeast(report.Findings, threshold)
+	report.Passed = report.Failing == 0 && len(report.Errors) == 0
+
+	junit := renderJUnit("rrctl lint", time.Now(), lintJUnitTargets(report, workflows, actions))
+	if lintJUnit != "" {
+		if err := os.WriteFile(lintJUnit, junit, 0o644); err != nil {
+			return err
+		}
+		// Keep machine-readable stdout clean
+		notes := io.Writer(os.Stdout)
+		if lintFormat != "text" {
+			notes = os.Stderr
+		}
+		infof(notes, "Wrote JUnit report to %s\n", lintJUnit)
+	}
+
+	switch lintFormat {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	case "sarif":
+		var findings []analyzer.SarifFinding
+		for _, f := range report.Findings {
+			findings = append(findings, analyzer.SarifFinding{Finding: f})
+		}
+		if err := analyzer.NewSarifLog(version, lintPath, findings).Encode(os.Stdout); err != nil {
+			return err
+		}
+	case "junit":
+		os.Stdout.Write(junit)
+	default:
+		printLintText(os.Stdout, report)
+	}
+
+	if !report.Passed {
+		cmd.SilenceUsage = true
+		if report.Failing == 0 {
+			return errors.New("lint: checks failed to run")
+		}
+		_, byRule := analyzer.CountAtLeast(report.Findings, threshold)
+		return fmt.Errorf("lint: %d findings at or above %s severity (%s)", report.Failing, threshold, analyzer.FormatRuleCounts(byRule))
+	}
+	return nil
+}
+
+// lintJUnitTargets groups lint findings by file for the JUnit report: workflows and local actions
+// are checked against every rule of their kind, other files list the secret and permission rules
+// they break, and the repository carries the enabled security checks that found nothing
+func lintJUnitTargets(r LintReport, workflows []analyzer.WorkflowReport, actions []analyzer.ActionReport) []junitTarget {
+	byFile := map[string][]analyzer.Finding{}
+	broken := map[string]bool{}
+	for _, f := range r.Findings {
+		byFile[f.File] = append(byFile[f.File], f)
+		broken[f.RuleID] = true
+	}
+	var out []junitTarget
+	add := func(file string, rules []string) {
+		out = append(out, junitTarget{File: file, Rules: rules, Findings: byFile[file]})
+		delete(byFile, file)
+	}
+	for _, w := range workflows {
+		add(w.File, analyzer.RuleIDs("workflow."))
+	}
+	for _, a := range actions {
+		add(a.File, analyzer.RuleIDs("action."))
+	}
+	var rest []string
+	for file := range byFile {
+		rest = append(rest, file)
+	}
+	sort.Strings(rest)
+	for _, file := range rest {
+		add(file, nil)
+	}
+	var checked []string
+	if lintSecrets {
+		checked = append(checked, analyzer.RuleIDs("secret.")...)
+	}
+	if lintPerms {
+		checked = append(checked, analyzer.RuleIDs("file.")...)
+	}
+	var passed []string
+	for _, rule := range checked {
+		if !broken[rule] {
+			passed = append(passed, rule)
+		}
+	}
+	if len(passed) > 0 {
+		out = append(out, junitTarget{File: r.Path, Rules: passed})
+	}
+	return out
+}
+
+// printLintText lists every finding, most severe first, then the pass/fail verdict
+func printLintText(out io.Writer, r LintReport) {
+	for _, f := range r.Findings {
+		loc := f.File
+		if f.Line > 0 {
+			loc = fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		fmt.Fprintf(out, "%s: [%s] %s (%s)\n", loc, colorSeverity(f.Severity), f.Message, f.RuleID)
+	}
+	for _, e := range r.Errors {
+		fmt.Fprintf(out, "Check failed: %s\n", e)
+	}
+	verdict := colorize(ansiGreen, "PASS")
+	if !r.Passed {
+		verdict = colorize(ansiRed, "FAIL")
+	}
+	fmt.Fprintf(out, "lint %s: %d findings, %d at or above %s\n", verdict, len(r.Findings), r.Failing, r.Threshold)
+}
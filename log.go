@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/kushin77/rrctl/analyzer"
+)
+
+// Output levels set by the persistent --quiet and --verbose flags. Errors and data (reports,
+// findings, summaries) are printed at every level.
+const (
+	levelQuiet = iota
+	levelNormal
+	levelVerbose
+)
+
+var (
+	quietOutput   bool
+	verboseOutput bool
+	outputLevel   = levelNormal
+	// logMu keeps lines from parallel workflow scans whole
+	logMu sync.Mutex
+)
+
+func init() {
+	rootCmd.PersistentFlags().BoolVarP(&quietOutput, "quiet", "q", false, "Print only errors and data (reports, findings, summaries); no progress, confirmations or warnings")
+	rootCmd.PersistentFlags().BoolVar(&verboseOutput, "verbose", false, "Also print per-file scan progress, git commands run and GitHub URLs fetched (to stderr)")
+}
+
+// setOutputLevel applies --quiet/--verbose to this package and the analyzer hooks
+func setOutputLevel() error {
+	if quietOutput && verboseOutput {
+		return fmt.Errorf("--quiet and --verbose are mutually exclusive")
+	}
+	outputLevel = levelNormal
+	switch {
+	case quietOutput:
+		outputLevel = levelQuiet
+	case verboseOutput:
+		outputLevel = levelVerbose
+	}
+	analyzer.Warnf = warnf
+	analyzer.Tracef = debugf
+	return nil
+}
+
+func logf(level int, w io.Writer, format string, args ...any) {
+	if outputLevel < level {
+		return
+	}
+	logMu.Lock()
+	defer logMu.Unlock()
+	clearProgressLocked()
+	// Warnings and --verbose lines quote URLs, headers and API errors; never print a credential
+	fmt.Fprint(w, analyzer.Redact(fmt.Sprintf(format, args...)))
+}
+
+// infof prints progress and confirmations ("Wrote ... to ...") to w; --quiet drops them
+func infof(w io.Writer, format string, args ...any) {
+	logf(levelNormal, w, format, args...)
+}
+
+// warnf prints a "Warning: " line to stderr; --quiet drops it
+func warnf(format string, args ...any) {
+	logf(levelNormal, os.Stderr, "Warning: "+format+"\n", args...)
+}
+
+// debugf prints a progress line to stderr with --verbose
+func debugf(format string, args ...any) {
+	logf(levelVerbose, os.Stderr, format+"\n", args...)
+}
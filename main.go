@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/kushin77/rrctl/analyzer"
 	"github.com/spf13/cobra"
 )
 
@@ -15,8 +19,14 @@ var (
 )
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	// Ctrl-C cancels in-flight GitHub API requests; a second Ctrl-C kills the process as usual
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", analyzer.Redact(err.Error()))
 		os.Exit(1)
 	}
 }
@@ -26,6 +36,8 @@ var rootCmd = &cobra.Command{
 	Short:   "RoundRobin Control - Open Source DevOps CLI",
 	Long:    `rrctl is an open-source CLI tool for DevOps automation and security scanning.`,
 	Version: fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, date),
+	// main prints the error once, through analyzer.Redact
+	SilenceErrors: true,
 }
 
 var versionCmd = &cobra.Command{
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kushin77/rrctl/analyzer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pinCheckPath      string
+	pinCheckWorkflows []string
+	pinCheckRequire   string
+	pinCheckFormat    string
+)
+
+var pinCheckCmd = &cobra.Command{
+	Use:   "pin-check",
+	Short: "CI gate: fail when workflow actions are not pinned to a tag or commit SHA",
+	Long: `Scan workflows and list every step-level action and job-level reusable workflow reference
+pinned below --require, with its file, line, job and step. Branch refs (main, master, any
+non-version ref) and references without @ref always fail; --require sha also fails version tags.
+Exits non-zero when any are found. Local (./) and docker:// references are not checked.`,
+	RunE: runPinCheck,
+}
+
+func init() {
+	rootCmd.AddCommand(pinCheckCmd)
+
+	pinCheckCmd.Flags().StringVarP(&pinCheckPath, "path", "p", ".", "Root path of the repository")
+	pinCheckCmd.Flags().StringSliceVar(&pinCheckWorkflows, "workflows", []string{".github/workflows"}, "Relative path to a workflows directory (comma-separated or repeatable)")
+	pinCheckCmd.Flags().StringVar(&pinCheckRequire, "require", "tag", "Minimum pin level: tag (version tag or commit SHA) or sha (full commit SHA only)")
+	pinCheckCmd.Flags().StringVar(&pinCheckFormat, "format", "json", "Stdout format: json or text")
+}
+
+// PinCheckReport is the output of pin-check
+type PinCheckReport struct {
+	Path    string            `json:"path"`
+	Require analyzer.PinLevel `json:"require"`
+	Passed  bool              `json:"passed"`
+	// Checked counts the references checked; Counts grades them by pin level
+	Checked    int                `json:"checked"`
+	Counts     analyzer.PinCounts `json:"counts"`
+	Violations []PinViolation     `json:"violations"`
+}
+
+// PinViolation is a uses: reference pinned below --require
+type PinViolation struct {
+	File string `json:"file"`
+	// Kind is "action" for a step's action or "reusable-workflow" for a job-level call
+	Kind string `json:"kind"`
+	analyzer.ActionPin
+}
+
+func runPinCheck(cmd *cobra.Command, args []string) error {
+	require := analyzer.PinLevel(pinCheckRequire)
+	if require != analyzer.PinTag && require != analyzer.PinSHA {
+		return fmt.Errorf("unsupported --require %q (want sha|tag)", pinCheckRequire)
+	}
+	switch pinCheckFormat {
+	case "json", "text":
+	default:
+		return fmt.Errorf("unsupported --format %q (want json|text)", pinCheckFormat)
+	}
+
+	var dirs []string
+	for _, p := range pinCheckWorkflows {
+		dirs = append(dirs, filepath.Join(pinCheckPath, p))
+	}
+	stopProgress := startProgress(pinCheckFormat == "text")
+	workflows, err := analyzer.ScanWorkflowDirs(dirs, 0, false, analyzer.ScanOptions{})
+	stopProgress()
+	if err != nil {
+		return fmt.Errorf("workflow scan: %w", err)
+	}
+
+	report := PinCheckReport{Path: pinCheckPath, Require: require, Violations: []PinViolation{}}
+	for _, w := range workflows {
+		check := func(kind string, pins []analyzer.ActionPin) {
+			report.Checked += len(pins)
+			report.Counts.Add(pins)
+			for _, p := range pins {
+				if !p.Level.AtLeast(require) {
+					report.Violations = append(report.Violations, PinViolation{File: w.File, Kind: kind, ActionPin: p})
+				}
+			}
+		}
+		check("action", w.ActionPins)
+		var calls []analyzer.ActionPin
+		for _, rw := range w.ReusableWorkflows {
+			if !rw.Local {
+				calls = append(calls, analyzer.ActionPin{Job: rw.Job, Line: rw.Line, Uses: rw.Uses, Level: analyzer.ClassifyPin(rw.Uses)})
+			}
+		}
+		check("reusable-workflow", calls)
+	}
+	// Steps and job-level calls of one file in line order
+	sort.SliceStable(report.Violations, func(i, j int) bool {
+		a, b := report.Violations[i], report.Violations[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	report.Passed = len(report.Violations) == 0
+
+	if pinCheckFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printPinCheckText(os.Stdout, report)
+	}
+
+	if !report.Passed {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("pin-check: %d of %d uses: references are pinned below %s", len(report.Violations), report.Checked, require)
+	}
+	return nil
+}
+
+// printPinCheckText lists each violation as file:line, then the pass/fail verdict
+func printPinCheckText(out io.Writer, r PinCheckReport) {
+	for _, v := range r.Violations {
+		loc := v.File
+		if v.Line > 0 {
+			loc = fmt.Sprintf("%s:%d", v.File, v.Line)
+		}
+		where := ""
+		switch {
+		case v.Step != "":
+			where = fmt.Sprintf("job:%s step:%s: ", v.Job, v.Step)
+		case v.Job != "":
+			where = fmt.Sprintf("job:%s: ", v.Job)
+		}
+		what := v.Uses
+		if v.Kind == "reusable-workflow" {
+			what = "reusable workflow " + v.Uses
+		}
+		fmt.Fprintf(out, "%s: %s%s is pinned to %s (want %s)\n", loc, where, what, pinLevelText(v.Level), r.Require)
+	}
+	verdict := colorize(ansiGreen, "PASS")
+	if !r.Passed {
+		verdict = colorize(ansiRed, "FAIL")
+	}
+	fmt.Fprintf(out, "pin-check %s: %d uses: references, %d below %s (sha: %d, tag: %d, branch: %d, none: %d)\n",
+		verdict, r.Checked, len(r.Violations), r.Require, r.Counts.SHA, r.Counts.Tag, r.Counts.Branch, r.Counts.None)
+}
+
+func pinLevelText(l analyzer.PinLevel) string {
+	switch l {
+	case analyzer.PinNone:
+		return "nothing (no @ref)"
+	case analyzer.PinBranch:
+		return "a branch"
+	case analyzer.PinTag:
+		return "a tag"
+	}
+	return "a commit SHA"
+}
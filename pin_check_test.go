@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPinCheck(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, ".github", "workflows")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	workflow := `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@0c45773b623bea8c8e75f6c82b208c3cf94ea4f9
+      - name: Setup
+        uses: actions/setup-go@v5
+      - uses: some/act@release-1
+      - uses: ./local
+  deploy:
+    uses: org/repo/.github/workflows/deploy.yml@main
+  release:
+    uses: org/repo/.github/workflows/release.yml@v2
+  local:
+    uses: ./.github/workflows/lib.yml
+`
+	if err := os.WriteFile(filepath.Join(dir, "ci.yml"), []byte(workflow), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		require string
+		want    []string // kind job line uses of each violation
+	}{
+		"tag": {require: "tag", want: []string{
+			"action build 9 some/act@release-1",
+			"reusable-workflow deploy 12 org/repo/.github/workflows/deploy.yml@main",
+		}},
+		"sha": {require: "sha", want: []string{
+			"action build 8 actions/setup-go@v5",
+			"action build 9 some/act@release-1",
+			"reusable-workflow deploy 12 org/repo/.github/workflows/deploy.yml@main",
+			"reusable-workflow release 14 org/repo/.github/workflows/release.yml@v2",
+		}},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			out, err := runRRCTL(t, "pin-check", "--path", root, "--require", c.require)
+			if err == nil || !strings.Contains(err.Error(), "pinned below "+c.require) {
+				t.Fatalf("expected a pin-check failure, got %v", err)
+			}
+			var report PinCheckReport
+			if err := json.Unmarshal([]byte(out), &report); err != nil {
+				t.Fatalf("%v\n%s", err, out)
+			}
+			if report.Passed || report.Checked != 5 {
+				t.Errorf("passed=%v checked=%d, want false and 5", report.Passed, report.Checked)
+			}
+			var got []string
+			for _, v := range report.Violations {
+				got = append(got, strings.Join([]string{v.Kind, v.Job, strconv.Itoa(v.Line), v.Uses}, " "))
+			}
+			if strings.Join(got, "\n") != strings.Join(c.want, "\n") {
+				t.Errorf("violations\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(c.want, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/kushin77/rrctl/analyzer"
+)
+
+// progressFrames is the spinner drawn in front of the progress counter
+const progressFrames = `|/-\`
+
+// progressInterval limits redraws, so fast scans do not spend their time writing to the terminal
+const progressInterval = 100 * time.Millisecond
+
+// progress is the state of the single stderr line redrawn by analyzer.Progress; logMu guards it
+var progress struct {
+	drawn bool
+	last  time.Time
+	frame int
+}
+
+// startProgress points analyzer.Progress at a "Scanned 412/1000 files" counter on stderr and
+// returns a function that erases it again. The counter is only drawn when enabled (the command
+// writes its text output to stdout), outside --quiet, and when stdout and stderr are both terminals,
+// so redirected and CI output never contains it.
+func startProgress(enabled bool) (stop func()) {
+	if !enabled || outputLevel < levelNormal || !isTerminal(os.Stdout) || !isTerminal(os.Stderr) {
+		return func() {}
+	}
+	analyzer.Progress = drawProgress
+	return func() {
+		analyzer.Progress = func(string, int, int) {}
+		logMu.Lock()
+		defer logMu.Unlock()
+		clearProgressLocked()
+	}
+}
+
+// drawProgress redraws the counter for done of total items (total 0: not known up front),
+// at most every progressInterval except for the last item
+func drawProgress(items string, done, total int) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	now := time.Now()
+	if now.Sub(progress.last) < progressInterval && (total == 0 || done < total) {
+		return
+	}
+	progress.last = now
+	progress.frame = (progress.frame + 1) % len(progressFrames)
+	count := fmt.Sprint(done)
+	if total > 0 {
+		count = fmt.Sprintf("%d/%d", done, total)
+	}
+	fmt.Fprintf(os.Stderr, "\r\x1b[K%c Scanned %s %s", progressFrames[progress.frame], count, items)
+	progress.drawn = true
+}
+
+// clearProgressLocked erases the counter line, if drawn, before other output; callers hold logMu
+func clearProgressLocked() {
+	if progress.drawn {
+		fmt.Fprint(os.Stderr, "\r\x1b[K")
+		progress.drawn = false
+	}
+}
+
+// printAboveProgress writes report output to w while a counter may be drawn, erasing it first;
+// unlike infof it is printed at every output level
+func printAboveProgress(w io.Writer, format string, args ...any) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	clearProgressLocked()
+	fmt.Fprintf(w, format, args...)
+}
@@ -1,14 +1,16 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/kushin77/rrctl/analyzer"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -19,15 +21,48 @@ var (
 	autofixDryRun        bool
 	autofixPatchOut      string
 	autofixJSON          bool
+	autofixFormat        string
+	autofixNormalizeEnc  bool
+	autofixCheck         bool
+	autofixInteractive   bool
+	autofixPinSHA        bool
+	autofixGitHubToken   string
+	autofixTokenFile     string
+	autofixGitHubAPIURL  string
+	autofixGitHubRetries int
+	autofixAddPerms      bool
+	autofixUpgradeRun    bool
+	autofixRunnerMap     map[string]string
+	autofixBackupOn      bool
+	autofixRestore       bool
+	autofixForce         bool
+	autofixConcGroup     string
+	autofixCancel        string
+	autofixIgnore        []string
 )
 
+// defaultConcurrencyGroup is the group of inserted concurrency blocks unless --concurrency-group is set
+const defaultConcurrencyGroup = "${{ github.workflow }}-${{ github.ref }}"
+
+// reEnvironmentKey finds job-level environment: keys in files yaml.v3 cannot parse
+var reEnvironmentKey = regexp.MustCompile(`(?m)^\s+environment:`)
+
+// defaultRunnerUpgrades maps the deprecated hosted runner labels flagged by repo-defrag to replacements
+var defaultRunnerUpgrades = map[string]string{
+	"ubuntu-22.04": "ubuntu-24.04",
+	"macos-12":     "macos-14",
+}
+
 var repoAutofixCmd = &cobra.Command{
 	Use:   "repo-autofix",
 	Short: "Auto-fix workflows: add concurrency, pin common actions",
 	Long: `Apply safe automated fixes to .github/workflows:
-- Add concurrency block with cancel-in-progress
+- Add concurrency block (cancel-in-progress: false for deployment workflows, true otherwise)
 - Pin common unpinned actions to latest stable versions
-- Output unified diff patch for review/apply`,
+- Optionally add least-privilege token permissions (--add-permissions)
+- Optionally upgrade deprecated hosted runner labels (--upgrade-runners)
+- Output unified diff patch for review/apply
+- Review each file's diff and accept or reject it (--interactive)`,
 	RunE: runRepoAutofix,
 }
 
@@ -39,19 +74,127 @@ func init() {
 	repoAutofixCmd.Flags().BoolVar(&autofixDryRun, "dry-run", true, "Dry run mode (default true); set false to write changes")
 	repoAutofixCmd.Flags().StringVar(&autofixPatchOut, "patch", "", "Write unified diff patch to file (optional)")
 	repoAutofixCmd.Flags().BoolVar(&autofixJSON, "json", false, "Output results in JSON format")
+	repoAutofixCmd.Flags().StringVar(&autofixFormat, "format", "text", "Stdout format: text or json (same as --json)")
+	repoAutofixCmd.Flags().BoolVar(&autofixNormalizeEnc, "normalize-encoding", false, "Strip UTF-8 BOMs and convert CRLF line endings to LF")
+	repoAutofixCmd.Flags().BoolVar(&autofixAddPerms, "add-permissions", false, "Add a top-level 'permissions: contents: read' block to workflows that declare no token permissions (review workflows that need write scopes)")
+	repoAutofixCmd.Flags().BoolVar(&autofixUpgradeRun, "upgrade-runners", false, "Rewrite deprecated hosted runner labels in runs-on (ubuntu-22.04 -> ubuntu-24.04, macos-12 -> macos-14); self-hosted runners are left alone")
+	repoAutofixCmd.Flags().StringToStringVar(&autofixRunnerMap, "runner-map", nil, "Extra or overriding runner upgrades for --upgrade-runners, e.g. macos-12=macos-15,ubuntu-20.04=ubuntu-24.04")
+	repoAutofixCmd.Flags().BoolVar(&autofixPinSHA, "pin-sha", false, "Pin every public action to the commit SHA of its tag via the GitHub API, keeping the tag as a comment")
+	repoAutofixCmd.Flags().StringVar(&autofixGitHubToken, "github-token", "", "GitHub token for --pin-sha lookups, raising the API rate limit (default: --github-token-file, then env GITHUB_TOKEN, then gh auth token)")
+	repoAutofixCmd.Flags().StringVar(&autofixTokenFile, "github-token-file", "", "Read the GitHub token for --pin-sha lookups from this file (surrounding whitespace is trimmed)")
+	repoAutofixCmd.Flags().StringVar(&autofixGitHubAPIURL, "github-api-url", os.Getenv("GITHUB_API_URL"), "GitHub REST API root for --pin-sha lookups, e.g. https://github.mycorp.com/api/v3 for GitHub Enterprise Server (env GITHUB_API_URL supported; default https://api.github.com)")
+	repoAutofixCmd.Flags().IntVar(&autofixGitHubRetries, "github-retries", analyzer.DefaultGitHubRetries, "Retry a --pin-sha lookup this many times after a network error, 5xx or rate limit, with exponential backoff (0 = no retries)")
+	repoAutofixCmd.Flags().BoolVar(&autofixBackupOn, "backup", false, "With --dry-run=false, copy each file to .rrctl-backups/<timestamp>/ before overwriting it")
+	repoAutofixCmd.Flags().BoolVar(&autofixRestore, "restore", false, "Restore the files of the most recent --backup run and exit (no fixes are applied)")
+	repoAutofixCmd.Flags().BoolVar(&autofixForce, "force", false, "Write fixes even when the fixed file no longer parses as YAML (by default such files are skipped)")
+	repoAutofixCmd.Flags().StringVar(&autofixConcGroup, "concurrency-group", defaultConcurrencyGroup, "Group expression of inserted concurrency blocks")
+	repoAutofixCmd.Flags().StringVar(&autofixCancel, "cancel-in-progress", "auto", "cancel-in-progress of inserted concurrency blocks: true, false, or auto (false for deployment workflows, true otherwise)")
+	repoAutofixCmd.Flags().StringArrayVar(&autofixIgnore, "ignore", nil, "Leave workflow files matching this glob, relative to the root, untouched (repeatable; also read from .rrctlignore)")
+	repoAutofixCmd.Flags().BoolVar(&autofixInteractive, "interactive", false, "Show each file's diff and ask [y]es/[n]o/[a]ll/[q]uit before writing it; only accepted files are written (without a terminal this falls back to a dry run)")
+	repoAutofixCmd.Flags().BoolVar(&autofixCheck, "check", false, "CI gate: change nothing and exit non-zero if any workflow would be fixed, listing the reasons per file")
 }
 
 func runRepoAutofix(cmd *cobra.Command, args []string) error {
+	if autofixCheck && cmd.Flags().Changed("dry-run") && !autofixDryRun {
+		return fmt.Errorf("--check never writes changes; drop --dry-run=false")
+	}
+	switch autofixFormat {
+	case "text", "json":
+	default:
+		return fmt.Errorf("unsupported --format %q (want text|json)", autofixFormat)
+	}
+	if autofixCancel != "auto" {
+		if _, err := strconv.ParseBool(autofixCancel); err != nil {
+			return fmt.Errorf("invalid --cancel-in-progress %q (want true|false|auto)", autofixCancel)
+		}
+	}
+	if strings.TrimSpace(autofixConcGroup) == "" {
+		return fmt.Errorf("--concurrency-group cannot be empty")
+	}
+	apiURL, err := parseGitHubAPIURL(autofixGitHubAPIURL)
+	if err != nil {
+		return err
+	}
+	if err := checkGitHubRetries(autofixGitHubRetries); err != nil {
+		return err
+	}
+	if autofixPinSHA {
+		if autofixGitHubToken, err = resolveGitHubToken(cmd.Context(), autofixGitHubToken, autofixTokenFile, apiURL); err != nil {
+			return err
+		}
+	}
+	gh := analyzer.GitHubOptions{Token: autofixGitHubToken, APIURL: apiURL, Retries: autofixGitHubRetries}
+	jsonOutput := autofixJSON || autofixFormat == "json"
+	if autofixInteractive && (autofixCheck || jsonOutput) {
+		return fmt.Errorf("--interactive cannot be combined with --check or JSON output")
+	}
+	dryRun := autofixDryRun || autofixCheck
 	root := autofixPath
+
+	if autofixRestore {
+		if autofixCheck {
+			return fmt.Errorf("--restore cannot be combined with --check")
+		}
+		dir, restored, err := restoreLatestBackup(root)
+		if err != nil {
+			return err
+		}
+		for _, p := range restored {
+			fmt.Printf("Restored: %s\n", p)
+		}
+		infof(os.Stdout, "Restored %d files from %s\n", len(restored), dir)
+		return nil
+	}
+	var prompter *autofixPrompter
+	if autofixInteractive {
+		// Accepting a file writes it, so --interactive needs no --dry-run=false
+		if prompter = newAutofixPrompter(); prompter != nil {
+			dryRun = false
+		} else {
+			warnf("--interactive needs a terminal on stdin and stdout; doing a dry run instead")
+		}
+	}
+	var backup *autofixBackup
+	if autofixBackupOn && !dryRun {
+		backup = newAutofixBackup(root)
+	}
 	wfPath := filepath.Join(root, autofixWorkflowsPath)
 
 	entries, err := os.ReadDir(wfPath)
 	if err != nil {
 		return fmt.Errorf("read workflows dir: %w", err)
 	}
+	ignorer, err := newWorkflowIgnorer(root, autofixIgnore)
+	if err != nil {
+		return err
+	}
+
+	// Resolved once per action@ref across all files
+	shaCache := map[string]string{}
+	resolveSHA := func(repo, ref string) (string, error) {
+		key := repo + "@" + ref
+		if sha, ok := shaCache[key]; ok {
+			return sha, nil
+		}
+		sha, err := analyzer.ResolveActionSHA(cmd.Context(), gh, repo, ref)
+		if err == nil {
+			shaCache[key] = sha
+		}
+		return sha, err
+	}
+
+	runnerUpgrades := map[string]string{}
+	for from, to := range defaultRunnerUpgrades {
+		runnerUpgrades[from] = to
+	}
+	for from, to := range autofixRunnerMap {
+		runnerUpgrades[from] = to
+	}
 
 	var allPatches []string
-	fixCount := 0
+	var fileChanges []autofixFile
+	var skipped []autofixSkip
+	fixCount, declined := 0, 0
 
 	for _, e := range entries {
 		if e.IsDir() {
@@ -62,82 +205,270 @@ func runRepoAutofix(cmd *cobra.Command, args []string) error {
 			continue
 		}
 		full := filepath.Join(wfPath, name)
+		if ignorer.skip(full) {
+			debugf("Ignoring %s", full)
+			continue
+		}
+		debugf("Checking %s", full)
 		original, err := os.ReadFile(full)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to read %s: %v\n", full, err)
 			continue
 		}
 
-		fixed, changed := applyAutoFixes(string(original), name)
-		if !changed {
+		content := string(original)
+		var changes []autofixChange
+		if autofixNormalizeEnc {
+			content, changes = normalizeWorkflowEncoding(original)
+		}
+		fixed, fixChanges := applyAutoFixes(content, name)
+		changes = append(changes, fixChanges...)
+		if autofixUpgradeRun {
+			var runnerChanges []autofixChange
+			fixed, runnerChanges = upgradeRunners(fixed, runnerUpgrades)
+			changes = append(changes, runnerChanges...)
+		}
+		if autofixPinSHA {
+			var shaChanges []autofixChange
+			fixed, shaChanges = pinActionSHAs(fixed, name, resolveSHA)
+			changes = append(changes, shaChanges...)
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		// The text fallback edits files yaml.v3 cannot parse, so its output is unverified too
+		if err := validateFixedYAML(fixed); err != nil && !autofixCheck && !autofixForce {
+			reason := fmt.Sprintf("fixed content is not valid YAML: %v", err)
+			if yaml.Unmarshal(original, new(any)) != nil {
+				reason += " (the original does not parse either)"
+			}
+			skipped = append(skipped, autofixSkip{File: full, Reason: reason})
+			fmt.Fprintf(os.Stderr, "Skipped %s: %s; rerun with --force to write it anyway\n", name, reason)
 			continue
 		}
+		// Paths relative to the repository root so `git apply` can be run from there
+		rel, err := filepath.Rel(root, full)
+		if err != nil {
+			rel = full
+		}
+		if prompter != nil {
+			apply, quit := prompter.confirm(name, summarizeChanges(changes), generateUnifiedDiff(filepath.ToSlash(rel), string(original), fixed))
+			if quit {
+				infof(os.Stdout, "Stopped; %s and the remaining workflows were left unchanged\n", name)
+				break
+			}
+			if !apply {
+				declined++
+				fmt.Printf("Left unchanged: %s\n", name)
+				continue
+			}
+		}
 
 		fixCount++
-		if autofixDryRun {
-			if !autofixJSON {
-				fmt.Printf("[DRY RUN] Would fix: %s\n", name)
+		if autofixCheck {
+			if !jsonOutput {
+				fmt.Printf("Needs fixing: %s (%s)\n", name, checkReasons(changes))
+			}
+		} else if dryRun {
+			if !jsonOutput {
+				fmt.Printf("[DRY RUN] Would fix: %s (%s)\n", name, summarizeChanges(changes))
 			}
 		} else {
+			if backup != nil {
+				if err := backup.save(full, original); err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to back up %s, leaving it unchanged: %v\n", full, err)
+					continue
+				}
+			}
 			if err := os.WriteFile(full, []byte(fixed), 0o644); err != nil {
 				fmt.Fprintf(os.Stderr, "Failed to write %s: %v\n", full, err)
 				continue
 			}
-			if !autofixJSON {
-				fmt.Printf("Fixed: %s\n", name)
+			if !jsonOutput {
+				fmt.Printf("Fixed: %s (%s)\n", name, summarizeChanges(changes))
 			}
 		}
+		fileChanges = append(fileChanges, autofixFile{File: full, Changes: changes})
 
 		// Generate unified diff for patch
 		if autofixPatchOut != "" {
-			patch := generateUnifiedDiff(name, string(original), fixed)
+			patch := generateUnifiedDiff(filepath.ToSlash(rel), string(original), fixed)
 			allPatches = append(allPatches, patch)
 		}
 	}
 
 	if autofixPatchOut != "" && len(allPatches) > 0 {
-		combined := strings.Join(allPatches, "\n")
+		combined := strings.Join(allPatches, "")
 		if err := os.WriteFile(autofixPatchOut, []byte(combined), 0o644); err != nil {
 			return fmt.Errorf("write patch: %w", err)
 		}
-		if !autofixJSON {
-			fmt.Printf("Wrote patch to %s\n", autofixPatchOut)
+		if !jsonOutput {
+			infof(os.Stdout, "Wrote patch to %s\n", autofixPatchOut)
 		}
 	}
 
-	if autofixJSON {
-		return outputAutofixJSON(fixCount, autofixDryRun, allPatches)
+	if ignorer.skipped > 0 && !jsonOutput {
+		infof(os.Stdout, "Ignored %d workflow files (--ignore/%s)\n", ignorer.skipped, analyzer.IgnoreFileName)
 	}
 
-	if autofixDryRun {
-		fmt.Printf("\nDry run complete. %d files would be modified.\n", fixCount)
-		fmt.Println("Run with --dry-run=false to apply changes.")
+	checkFailed := autofixCheck && fixCount > 0
+	if jsonOutput {
+		backupDir := ""
+		if backup != nil && fixCount > 0 {
+			backupDir = backup.dir
+		}
+		if err := outputAutofixJSON(fixCount, dryRun, fileChanges, skipped, ignorer.skipped, backupDir); err != nil {
+			return err
+		}
+	} else if autofixCheck {
+		if !checkFailed {
+			infof(os.Stdout, "All workflows are compliant; nothing to fix.\n")
+		}
+	} else if dryRun {
+		infof(os.Stdout, "\nDry run complete. %d files would be modified.\nRun with --dry-run=false to apply changes.\n", fixCount)
 	} else {
-		fmt.Printf("\nApplied fixes to %d files.\n", fixCount)
+		infof(os.Stdout, "\nApplied fixes to %d files.\n", fixCount)
+		if declined > 0 {
+			infof(os.Stdout, "Left %d files unchanged at your request.\n", declined)
+		}
+		if backup != nil && fixCount > 0 {
+			infof(os.Stdout, "Originals backed up to %s; undo with repo-autofix --restore\n", backup.dir)
+		}
 	}
 
+	if checkFailed {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d workflow files need fixing; run repo-autofix --dry-run=false to apply", fixCount)
+	}
+	if len(skipped) > 0 && !dryRun {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d workflow files not fixed because the result would not parse as YAML", len(skipped))
+	}
 	return nil
 }
 
 type autofixResult struct {
-	Success       bool     `json:"success"`
-	DryRun        bool     `json:"dry_run"`
-	FilesModified int      `json:"files_modified"`
-	PatchFile     string   `json:"patch_file,omitempty"`
-	Message       string   `json:"message"`
+	Success       bool          `json:"success"`
+	DryRun        bool          `json:"dry_run"`
+	FilesModified int           `json:"files_modified"`
+	PatchFile     string        `json:"patch_file,omitempty"`
+	BackupDir     string        `json:"backup_dir,omitempty"`
+	Message       string        `json:"message"`
+	Files         []autofixFile `json:"files,omitempty"`
+	Skipped       []autofixSkip `json:"skipped,omitempty"`
+	Ignored       int           `json:"ignored,omitempty"`
+}
+
+// autofixSkip is a file whose fixes were not applied, and why
+type autofixSkip struct {
+	File   string `json:"file"`
+	Reason string `json:"reason"`
+}
+
+// autofixFile lists the transformations applied (or proposed) for one workflow file
+type autofixFile struct {
+	File    string          `json:"file"`
+	Changes []autofixChange `json:"changes"`
+}
+
+// autofixChange is a single transformation, e.g. concurrency added or an action pinned from -> to
+type autofixChange struct {
+	Kind   string `json:"kind"`
+	Action string `json:"action,omitempty"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+const (
+	changeConcurrencyAdded = "concurrency-added"
+	changeActionPinned     = "action-pinned"
+	changeBOMRemoved       = "bom-removed"
+	changeCRLFNormalized   = "crlf-normalized"
+	changeSHAPinned        = "sha-pinned"
+	changePermissionsAdded = "permissions-added"
+	changeRunnerUpgraded   = "runner-upgraded"
+)
+
+func (c autofixChange) String() string {
+	switch c.Kind {
+	case changeActionPinned:
+		return fmt.Sprintf("pin %s@%s -> @%s", c.Action, valueOr(c.From, "(none)"), c.To)
+	case changeConcurrencyAdded:
+		if c.To == "cancel-in-progress: false" {
+			return "add concurrency (cancel-in-progress: false)"
+		}
+		return "add concurrency"
+	case changePermissionsAdded:
+		return "add permissions: contents: read"
+	case changeRunnerUpgraded:
+		return fmt.Sprintf("runner %s -> %s", c.From, c.To)
+	case changeBOMRemoved:
+		return "strip BOM"
+	case changeCRLFNormalized:
+		return "CRLF -> LF"
+	case changeSHAPinned:
+		return fmt.Sprintf("pin %s@%s -> %s", c.Action, c.From, c.To)
+	}
+	return c.Kind
+}
+
+// Reason describes what is wrong with the file before the change, for --check
+func (c autofixChange) Reason() string {
+	switch c.Kind {
+	case changeActionPinned:
+		return fmt.Sprintf("unpinned action %s@%s", c.Action, valueOr(c.From, "(none)"))
+	case changeConcurrencyAdded:
+		return "missing concurrency"
+	case changePermissionsAdded:
+		return "missing token permissions"
+	case changeRunnerUpgraded:
+		return fmt.Sprintf("deprecated runner %s", c.From)
+	case changeBOMRemoved:
+		return "UTF-8 BOM"
+	case changeCRLFNormalized:
+		return "CRLF line endings"
+	case changeSHAPinned:
+		return fmt.Sprintf("action %s@%s not pinned to a commit SHA", c.Action, c.From)
+	}
+	return c.Kind
 }
 
-func outputAutofixJSON(fixCount int, dryRun bool, patches []string) error {
+func checkReasons(changes []autofixChange) string {
+	var parts []string
+	for _, c := range changes {
+		parts = append(parts, c.Reason())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// summarizeChanges renders a concise one-line description for human output
+func summarizeChanges(changes []autofixChange) string {
+	var parts []string
+	for _, c := range changes {
+		parts = append(parts, c.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+func outputAutofixJSON(fixCount int, dryRun bool, files []autofixFile, skipped []autofixSkip, ignored int, backupDir string) error {
 	result := autofixResult{
 		Success:       true,
 		DryRun:        dryRun,
 		FilesModified: fixCount,
 		PatchFile:     autofixPatchOut,
+		BackupDir:     backupDir,
+		Files:         files,
+		Skipped:       skipped,
+		Ignored:       ignored,
 	}
 
-	if dryRun {
+	if autofixCheck {
+		result.Success = fixCount == 0
+		result.Message = fmt.Sprintf("Check complete. %d files need fixing.", fixCount)
+	} else if dryRun {
 		result.Message = fmt.Sprintf("Dry run complete. %d files would be modified.", fixCount)
 	} else {
+		result.Success = len(skipped) == 0
 		result.Message = fmt.Sprintf("Applied fixes to %d files.", fixCount)
 	}
 
@@ -146,51 +477,111 @@ func outputAutofixJSON(fixCount int, dryRun bool, patches []string) error {
 	return encoder.Encode(result)
 }
 
-// applyAutoFixes attempts to add concurrency and pin common actions
-func applyAutoFixes(content, filename string) (string, bool) {
-	changed := false
+// normalizeWorkflowEncoding strips a UTF-8 BOM and converts CRLF to LF before the other fixes run
+func normalizeWorkflowEncoding(raw []byte) (string, []autofixChange) {
+	bom, crlf := analyzer.EncodingIssues(raw)
+	var changes []autofixChange
+	if bom {
+		changes = append(changes, autofixChange{Kind: changeBOMRemoved})
+	}
+	if crlf > 0 {
+		changes = append(changes, autofixChange{Kind: changeCRLFNormalized})
+	}
+	return string(analyzer.NormalizeEncoding(raw)), changes
+}
+
+// validateFixedYAML re-parses fixed workflow content before it is written
+func validateFixedYAML(content string) error {
+	var doc any
+	return yaml.Unmarshal([]byte(content), &doc)
+}
+
+// applyAutoFixes attempts to add concurrency (and, with --add-permissions, token permissions) and
+// pin common actions, returning the applied changes
+func applyAutoFixes(content, filename string) (string, []autofixChange) {
+	var changes []autofixChange
 	result := content
 
 	// Parse YAML
 	var doc map[string]any
 	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
 		// Fallback to text mode
-		result, changed = applyTextFixes(content)
-		return result, changed
+		return applyTextFixes(content, filename)
 	}
 
 	// Add concurrency if missing
-	if !hasConcurrency(doc) {
-		result, changed = addConcurrencyBlock(result)
+	if !analyzer.HasConcurrency(doc) {
+		cancel := cancelInProgress(analyzer.IsDeployWorkflow(doc, filename))
+		var added bool
+		if result, added = addConcurrencyBlock(result, cancel); added {
+			changes = append(changes, concurrencyChange(cancel))
+		}
+	}
+
+	// Add least-privilege permissions if opted in and none are declared
+	if has, _ := analyzer.DeclaresPermissions(doc); autofixAddPerms && !has {
+		var added bool
+		if result, added = addPermissionsBlock(result); added {
+			changes = append(changes, autofixChange{Kind: changePermissionsAdded})
+		}
 	}
 
 	// Pin common actions
-	pinned, wasChanged := pinCommonActions(result)
-	if wasChanged {
-		result = pinned
-		changed = true
+	result, pinChanges := pinCommonActions(result)
+	changes = append(changes, pinChanges...)
+
+	return result, changes
+}
+
+// addConcurrencyBlock inserts a concurrency block at the top level (see insertTopLevelBlock)
+func addConcurrencyBlock(content string, cancel bool) (string, bool) {
+	return insertTopLevelBlock(content, []string{
+		"concurrency:",
+		"  group: " + autofixConcGroup,
+		"  cancel-in-progress: " + strconv.FormatBool(cancel),
+	})
+}
+
+// cancelInProgress resolves --cancel-in-progress for one workflow: an explicit true/false wins,
+// otherwise deployments keep running (false) and everything else is cancelled (true)
+func cancelInProgress(deploy bool) bool {
+	if v, err := strconv.ParseBool(autofixCancel); err == nil {
+		return v
 	}
+	return !deploy
+}
 
-	return result, changed
+func concurrencyChange(cancel bool) autofixChange {
+	return autofixChange{Kind: changeConcurrencyAdded, To: "cancel-in-progress: " + strconv.FormatBool(cancel)}
 }
 
-// addConcurrencyBlock inserts concurrency after 'name:' or before 'on:'
-func addConcurrencyBlock(content string) (string, bool) {
+// addPermissionsBlock inserts read-only contents permissions at the top level (see insertTopLevelBlock)
+func addPermissionsBlock(content string) (string, bool) {
+	return insertTopLevelBlock(content, []string{
+		"permissions:",
+		"  contents: read",
+	})
+}
+
+// insertTopLevelBlock inserts block after the document-level 'name:' or before the document-level
+// 'on:'. Only column-0 keys count, so job and step names are never matched.
+func insertTopLevelBlock(content string, block []string) (string, bool) {
 	lines := strings.Split(content, "\n")
 	insertIdx := -1
 
-	// Find insertion point after 'name:' or before 'on:'
 	for i, line := range lines {
-		trim := strings.TrimSpace(line)
-		if strings.HasPrefix(trim, "name:") {
+		if isTopLevelKey(line, "name") {
+			// Skip the value's continuation lines (e.g. a `name: >` block scalar)
 			insertIdx = i + 1
+			for insertIdx < len(lines) && (strings.HasPrefix(lines[insertIdx], " ") || strings.HasPrefix(lines[insertIdx], "\t")) {
+				insertIdx++
+			}
 			break
 		}
 	}
 	if insertIdx == -1 {
 		for i, line := range lines {
-			trim := strings.TrimSpace(line)
-			if strings.HasPrefix(trim, "on:") {
+			if isTopLevelKey(line, "on") {
 				insertIdx = i
 				break
 			}
@@ -201,100 +592,175 @@ func addConcurrencyBlock(content string) (string, bool) {
 		return content, false
 	}
 
-	concurrencyBlock := []string{
-		"",
-		"concurrency:",
-		"  group: ${{ github.workflow }}-${{ github.ref }}",
-		"  cancel-in-progress: true",
+	if insertIdx != 0 {
+		// Separate from the preceding key; no leading blank line when the block opens the file
+		block = append([]string{""}, block...)
 	}
 
-	result := append(lines[:insertIdx], append(concurrencyBlock, lines[insertIdx:]...)...)
+	result := append(lines[:insertIdx], append(block, lines[insertIdx:]...)...)
 	return strings.Join(result, "\n"), true
 }
 
-// pinCommonActions pins unpinned actions to known stable versions
-func pinCommonActions(content string) (string, bool) {
-	pins := map[string]string{
-		"actions/checkout":      "v4",
-		"actions/setup-go":      "v5",
-		"actions/setup-node":    "v4",
-		"actions/setup-python":  "v5",
-		"actions/cache":         "v4",
-		"actions/upload-artifact": "v4",
-		"actions/download-artifact": "v4",
-		"docker/setup-buildx-action": "v3",
-		"docker/login-action": "v3",
-		"docker/build-push-action": "v5",
+// isTopLevelKey reports whether line starts the document-level mapping key (plain or quoted)
+func isTopLevelKey(line, key string) bool {
+	for _, k := range []string{key, `"` + key + `"`, `'` + key + `'`} {
+		if strings.HasPrefix(line, k+":") {
+			return true
+		}
 	}
+	return false
+}
+
+// pinCommonActions pins unpinned actions to the versions repo-defrag recommends
+// (analyzer.RecommendedActionVersions), so they match its deprecated-action upgrade hints
+func pinCommonActions(content string) (string, []autofixChange) {
+	pins := analyzer.RecommendedActionVersions
 
 	result := content
-	changed := false
+	var changes []autofixChange
+
+	actions := make([]string, 0, len(pins))
+	for action := range pins {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
 
-	for action, version := range pins {
+	for _, action := range actions {
+		version := pins[action]
 		// Match uses: action@main or uses: action (no @)
 		reUnpinned := regexp.MustCompile(`(\s+uses:\s+` + regexp.QuoteMeta(action) + `)(@(main|master|HEAD|latest))?(\s|$)`)
-		if reUnpinned.MatchString(result) {
-			result = reUnpinned.ReplaceAllString(result, "${1}@"+version+"${4}")
-			changed = true
+		for _, m := range reUnpinned.FindAllStringSubmatch(result, -1) {
+			changes = append(changes, autofixChange{Kind: changeActionPinned, Action: action, From: m[3], To: version})
 		}
+		result = reUnpinned.ReplaceAllString(result, "${1}@"+version+"${4}")
 	}
 
-	return result, changed
+	return result, changes
 }
 
-// applyTextFixes for when YAML parsing fails
-func applyTextFixes(content string) (string, bool) {
-	result := content
-	changed := false
+var (
+	// reUsesRef matches `uses: owner/repo[/path]@ref` lines, capturing the prefix, action, ref and any comment
+	reUsesRef = regexp.MustCompile(`(?m)^(\s*(?:-\s+)?uses:\s*)(["']?)([A-Za-z0-9_.\-]+/[A-Za-z0-9_.\-]+(?:/[^@\s"']*)?)@([^\s"'#]+)(["']?)([ \t]+#.*)?$`)
+	reFullSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+)
 
-	// Add concurrency if missing
-	if !detectConcurrencyFallback(content) {
-		result, changed = addConcurrencyBlock(result)
-	}
+// pinActionSHAs rewrites `uses: owner/repo@tag` to `uses: owner/repo@<sha> # tag`. Local (./) and
+// docker:// actions and refs that are already SHAs are left alone, as are actions whose ref cannot
+// be resolved (with a warning). An existing trailing comment is kept after the tag.
+func pinActionSHAs(content, filename string, resolve func(repo, ref string) (string, error)) (string, []autofixChange) {
+	var changes []autofixChange
+	result := reUsesRef.ReplaceAllStringFunc(content, func(line string) string {
+		m := reUsesRef.FindStringSubmatch(line)
+		prefix, quote, action, ref, endQuote, comment := m[1], m[2], m[3], m[4], m[5], m[6]
+		if strings.HasPrefix(action, "./") || strings.HasPrefix(action, "docker://") || reFullSHA.MatchString(ref) {
+			return line
+		}
+		parts := strings.SplitN(action, "/", 3)
+		sha, err := resolve(parts[0]+"/"+parts[1], ref)
+		if err != nil {
+			warnf("%s: not pinning %s@%s: %v", filename, action, ref, err)
+			return line
+		}
+		changes = append(changes, autofixChange{Kind: changeSHAPinned, Action: action, From: ref, To: sha})
+		return prefix + quote + action + "@" + sha + endQuote + " # " + ref + comment
+	})
+	return result, changes
+}
 
-	// Pin actions
-	pinned, wasChanged := pinCommonActions(result)
-	if wasChanged {
-		result = pinned
-		changed = true
+var (
+	// reRunsOn matches a runs-on line, capturing the prefix and its (possibly empty) value
+	reRunsOn = regexp.MustCompile(`^(\s*(?:-\s+)?runs-on:[ \t]*)(.*)$`)
+	// reRunnerLabel matches one label of a runs-on value or list item, optionally quoted
+	reRunnerLabel = regexp.MustCompile(`(["']?)([A-Za-z0-9_.\-]+)(["']?)`)
+)
+
+// upgradeRunners rewrites deprecated labels in runs-on scalars, flow lists ([a, b]) and block lists.
+// Values containing self-hosted (custom runner labels) and expressions are left unchanged.
+func upgradeRunners(content string, upgrades map[string]string) (string, []autofixChange) {
+	var changes []autofixChange
+	rewrite := func(value string) string {
+		if strings.Contains(value, "self-hosted") || strings.Contains(value, "${{") {
+			return value
+		}
+		code, comment := value, ""
+		if i := strings.Index(value, "#"); i >= 0 {
+			code, comment = value[:i], value[i:]
+		}
+		code = reRunnerLabel.ReplaceAllStringFunc(code, func(tok string) string {
+			m := reRunnerLabel.FindStringSubmatch(tok)
+			to, ok := upgrades[m[2]]
+			if !ok {
+				return tok
+			}
+			changes = append(changes, autofixChange{Kind: changeRunnerUpgraded, From: m[2], To: to})
+			return m[1] + to + m[3]
+		})
+		return code + comment
 	}
 
-	return result, changed
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		m := reRunsOn.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		if value := strings.TrimSpace(m[2]); value != "" && !strings.HasPrefix(value, "#") {
+			lines[i] = m[1] + rewrite(m[2])
+			continue
+		}
+		// Block list: the following more-indented "- label" lines
+		indent := len(lines[i]) - len(strings.TrimLeft(lines[i], " \t-"))
+		end := i + 1
+		var items []int
+		selfHosted := false
+		for ; end < len(lines); end++ {
+			trim := strings.TrimSpace(lines[end])
+			if trim == "" {
+				continue
+			}
+			if len(lines[end])-len(strings.TrimLeft(lines[end], " \t")) <= indent || !strings.HasPrefix(trim, "- ") {
+				break
+			}
+			items = append(items, end)
+			selfHosted = selfHosted || strings.Contains(trim, "self-hosted")
+		}
+		if !selfHosted {
+			for _, j := range items {
+				dash := strings.Index(lines[j], "- ")
+				lines[j] = lines[j][:dash+2] + rewrite(lines[j][dash+2:])
+			}
+		}
+		i = end - 1
+	}
+	return strings.Join(lines, "\n"), changes
 }
 
-// generateUnifiedDiff creates a unified diff format patch
-func generateUnifiedDiff(filename, original, fixed string) string {
-	var buf bytes.Buffer
-	fmt.Fprintf(&buf, "--- a/%s\n", filename)
-	fmt.Fprintf(&buf, "+++ b/%s\n", filename)
-
-	origLines := strings.Split(original, "\n")
-	fixedLines := strings.Split(fixed, "\n")
+// applyTextFixes for when YAML parsing fails
+func applyTextFixes(content, filename string) (string, []autofixChange) {
+	var changes []autofixChange
+	result := content
 
-	// Simple line-by-line diff
-	maxLen := len(origLines)
-	if len(fixedLines) > maxLen {
-		maxLen = len(fixedLines)
+	// Add concurrency if missing
+	if !analyzer.DetectConcurrencyFallback(content) {
+		deploy := reEnvironmentKey.MatchString(content) || strings.Contains(strings.ToLower(filename), "deploy")
+		cancel := cancelInProgress(deploy)
+		var added bool
+		if result, added = addConcurrencyBlock(result, cancel); added {
+			changes = append(changes, concurrencyChange(cancel))
+		}
 	}
 
-	fmt.Fprintf(&buf, "@@ -1,%d +1,%d @@\n", len(origLines), len(fixedLines))
-
-	i := 0
-	for i < maxLen {
-		if i < len(origLines) && i < len(fixedLines) {
-			if origLines[i] != fixedLines[i] {
-				fmt.Fprintf(&buf, "-%s\n", origLines[i])
-				fmt.Fprintf(&buf, "+%s\n", fixedLines[i])
-			} else {
-				fmt.Fprintf(&buf, " %s\n", origLines[i])
-			}
-		} else if i < len(origLines) {
-			fmt.Fprintf(&buf, "-%s\n", origLines[i])
-		} else if i < len(fixedLines) {
-			fmt.Fprintf(&buf, "+%s\n", fixedLines[i])
+	if has, _ := analyzer.DetectPermissionsFallback(content); autofixAddPerms && !has {
+		var added bool
+		if result, added = addPermissionsBlock(result); added {
+			changes = append(changes, autofixChange{Kind: changePermissionsAdded})
 		}
-		i++
 	}
 
-	return buf.String()
+	// Pin actions
+	result, pinChanges := pinCommonActions(result)
+	changes = append(changes, pinChanges...)
+
+	return result, changes
 }
+
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// autofixBackupDir holds one timestamped directory of original workflow files per --backup run,
+// under the repository root. It is hidden, so workflow and action scans never descend into it.
+const autofixBackupDir = ".rrctl-backups"
+
+// autofixBackup copies files into a backup set before repo-autofix overwrites them
+type autofixBackup struct {
+	root string
+	dir  string
+}
+
+func newAutofixBackup(root string) *autofixBackup {
+	return &autofixBackup{root: root, dir: filepath.Join(root, autofixBackupDir, time.Now().UTC().Format("20060102T150405Z"))}
+}
+
+// save writes original to the backup set at full's path relative to the repository root
+func (b *autofixBackup) save(full string, original []byte) error {
+	rel, err := filepath.Rel(b.root, full)
+	if err != nil {
+		return err
+	}
+	dst := filepath.Join(b.dir, rel)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, original, 0o644)
+}
+
+// restoreLatestBackup copies every file of the most recent backup set back into root and returns
+// the set's directory and the files restored
+func restoreLatestBackup(root string) (string, []string, error) {
+	base := filepath.Join(root, autofixBackupDir)
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		return "", nil, fmt.Errorf("no backups to restore: %w", err)
+	}
+	var sets []string
+	for _, e := range entries {
+		if e.IsDir() {
+			sets = append(sets, e.Name())
+		}
+	}
+	if len(sets) == 0 {
+		return "", nil, fmt.Errorf("no backups to restore in %s", base)
+	}
+	// Timestamps sort chronologically
+	sort.Strings(sets)
+	dir := filepath.Join(base, sets[len(sets)-1])
+
+	var restored []string
+	err = filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(root, rel)
+		if err := os.WriteFile(dst, b, 0o644); err != nil {
+			return fmt.Errorf("restore %s: %w", dst, err)
+		}
+		restored = append(restored, dst)
+		return nil
+	})
+	return dir, restored, err
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change, as in `diff -u`
+const diffContext = 3
+
+// diffOp is one line of an edit script: ' ' kept, '-' deleted from a, '+' inserted from b
+type diffOp struct {
+	kind byte
+	a, b int // line indexes in a and b (the side that does not apply is left as the insertion point)
+}
+
+// generateUnifiedDiff creates a unified diff of original -> fixed that `git apply` accepts.
+// filename should be the path relative to the repository root.
+func generateUnifiedDiff(filename, original, fixed string) string {
+	a, aNL := splitDiffLines(original)
+	b, bNL := splitDiffLines(fixed)
+	ops := myersDiff(diffKeys(a, aNL), diffKeys(b, bNL))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- a/%s\n", filename)
+	fmt.Fprintf(&buf, "+++ b/%s\n", filename)
+	for _, h := range diffHunks(ops) {
+		hunk := ops[h[0]:h[1]]
+		aStart, bStart := hunk[0].a, hunk[0].b
+		var aLen, bLen int
+		for _, op := range hunk {
+			if op.kind != '+' {
+				aLen++
+			}
+			if op.kind != '-' {
+				bLen++
+			}
+		}
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n", hunkRange(aStart, aLen), hunkRange(bStart, bLen))
+		for _, op := range hunk {
+			var line string
+			var last, nl bool
+			if op.kind == '+' {
+				line, last, nl = b[op.b], op.b == len(b)-1, bNL
+			} else {
+				line, last, nl = a[op.a], op.a == len(a)-1, aNL
+			}
+			fmt.Fprintf(&buf, "%c%s\n", op.kind, line)
+			if last && !nl {
+				buf.WriteString("\\ No newline at end of file\n")
+			}
+		}
+	}
+	return buf.String()
+}
+
+// splitDiffLines splits s into lines and reports whether it ends with a newline
+func splitDiffLines(s string) ([]string, bool) {
+	if s == "" {
+		return nil, true
+	}
+	nl := strings.HasSuffix(s, "\n")
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n"), nl
+}
+
+// diffKeys returns the lines to compare; a final line without a newline gets a marker so it
+// differs from the same text with one, and the diff rewrites it
+func diffKeys(lines []string, nl bool) []string {
+	if nl || len(lines) == 0 {
+		return lines
+	}
+	keys := append([]string(nil), lines...)
+	keys[len(keys)-1] += "\x00no-newline"
+	return keys
+}
+
+// hunkRange formats a unified diff range; empty ranges point at the line before the change
+func hunkRange(start, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if n == 1 {
+		return fmt.Sprint(start + 1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, n)
+}
+
+// diffHunks groups ops into [start, end) ranges around changes with diffContext lines of context,
+// merging changes whose context would overlap
+func diffHunks(ops []diffOp) [][2]int {
+	var hunks [][2]int
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == ' ' {
+			continue
+		}
+		start := max(i-diffContext, 0)
+		end := i
+		// Extend through changes separated by at most 2*diffContext kept lines
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*diffContext {
+				end = min(end+diffContext, len(ops))
+				break
+			}
+			end = run
+		}
+		if n := len(hunks); n > 0 && start <= hunks[n-1][1] {
+			hunks[n-1][1] = end
+		} else {
+			hunks = append(hunks, [2]int{start, end})
+		}
+		i = end - 1
+	}
+	return hunks
+}
+
+// myersDiff returns a shortest edit script from a to b (Myers' O(ND) algorithm)
+func myersDiff(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	maxD := n + m
+	offset := maxD
+	v := make([]int, 2*maxD+2)
+	var trace [][]int
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrackDiff(trace, a, b, offset, d)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrackDiff walks the saved V arrays back from (len(a), len(b)) to recover the edit script
+func backtrackDiff(trace [][]int, a, b []string, offset, d int) []diffOp {
+	x, y := len(a), len(b)
+	var ops []diffOp
+	for ; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			ops = append(ops, diffOp{' ', x, y})
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, diffOp{'+', x, prevY})
+			} else {
+				ops = append(ops, diffOp{'-', prevX, y})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestUnifiedDiffApplies checks that git accepts the generated patch against the original file
+// and that applying it produces the fixed content
+func TestUnifiedDiffApplies(t *testing.T) {
+	git, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not installed")
+	}
+	cases := map[string]struct{ original, fixed string }{
+		"insert mid-file": {
+			original: "name: ci\non: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v2\n      - run: make\n      - run: make test\n      - run: make lint\n      - run: make dist\n",
+			fixed:    "name: ci\n\nconcurrency:\n  group: ${{ github.workflow }}-${{ github.ref }}\n  cancel-in-progress: true\non: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n      - run: make\n      - run: make test\n      - run: make lint\n      - run: make dist\n",
+		},
+		"separate hunks": {
+			original: "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n",
+			fixed:    "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn\n",
+		},
+		"no newline at end": {
+			original: "on: push\njobs:\n  a:\n    runs-on: ubuntu-22.04",
+			fixed:    "on: push\njobs:\n  a:\n    runs-on: ubuntu-24.04",
+		},
+		"add newline at end": {
+			original: "on: push\njobs: {}",
+			fixed:    "on: push\npermissions:\n  contents: read\njobs: {}\n",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			file := filepath.Join(".github", "workflows", "ci.yml")
+			if err := os.MkdirAll(filepath.Join(dir, ".github", "workflows"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, file), []byte(c.original), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			patch := filepath.Join(dir, "fix.patch")
+			diff := generateUnifiedDiff(filepath.ToSlash(file), c.original, c.fixed)
+			if err := os.WriteFile(patch, []byte(diff), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			for _, args := range [][]string{{"apply", "--check", patch}, {"apply", patch}} {
+				cmd := exec.Command(git, args...)
+				cmd.Dir = dir
+				if out, err := cmd.CombinedOutput(); err != nil {
+					t.Fatalf("git %v: %v\n%s\npatch:\n%s", args, err, out, diff)
+				}
+			}
+			got, err := os.ReadFile(filepath.Join(dir, file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != c.fixed {
+				t.Errorf("applied patch gives\n%q\nwant\n%q", got, c.fixed)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// autofixPrompter asks, file by file, whether to write the proposed fixes (repo-autofix
+// --interactive). Answering [a]ll accepts the current file and every remaining one.
+type autofixPrompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	all bool
+}
+
+// newAutofixPrompter returns a prompter on stdin/stdout, or nil when either is not a terminal:
+// nobody could answer, so the run falls back to a dry run
+func newAutofixPrompter() *autofixPrompter {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return nil
+	}
+	return &autofixPrompter{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+}
+
+// confirm shows the diff for one file and reads the answer: apply reports whether to write the
+// file, quit whether to stop before the remaining files. End of input counts as quit.
+func (p *autofixPrompter) confirm(name, summary, diff string) (apply, quit bool) {
+	if p.all {
+		return true, false
+	}
+	fmt.Fprintf(p.out, "\n%s (%s)\n%s", colorize(ansiBold, name), summary, colorDiff(diff))
+	for {
+		fmt.Fprintf(p.out, "Apply these fixes to %s? [y]es/[n]o/[a]ll/[q]uit: ", name)
+		line, err := p.in.ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "y", "yes":
+			return true, false
+		case "n", "no":
+			return false, false
+		case "a", "all":
+			p.all = true
+			return true, false
+		case "q", "quit":
+			return false, true
+		}
+		if err != nil {
+			fmt.Fprintln(p.out)
+			return false, true
+		}
+	}
+}
+
+// colorDiff colors the added and removed lines of a unified diff for the terminal
+func colorDiff(diff string) string {
+	if !useColor() {
+		return diff
+	}
+	lines := strings.SplitAfter(diff, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+			lines[i] = colorize(ansiBold, strings.TrimSuffix(line, "\n")) + "\n"
+		case strings.HasPrefix(line, "+"):
+			lines[i] = colorize(ansiGreen, strings.TrimSuffix(line, "\n")) + "\n"
+		case strings.HasPrefix(line, "-"):
+			lines[i] = colorize(ansiRed, strings.TrimSuffix(line, "\n")) + "\n"
+		}
+	}
+	return strings.Join(lines, "")
+}
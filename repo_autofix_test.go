@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// runRRCTL runs rrctl with args, every flag of the command first reset to its default, and
+// returns what it printed to stdout and stderr
+func runRRCTL(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd, _, err := rootCmd.Find(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reset := func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			var def []string
+			if d := strings.Trim(f.DefValue, "[]"); d != "" {
+				def = strings.Split(d, ",")
+			}
+			_ = sv.Replace(def)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	rootCmd.PersistentFlags().VisitAll(reset)
+
+	out, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = out, out
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+	rootCmd.SetArgs(args)
+	runErr := rootCmd.ExecuteContext(context.Background())
+	printed, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(printed), runErr
+}
+
+func TestAddConcurrencyBlockPlacement(t *testing.T) {
+	block := "concurrency:\n  group: ${{ github.workflow }}-${{ github.ref }}\n  cancel-in-progress: true\n"
+	cases := map[string]struct{ in, want string }{
+		"step name before top-level name": {
+			in:   "jobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - name: Build\n        run: make\nname: ci\non: push\n",
+			want: "jobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - name: Build\n        run: make\nname: ci\n\n" + block + "on: push\n",
+		},
+		"no top-level name": {
+			in:   "on: push\njobs:\n  build:\n    name: Build job\n    runs-on: ubuntu-latest\n    steps:\n      - name: Build\n        run: make\n",
+			want: block + "on: push\njobs:\n  build:\n    name: Build job\n    runs-on: ubuntu-latest\n    steps:\n      - name: Build\n        run: make\n",
+		},
+		"no top-level name, on after jobs": {
+			in:   "jobs:\n  build:\n    steps:\n      - name: Build\n        run: make\non: push\n",
+			want: "jobs:\n  build:\n    steps:\n      - name: Build\n        run: make\n\n" + block + "on: push\n",
+		},
+		"name after jobs": {
+			in:   "on: push\njobs:\n  build:\n    steps:\n      - name: Build\n        run: make\nname: ci",
+			want: "on: push\njobs:\n  build:\n    steps:\n      - name: Build\n        run: make\nname: ci\n\n" + strings.TrimSuffix(block, "\n"),
+		},
+		"block scalar name": {
+			in:   "name: >\n  Long\n  name\non: push\njobs: {}\n",
+			want: "name: >\n  Long\n  name\n\n" + block + "on: push\njobs: {}\n",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, added := addConcurrencyBlock(c.in, true)
+			if !added {
+				t.Fatal("concurrency block not added")
+			}
+			if got != c.want {
+				t.Errorf("got\n%s\nwant\n%s", got, c.want)
+			}
+			var doc map[string]any
+			if err := yaml.Unmarshal([]byte(got), &doc); err != nil {
+				t.Fatalf("result does not parse: %v", err)
+			}
+			if _, ok := doc["concurrency"]; !ok {
+				t.Errorf("concurrency is not a top-level key: %v", doc)
+			}
+		})
+	}
+}
+
+func TestAddConcurrencyBlockNoAnchor(t *testing.T) {
+	in := "jobs:\n  build:\n    steps:\n      - name: Build\n"
+	if got, added := addConcurrencyBlock(in, true); added || got != in {
+		t.Errorf("without a top-level name: or on: nothing should be inserted, got\n%s", got)
+	}
+}
+
+// TestAutofixSkipsInvalidResult checks that a workflow the text fallback cannot fix into valid YAML
+// (a duplicated on: key) is reported and left alone, and written anyway with --force
+func TestAutofixSkipsInvalidResult(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, ".github", "workflows")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "ci.yml")
+	original := "name: ci\non: push\non: pull_request\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n"
+	if err := os.WriteFile(file, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runRRCTL(t, "repo-autofix", "--path", root, "--dry-run=false")
+	if err == nil || !strings.Contains(err.Error(), "would not parse as YAML") {
+		t.Fatalf("expected a not-parseable error, got %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Skipped ci.yml") || !strings.Contains(out, "--force") {
+		t.Errorf("expected the file to be reported as skipped, got:\n%s", out)
+	}
+	if got, _ := os.ReadFile(file); string(got) != original {
+		t.Errorf("skipped file was modified:\n%s", got)
+	}
+
+	out, err = runRRCTL(t, "repo-autofix", "--path", root, "--dry-run=false", "--force")
+	if err != nil {
+		t.Fatalf("--force: %v\n%s", err, out)
+	}
+	got, _ := os.ReadFile(file)
+	if !strings.Contains(string(got), "concurrency:") {
+		t.Errorf("--force should write the fixed file, got:\n%s", got)
+	}
+}
@@ -6,95 +6,62 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"strings"
+	"text/tabwriter"
 	"time"
 
+	"github.com/kushin77/rrctl/analyzer"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
-// RepoDefragReport is the top-level report structure
-type RepoDefragReport struct {
-	GeneratedAt   time.Time           `json:"generatedAt"`
-	RootPath      string              `json:"rootPath"`
-	WorkflowsPath string              `json:"workflowsPath"`
-	StaleDays     int                 `json:"staleDays"`
-	Workflows     []WorkflowReport    `json:"workflows"`
-	GitHub        *GitHubReport       `json:"github,omitempty"`
-	Summary       RepoDefragSummaries `json:"summary"`
-}
-
-// RepoDefragSummaries aggregates quick stats
-type RepoDefragSummaries struct {
-	WorkflowCount               int `json:"workflowCount"`
-	WorkflowsStale              int `json:"workflowsStale"`
-	WorkflowsWithUnpinned       int `json:"workflowsWithUnpinned"`
-	WorkflowsWithoutConcurrency int `json:"workflowsWithoutConcurrency"`
-}
-
-type WorkflowReport struct {
-	File               string     `json:"file"`
-	Name               string     `json:"name"`
-	Triggers           []string   `json:"triggers"`
-	Schedules          []string   `json:"schedules"`
-	Runners            []string   `json:"runners"`
-	HasConcurrency     bool       `json:"hasConcurrency"`
-	UsesUnpinnedAction bool       `json:"usesUnpinnedAction"`
-	UnpinnedDetails    []string   `json:"unpinnedDetails"`
-	DeprecatedHints    []string   `json:"deprecatedHints"`
-	LastModified       *time.Time `json:"lastModified,omitempty"`
-	Recommendations    []string   `json:"recommendations"`
-}
-
-type GitHubReport struct {
-	Owner           string             `json:"owner"`
-	Repo            string             `json:"repo"`
-	WorkflowFailure []WorkflowFailure  `json:"workflowFailureRates,omitempty"`
-	PRs             []PRReport         `json:"pullRequests,omitempty"`
-	Environments    []EnvironmentProbe `json:"environments,omitempty"`
-}
-
-type WorkflowFailure struct {
-	Name          string  `json:"name"`
-	WorkflowID    int64   `json:"workflowId"`
-	SampledRuns   int     `json:"sampledRuns"`
-	FailureRate   float64 `json:"failureRate"`
-	RecentFailure bool    `json:"recentFailure"`
-}
-
-type PRReport struct {
-	Number    int       `json:"number"`
-	Title     string    `json:"title"`
-	Author    string    `json:"author"`
-	Draft     bool      `json:"draft"`
-	UpdatedAt time.Time `json:"updatedAt"`
-	Stale     bool      `json:"stale"`
-	HeadSHA   string    `json:"headSha"`
-}
-
-type EnvironmentProbe struct {
-	Name         string     `json:"name"`
-	LastDeployed *time.Time `json:"lastDeployed,omitempty"`
-	IsStale      bool       `json:"isStale"`
-}
-
 var (
 	defragPath          string
-	defragWorkflowsPath string
+	defragWorkflowsPath []string
+	defragRecursive     bool
+	defragOutputDir     string
+	defragIgnore        []string
 	defragDaysStale     int
 	ghOwner             string
 	ghRepo              string
 	ghToken             string
+	ghTokenFile         string
 	ghSampleRuns        int
+	ghCacheDir          string
+	ghMaxPRs            int
+	ghRetries           int
+	ghTimeout           time.Duration
+	ghAPIURL            string
 	jsonOut             string
 	mdOut               string
+	htmlOut             string
+	sarifOut            string
+	junitOut            string
 	planOut             string
+	jobGraphOut         string
+	callGraphOut        string
+	callGraphFormat     string
+	promOut             string
+	defragOverrides     []string
+	defragMinSeverity   string
+	defragFailOn        string
+	defragFormat        string
+	defragNoGit         bool
+	defragTable         bool
+	defragBaselinePath  string
+	defragDeltaPath     string
+	defragStrict        bool
+	defragInputsKB      string
+	defragTrusted       []string
+	defragSort          string
+	defragFailFast      bool
+	defragConcurrency   int
+	defragMatrixMax     int
+	defragConcScope     string
+	defragProvider      string
+	defragSince         string
 )
 
 var repoDefragCmd = &cobra.Command{
@@ -103,722 +70,796 @@ var repoDefragCmd = &cobra.Command{
 	Long: `Repo defragmentation scanner.
 - Scans .github/workflows locally for staleness, unpinned actions, deprecated runners, and consolidation hints
 - Optionally queries GitHub API for workflow run failure rates, stale PRs, and stale environments (requires --github flags)
-- Produces JSON and Markdown reports`,
+- With --provider gitlab, analyzes .gitlab-ci.yml instead (unguarded jobs, floating image tags, interruptible, stale schedules)
+- With --provider azure, analyzes azure-pipelines.yml instead (retired vmImages, unpinned task versions, unscoped trigger/pr)
+- Produces JSON, Markdown and HTML reports`,
 	RunE: runRepoDefrag,
 }
 
 func init() {
 	rootCmd.AddCommand(repoDefragCmd)
 
-	repoDefragCmd.Flags().StringVarP(&defragPath, "path", "p", ".", "Root path of the repository")
-	repoDefragCmd.Flags().StringVar(&defragWorkflowsPath, "workflows", ".github/workflows", "Relative path to workflows directory")
+	repoDefragCmd.PersistentFlags().StringVarP(&defragPath, "path", "p", ".", "Root path of the repository")
+	repoDefragCmd.PersistentFlags().StringSliceVar(&defragWorkflowsPath, "workflows", []string{".github/workflows"}, "Relative path to a workflows directory (comma-separated or repeatable to scan several)")
+	repoDefragCmd.PersistentFlags().StringArrayVar(&defragIgnore, "ignore", nil, "Skip workflow files matching this glob, relative to the root (repeatable; also read from .rrctlignore), e.g. 'generated-*.yml' or 'vendor/**'")
+	repoDefragCmd.PersistentFlags().BoolVar(&defragRecursive, "recursive", false, "Also scan every */.github/workflows directory beneath the root (monorepos); replaces the default --workflows unless it is given")
 	repoDefragCmd.Flags().IntVar(&defragDaysStale, "days-stale", 60, "Days without change considered stale for workflows/PRs/environments")
+	repoDefragCmd.Flags().StringVar(&defragProvider, "provider", "github", "CI provider to analyze: github (.github/workflows and local actions), gitlab (.gitlab-ci.yml at the repository root) or azure (azure-pipelines.yml at the repository root)")
+	repoDefragCmd.Flags().IntVar(&defragConcurrency, "concurrency", 0, "Workflow files analyzed in parallel (0 = number of CPUs)")
+	repoDefragCmd.Flags().IntVar(&defragMatrixMax, "matrix-threshold", 20, "Flag jobs whose strategy.matrix expands to more jobs than this")
+	repoDefragCmd.Flags().StringVar(&defragConcScope, "concurrency-scope", "any", "Concurrency that satisfies workflow.no-concurrency: any (workflow or job level) or workflow (top-level block only)")
+	repoDefragCmd.Flags().BoolVar(&defragNoGit, "no-git", false, "Skip git entirely and use filesystem mtime for last-modified (for tarballs/non-repo dirs)")
 
 	repoDefragCmd.Flags().StringVar(&ghOwner, "github-owner", "", "GitHub owner/org (optional)")
 	repoDefragCmd.Flags().StringVar(&ghRepo, "github-repo", "", "GitHub repository name (optional)")
-	repoDefragCmd.Flags().StringVar(&ghToken, "github-token", os.Getenv("GITHUB_TOKEN"), "GitHub token for API access (env GITHUB_TOKEN supported)")
+	repoDefragCmd.Flags().StringVar(&ghToken, "github-token", "", "GitHub token for API access (default: --github-token-file, then env GITHUB_TOKEN, then gh auth token)")
+	repoDefragCmd.Flags().StringVar(&ghTokenFile, "github-token-file", "", "Read the GitHub token from this file (surrounding whitespace is trimmed)")
 	repoDefragCmd.Flags().IntVar(&ghSampleRuns, "github-runs", 20, "Number of recent workflow runs to sample for failure rate")
+	repoDefragCmd.Flags().StringVar(&ghCacheDir, "github-cache-dir", "", "Cache GitHub API responses in this directory and revalidate them with ETags (304s are cheap on the rate limit)")
+	repoDefragCmd.Flags().IntVar(&ghMaxPRs, "github-max-prs", 1000, "Stop listing open pull requests after this many (0 = all)")
+	repoDefragCmd.Flags().IntVar(&ghRetries, "github-retries", analyzer.DefaultGitHubRetries, "Retry a GitHub API request this many times after a network error, 5xx or rate limit, with exponential backoff (0 = no retries)")
+	repoDefragCmd.Flags().StringVar(&ghAPIURL, "github-api-url", os.Getenv("GITHUB_API_URL"), "GitHub REST API root, e.g. https://github.mycorp.com/api/v3 for GitHub Enterprise Server (env GITHUB_API_URL supported; default https://api.github.com)")
+	repoDefragCmd.Flags().DurationVar(&ghTimeout, "github-timeout", 5*time.Minute, "Overall time budget for GitHub API enrichment; on expiry (or Ctrl-C) the data fetched so far is reported (0 = no limit)")
 
 	repoDefragCmd.Flags().StringVar(&jsonOut, "json", "", "Write JSON report to path (optional)")
 	repoDefragCmd.Flags().StringVar(&mdOut, "md", "", "Write Markdown report to path (optional)")
+	repoDefragCmd.Flags().StringVar(&htmlOut, "html", "", "Write a self-contained HTML report (inline CSS, collapsible workflows) to path (optional)")
+	repoDefragCmd.Flags().StringVar(&junitOut, "junit", "", "Write a JUnit XML test report (one testcase per workflow per rule, failing with the recommendations) to path (optional)")
+	repoDefragCmd.Flags().StringVar(&sarifOut, "sarif", "", "Write findings as SARIF 2.1.0 (for GitHub code scanning upload) to path (optional)")
 	repoDefragCmd.Flags().StringVar(&planOut, "plan", "", "Write Cleanup Plan (Markdown) to path (optional)")
+	repoDefragCmd.Flags().StringVar(&defragOutputDir, "output-dir", "", "Also write report.json, report.md and cleanup-plan.md into this directory, creating it if needed (optional)")
+	repoDefragCmd.Flags().StringVar(&promOut, "prometheus", "", "Write Prometheus metrics (textfile-collector format) to path (optional)")
+	repoDefragCmd.Flags().StringVar(&jobGraphOut, "job-graph", "", "Write job dependency graph (Graphviz DOT) to path (optional)")
+	repoDefragCmd.Flags().StringVar(&callGraphOut, "graph", "", "Write the reusable-workflow call graph (which workflows call which), with cycles and orphaned reusable workflows marked, to path (optional)")
+	repoDefragCmd.Flags().StringVar(&callGraphFormat, "graph-format", "dot", "Format of --graph: dot (Graphviz) or mermaid")
+
+	repoDefragCmd.Flags().StringVar(&defragInputsKB, "deprecated-inputs", "", "YAML file replacing the built-in deprecated setup-action inputs knowledge base")
+	repoDefragCmd.Flags().StringArrayVar(&defragTrusted, "trusted-installer", nil, "URL prefix of a trusted install script exempt from the curl|bash check (repeatable)")
+	repoDefragCmd.Flags().StringArrayVar(&defragOverrides, "severity-override", nil, "Remap a rule's severity as rule=level (repeatable), e.g. workflow.no-concurrency=high")
+	repoDefragCmd.Flags().StringVar(&defragMinSeverity, "min-severity", "low", "Only report findings at or above this severity (low|medium|high)")
+	repoDefragCmd.Flags().StringVar(&defragFailOn, "fail-on", "none", "Exit non-zero when findings at or above this severity exist (none|low|medium|high)")
+	repoDefragCmd.Flags().StringVar(&defragBaselinePath, "compare-baseline", "", "Previous JSON report; only report (and gate on) findings not present in it")
+	repoDefragCmd.Flags().StringVar(&defragDeltaPath, "baseline", "", "Previous JSON report to diff against: new and resolved stale workflows, unpinned actions and findings (all findings are still reported)")
+	repoDefragCmd.Flags().BoolVar(&defragFailFast, "fail-fast", false, "Stop scanning and exit non-zero at the first finding that would fail --fail-on (skips enrichment and reports)")
+	repoDefragCmd.Flags().StringVar(&defragSince, "since", "", "Only report workflows changed after this RFC3339 date (or YYYY-MM-DD) or since this git ref (git diff --name-only <ref>); summary counts cover only those")
+	repoDefragCmd.Flags().BoolVar(&defragStrict, "strict", false, "Aggressive: fail on every finding, including advisory (low) ones; implies --fail-on low --min-severity low")
+	repoDefragCmd.Flags().BoolVar(&defragTable, "table", false, "Print a color-coded table of workflows and their issue flags to the terminal")
+	repoDefragCmd.Flags().StringVar(&defragSort, "sort", "file", "Order findings in every output by file, rule, or severity (most severe first)")
+	repoDefragCmd.Flags().StringVar(&defragFormat, "format", "text", "Stdout format: text (summary line), json, markdown or sarif (the full report, as --json/--md/--sarif write to files), markdown-table (compact table for PR comments), prometheus (metrics), junit (XML test report), or tap (Test Anything Protocol)")
 }
 
 func runRepoDefrag(cmd *cobra.Command, args []string) error {
 	root := defragPath
-	wfPath := filepath.Join(root, defragWorkflowsPath)
 
-	wfReports, err := scanWorkflows(wfPath, defragDaysStale)
+	scan, err := newScanOptions(defragConcScope, defragMatrixMax, defragOverrides)
+	if err != nil {
+		return err
+	}
+	scan.TrustedInstallers = defragTrusted
+	minSev, err := analyzer.ParseSeverity(defragMinSeverity)
+	if err != nil {
+		return fmt.Errorf("--min-severity: %w", err)
+	}
+	switch defragFormat {
+	case "text", "json", "markdown", "sarif", "markdown-table", "prometheus", "junit", "tap":
+	default:
+		return fmt.Errorf("unsupported --format %q (want text|json|markdown|sarif|markdown-table|prometheus|junit|tap)", defragFormat)
+	}
+	// Only the text summary shares stdout with the "Wrote ..." confirmations
+	notes := io.Writer(os.Stdout)
+	if defragFormat != "text" {
+		notes = os.Stderr
+	}
+	switch defragProvider {
+	case "github", "gitlab", "azure":
+	default:
+		return fmt.Errorf("unsupported --provider %q (want github|gitlab|azure)", defragProvider)
+	}
+	switch defragSort {
+	case "file", "rule", "severity":
+	default:
+		return fmt.Errorf("unsupported --sort %q (want file|rule|severity)", defragSort)
+	}
+	switch callGraphFormat {
+	case "dot", "mermaid":
+	default:
+		return fmt.Errorf("unsupported --graph-format %q (want dot|mermaid)", callGraphFormat)
+	}
+	var failOn analyzer.Severity
+	if defragFailOn != "none" {
+		if failOn, err = analyzer.ParseSeverity(defragFailOn); err != nil {
+			return fmt.Errorf("--fail-on: %w", err)
+		}
+	}
+	if defragStrict {
+		// Lower the bar to the lowest severity so any deviation fails the gate
+		failOn, minSev = analyzer.SeverityLow, analyzer.SeverityLow
+	}
+
+	apiURL, err := parseGitHubAPIURL(ghAPIURL)
 	if err != nil {
 		return err
 	}
+	if err := checkGitHubRetries(ghRetries); err != nil {
+		return err
+	}
+
+	if defragInputsKB != "" {
+		if scan.DeprecatedInputs, err = analyzer.LoadDeprecatedInputs(defragInputsKB); err != nil {
+			return err
+		}
+	}
 
-	report := RepoDefragReport{
-		GeneratedAt:   time.Now().UTC(),
-		RootPath:      root,
-		WorkflowsPath: wfPath,
-		StaleDays:     defragDaysStale,
-		Workflows:     wfReports,
+	var baseline *analyzer.Report
+	if defragBaselinePath != "" {
+		if baseline, err = analyzer.LoadReport(defragBaselinePath); err != nil {
+			return err
+		}
 	}
 
-	// Summary
-	for _, w := range wfReports {
-		report.Summary.WorkflowCount++
-		if w.LastModified != nil && time.Since(*w.LastModified) > (time.Duration(defragDaysStale)*24*time.Hour) {
-			report.Summary.WorkflowsStale++
+	var deltaBase *analyzer.Report
+	if defragDeltaPath != "" {
+		if deltaBase, err = analyzer.LoadReport(defragDeltaPath); err != nil {
+			return err
 		}
-		if w.UsesUnpinnedAction {
-			report.Summary.WorkflowsWithUnpinned++
+	}
+
+	if defragFailFast {
+		if failOn == "" {
+			return fmt.Errorf("--fail-fast requires --fail-on or --strict")
 		}
-		if !w.HasConcurrency {
-			report.Summary.WorkflowsWithoutConcurrency++
+		if defragSince != "" {
+			// The scan stops before --since can drop the workflows it would have skipped
+			return fmt.Errorf("--fail-fast cannot be combined with --since")
+		}
+		inBaseline := func(analyzer.Finding) bool { return false }
+		if baseline != nil {
+			inBaseline = analyzer.InBaseline(baseline)
+		}
+		// Same qualification as the final gate: kept by --min-severity, at --fail-on, and new vs baseline
+		scan.StopAt = func(f analyzer.Finding) bool {
+			return f.Severity.AtLeast(minSev) && f.Severity.AtLeast(failOn) && !inBaseline(f)
 		}
 	}
 
+	ignorer, err := newWorkflowIgnorer(root, defragIgnore)
+	if err != nil {
+		return err
+	}
+	scan.Skip = ignorer.skip
+
+	opts := analyzer.Options{
+		Context:      cmd.Context(),
+		Root:         root,
+		Provider:     defragProvider,
+		StaleDays:    defragDaysStale,
+		NoGit:        defragNoGit,
+		Since:        defragSince,
+		MinSeverity:  minSev,
+		SortBy:       defragSort,
+		Baseline:     baseline,
+		BaselinePath: defragBaselinePath,
+		DeltaBase:    deltaBase,
+		DeltaPath:    defragDeltaPath,
+		ScanOptions:  scan,
+	}
+	if defragProvider == "github" {
+		if opts.WorkflowDirs, err = defragWorkflowDirs(cmd, root); err != nil {
+			return err
+		}
+		opts.Concurrency = defragConcurrency
+	}
 	// Optional GitHub API enrichments
+	if ghOwner != "" && ghRepo != "" {
+		if ghToken, err = resolveGitHubToken(cmd.Context(), ghToken, ghTokenFile, apiURL); err != nil {
+			return err
+		}
+	}
 	if ghOwner != "" && ghRepo != "" && ghToken != "" {
-		gh, err := enrichFromGitHub(ghOwner, ghRepo, ghToken, ghSampleRuns, defragDaysStale)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: GitHub enrichment failed: %v\n", err)
-		} else {
-			report.GitHub = gh
+		opts.GitHub = &analyzer.GitHubOptions{
+			Owner: ghOwner, Repo: ghRepo, Token: ghToken, SampleRuns: ghSampleRuns, Timeout: ghTimeout,
+			APIURL: apiURL, Retries: ghRetries, CacheDir: ghCacheDir, MaxPRs: ghMaxPRs,
 		}
 	}
 
+	stopProgress := startProgress(defragFormat == "text")
+	report, err := analyzer.Analyze(opts)
+	stopProgress()
+	var stop *analyzer.StopError
+	if errors.As(err, &stop) {
+		return failFastError(cmd, stop)
+	}
+	if err != nil {
+		return err
+	}
+	if report.IgnoredWorkflows = ignorer.skipped; ignorer.skipped > 0 {
+		infof(notes, "Ignored %d workflow files (--ignore/%s)\n", ignorer.skipped, analyzer.IgnoreFileName)
+	}
+	if report.GitHub != nil && report.GitHub.PRsTruncated {
+		warnf("listed only the first %d open pull requests; raise --github-max-prs to include the rest", ghMaxPRs)
+	}
+	allFindings := report.AllFindings()
+
 	// Output
 	if jsonOut != "" {
 		if err := writeJSON(jsonOut, report); err != nil {
 			return err
 		}
-		fmt.Printf("Wrote JSON report to %s\n", jsonOut)
+		infof(notes, "Wrote JSON report to %s\n", jsonOut)
 	}
 	if mdOut != "" {
 		if err := writeMarkdown(mdOut, report); err != nil {
 			return err
 		}
-		fmt.Printf("Wrote Markdown report to %s\n", mdOut)
+		infof(notes, "Wrote Markdown report to %s\n", mdOut)
+	}
+	if htmlOut != "" {
+		if err := writeHTML(htmlOut, report); err != nil {
+			return err
+		}
+		infof(notes, "Wrote HTML report to %s\n", htmlOut)
+	}
+	// allFindings is already in --sort order
+	var sarifFindings []analyzer.SarifFinding
+	for _, f := range allFindings {
+		sarifFindings = append(sarifFindings, analyzer.SarifFinding{Finding: f})
+	}
+	if sarifOut != "" {
+		if err := analyzer.NewSarifLog(version, root, sarifFindings).Write(sarifOut); err != nil {
+			return err
+		}
+		infof(notes, "Wrote SARIF report to %s\n", sarifOut)
+	}
+	if junitOut != "" {
+		if err := os.WriteFile(junitOut, renderJUnit("rrctl repo-defrag", report.GeneratedAt, junitTargets(report)), 0o644); err != nil {
+			return err
+		}
+		infof(notes, "Wrote JUnit report to %s\n", junitOut)
 	}
 
 	if planOut != "" {
 		if err := writeCleanupPlan(planOut, report); err != nil {
 			return err
 		}
-		fmt.Printf("Wrote Cleanup Plan to %s\n", planOut)
+		infof(notes, "Wrote Cleanup Plan to %s\n", planOut)
 	}
-
-	// Also print a concise summary to stdout
-	fmt.Printf("Workflows: %d, Stale: %d, Unpinned: %d, NoConcurrency: %d\n",
-		report.Summary.WorkflowCount,
-		report.Summary.WorkflowsStale,
-		report.Summary.WorkflowsWithUnpinned,
-		report.Summary.WorkflowsWithoutConcurrency,
-	)
-	if report.GitHub != nil {
-		fmt.Printf("GitHub PRs: %d, Environments: %d, Workflows with failure stats: %d\n",
-			len(report.GitHub.PRs), len(report.GitHub.Environments), len(report.GitHub.WorkflowFailure),
-		)
+	if promOut != "" {
+		if err := writePrometheus(promOut, report); err != nil {
+			return err
+		}
+		infof(notes, "Wrote Prometheus metrics to %s\n", promOut)
 	}
-
-	return nil
-}
-
-func writeJSON(path string, v any) error {
-	b, err := json.MarshalIndent(v, "", "  ")
-	if err != nil {
-		return err
+	if jobGraphOut != "" {
+		if err := writeJobGraph(jobGraphOut, report); err != nil {
+			return err
+		}
+		infof(notes, "Wrote job graph to %s\n", jobGraphOut)
 	}
-	return os.WriteFile(path, b, 0o644)
-}
-
-func writeMarkdown(path string, r RepoDefragReport) error {
-	var buf bytes.Buffer
-	fmt.Fprintf(&buf, "# Repo Defragmentation Report\n\nGenerated: %s UTC\n\n", r.GeneratedAt.Format(time.RFC3339))
-	fmt.Fprintf(&buf, "- Workflows scanned: %d\n- Stale workflows (> %d days): %d\n- Workflows with unpinned actions: %d\n- Workflows without concurrency: %d\n\n",
-		r.Summary.WorkflowCount, r.StaleDays, r.Summary.WorkflowsStale, r.Summary.WorkflowsWithUnpinned, r.Summary.WorkflowsWithoutConcurrency,
-	)
-
-	fmt.Fprintf(&buf, "## Workflows\n\n")
-	for _, w := range r.Workflows {
-		lm := "n/a"
-		if w.LastModified != nil {
-			lm = w.LastModified.Format("2006-01-02")
+	if callGraphOut != "" {
+		g := analyzer.BuildCallGraph(report.Workflows)
+		if err := writeCallGraph(callGraphOut, callGraphFormat, report, g); err != nil {
+			return err
 		}
-		fmt.Fprintf(&buf, "### %s\n\n", w.File)
-		fmt.Fprintf(&buf, "- Name: %s\n- Triggers: %s\n- Schedules: %s\n- Runners: %s\n- Last Modified: %s\n- Concurrency: %v\n- Unpinned Actions: %v\n",
-			valueOr(w.Name, "(none)"), strings.Join(w.Triggers, ", "), strings.Join(w.Schedules, ", "), strings.Join(w.Runners, ", "), lm, w.HasConcurrency, w.UsesUnpinnedAction,
-		)
-		if len(w.UnpinnedDetails) > 0 {
-			fmt.Fprintf(&buf, "  - Unpinned: %s\n", strings.Join(w.UnpinnedDetails, "; "))
+		cycles, orphans := 0, 0
+		for _, n := range g.Nodes {
+			if n.InCycle {
+				cycles++
+			}
+			if n.Orphan {
+				orphans++
+			}
 		}
-		if len(w.DeprecatedHints) > 0 {
-			fmt.Fprintf(&buf, "  - Deprecated: %s\n", strings.Join(w.DeprecatedHints, "; "))
+		infof(notes, "Wrote workflow call graph to %s (%d workflows in call cycles, %d orphaned reusable workflows)\n", callGraphOut, cycles, orphans)
+	}
+
+	if defragOutputDir != "" {
+		written, err := writeOutputDir(defragOutputDir, report)
+		if err != nil {
+			return err
 		}
-		if len(w.Recommendations) > 0 {
-			fmt.Fprintf(&buf, "  - Recommendations: %s\n", strings.Join(w.Recommendations, "; "))
+		infof(notes, "Wrote %d files to %s:\n", len(written), defragOutputDir)
+		for _, p := range written {
+			infof(notes, "  %s\n", p)
 		}
-		fmt.Fprintln(&buf)
 	}
 
-	if r.GitHub != nil {
-		fmt.Fprintf(&buf, "## GitHub Insights (%s/%s)\n\n", r.GitHub.Owner, r.GitHub.Repo)
-		if len(r.GitHub.WorkflowFailure) > 0 {
-			fmt.Fprintf(&buf, "### Workflow Failure Rates\n\n")
-			for _, wf := range r.GitHub.WorkflowFailure {
-				fmt.Fprintf(&buf, "- %s: failure rate %.0f%% over %d runs\n", wf.Name, wf.FailureRate*100, wf.SampledRuns)
-			}
-			fmt.Fprintln(&buf)
+	switch defragFormat {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
 		}
-		if len(r.GitHub.PRs) > 0 {
-			fmt.Fprintf(&buf, "### Stale Pull Requests (> %d days)\n\n", r.StaleDays)
-			for _, pr := range r.GitHub.PRs {
-				if pr.Stale {
-					fmt.Fprintf(&buf, "- #%d %s by %s (updated %s)\n", pr.Number, pr.Title, pr.Author, pr.UpdatedAt.Format("2006-01-02"))
-				}
-			}
-			fmt.Fprintln(&buf)
+	case "markdown":
+		os.Stdout.Write(colorizeSeverityTags(renderMarkdown(report)))
+	case "sarif":
+		if err := analyzer.NewSarifLog(version, root, sarifFindings).Encode(os.Stdout); err != nil {
+			return err
 		}
-		if len(r.GitHub.Environments) > 0 {
-			fmt.Fprintf(&buf, "### Environments\n\n")
-			for _, env := range r.GitHub.Environments {
-				lm := "never"
-				if env.LastDeployed != nil {
-					lm = env.LastDeployed.Format("2006-01-02")
-				}
-				stale := ""
-				if env.IsStale {
-					stale = " (stale)"
-				}
-				fmt.Fprintf(&buf, "- %s: last deployment %s%s\n", env.Name, lm, stale)
-			}
-			fmt.Fprintln(&buf)
+	case "markdown-table":
+		writeMarkdownTable(os.Stdout, report)
+	case "prometheus":
+		os.Stdout.Write(renderPrometheus(report))
+	case "junit":
+		os.Stdout.Write(renderJUnit("rrctl repo-defrag", report.GeneratedAt, junitTargets(report)))
+	case "tap":
+		os.Stdout.Write(renderTAP(report))
+	default:
+		printSummaryLine(report)
+		if defragTable {
+			printWorkflowTable(os.Stdout, report)
 		}
 	}
 
-	return os.WriteFile(path, buf.Bytes(), 0o644)
-}
-
-func valueOr(s, alt string) string {
-	if strings.TrimSpace(s) == "" {
-		return alt
+	if failOn != "" {
+		if n, byRule := analyzer.CountAtLeast(allFindings, failOn); n > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d findings at or above %s severity (%s)", n, failOn, analyzer.FormatRuleCounts(byRule))
+		}
 	}
-	return s
+
+	return nil
 }
 
-// scanWorkflows walks a workflows directory for YAML files and analyzes them
-func scanWorkflows(dir string, daysStale int) ([]WorkflowReport, error) {
-	entries, err := os.ReadDir(dir)
-	if err != nil {
-		return nil, fmt.Errorf("read workflows dir: %w", err)
-	}
-	var out []WorkflowReport
-	for _, e := range entries {
-		if e.IsDir() {
-			continue
+// defragWorkflowDirs joins --workflows onto root and, with --recursive, adds every nested
+// .github/workflows directory. An unchanged default --workflows is dropped in favour of the
+// discovered directories, so a monorepo without root workflows does not warn about it.
+func defragWorkflowDirs(cmd *cobra.Command, root string) ([]string, error) {
+	var dirs []string
+	seen := map[string]bool{}
+	add := func(dir string) {
+		if dir = filepath.Clean(dir); !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
 		}
-		name := e.Name()
-		if !strings.HasSuffix(name, ".yml") && !strings.HasSuffix(name, ".yaml") {
-			continue
+	}
+	if !defragRecursive || cmd.Flags().Changed("workflows") {
+		for _, p := range defragWorkflowsPath {
+			add(filepath.Join(root, p))
 		}
-		full := filepath.Join(dir, name)
-		wr, err := analyzeWorkflowFile(full)
+	}
+	if defragRecursive {
+		found, err := analyzer.FindWorkflowDirs(root)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to analyze %s: %v\n", full, err)
-			continue
+			return nil, err
 		}
-		// Last modified from git
-		if ts, err := gitLastModified(full); err == nil {
-			wr.LastModified = &ts
+		for _, dir := range found {
+			add(dir)
+		}
+		if len(dirs) == 0 {
+			return nil, fmt.Errorf("--recursive: no .github/workflows directory beneath %s", root)
 		}
-		// Recommendations
-		wr.Recommendations = recommendForWorkflow(wr, daysStale)
-		out = append(out, wr)
 	}
-	// sort by file for stable output
-	sort.Slice(out, func(i, j int) bool { return out[i].File < out[j].File })
-	return out, nil
+	return dirs, nil
 }
 
-func analyzeWorkflowFile(path string) (WorkflowReport, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return WorkflowReport{}, err
-	}
-	defer f.Close()
-	dec := yaml.NewDecoder(f)
-	var selected map[string]any
-	for {
-		var m map[string]any
-		if err := dec.Decode(&m); err != nil {
-			if err == io.EOF {
-				break
-			}
-			// YAML parsing failed; use tolerant fallback via regex-based extraction
-			return analyzeWorkflowTextFallback(path)
-		}
-		// Choose the first doc that looks like a workflow (has 'on' at top level or 'jobs')
-		if m != nil && (m["on"] != nil || m["jobs"] != nil) {
-			selected = m
-			break
-		}
-		if selected == nil && m != nil {
-			// fallback to first mapping document
-			selected = m
-		}
-	}
-	if selected == nil {
-		// nothing decoded; fallback to text scan
-		return analyzeWorkflowTextFallback(path)
-	}
-	wr := WorkflowReport{File: path}
-	if n, _ := selected["name"].(string); n != "" {
-		wr.Name = n
-	}
-
-	// triggers
-	wr.Triggers = extractTriggers(selected["on"])
-	// schedules
-	wr.Schedules = extractSchedules(selected["on"])
-	// runners
-	wr.Runners = extractRunners(selected)
-	// concurrency (workflow or job level)
-	wr.HasConcurrency = hasConcurrency(selected)
-	// actions pinning
-	wr.UsesUnpinnedAction, wr.UnpinnedDetails = detectUnpinnedActions(selected)
-	// deprecated hints
-	wr.DeprecatedHints = detectDeprecated(wr)
-	return wr, nil
+// writeOutputDir writes the JSON, Markdown and cleanup plan reports into dir and returns their paths
+func writeOutputDir(dir string, r analyzer.Report) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create output dir: %w", err)
+	}
+	outputs := []struct {
+		name  string
+		write func(string, analyzer.Report) error
+	}{
+		{"report.json", func(p string, r analyzer.Report) error { return writeJSON(p, r) }},
+		{"report.md", writeMarkdown},
+		{"cleanup-plan.md", writeCleanupPlan},
+	}
+	var written []string
+	for _, o := range outputs {
+		p := filepath.Join(dir, o.name)
+		if err := o.write(p, r); err != nil {
+			return written, err
+		}
+		written = append(written, p)
+	}
+	return written, nil
 }
 
-var (
-	reName       = regexp.MustCompile(`(?m)^\s*name:\s*(.+?)\s*$`)
-	reCron       = regexp.MustCompile(`(?m)cron:\s*['"]?([^'"\n]+)['"]?`)
-	reRunsOnLine = regexp.MustCompile(`(?m)^\s*runs-on:\s*(.+)$`)
-	reRunsOnItem = regexp.MustCompile(`(?m)^\s*-\s*([\w\-\.]+)\s*$`)
-	reUses       = regexp.MustCompile(`(?m)^\s*uses:\s*([^@\s]+)(?:@([^\s]+))?\s*$`)
-)
+// failFastError is the gate error for a scan stopped by --fail-fast
+func failFastError(cmd *cobra.Command, stop *analyzer.StopError) error {
+	cmd.SilenceUsage = true
+	return fmt.Errorf("fail-fast: %w", stop)
+}
 
-func analyzeWorkflowTextFallback(path string) (WorkflowReport, error) {
-	b, err := os.ReadFile(path)
+// parseGitHubAPIURL validates --github-api-url; empty means github.com
+func parseGitHubAPIURL(raw string) (string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return analyzer.DefaultGitHubAPIURL, nil
+	}
+	u, err := analyzer.ParseGitHubAPIURL(raw)
 	if err != nil {
-		return WorkflowReport{}, err
-	}
-	s := string(b)
-	wr := WorkflowReport{File: path}
-	if m := reName.FindStringSubmatch(s); len(m) == 2 {
-		wr.Name = strings.TrimSpace(m[1])
-	}
-	// heuristic triggers
-	known := []string{"push", "pull_request", "pull_request_target", "workflow_dispatch", "schedule", "release", "workflow_call"}
-	triggerSet := map[string]struct{}{}
-	for _, k := range known {
-		if regexp.MustCompile("(?m)^\\s*"+regexp.QuoteMeta(k)+"\\s*:").FindStringIndex(s) != nil {
-			triggerSet[k] = struct{}{}
-		}
-	}
-	for k := range triggerSet {
-		wr.Triggers = append(wr.Triggers, k)
-	}
-	sort.Strings(wr.Triggers)
-	// schedules
-	for _, m := range reCron.FindAllStringSubmatch(s, -1) {
-		if len(m) == 2 {
-			wr.Schedules = append(wr.Schedules, strings.TrimSpace(m[1]))
-		}
-	}
-	// runners (line form)
-	set := map[string]struct{}{}
-	for _, m := range reRunsOnLine.FindAllStringSubmatch(s, -1) {
-		raw := strings.TrimSpace(m[1])
-		if strings.HasPrefix(raw, "[") && strings.HasSuffix(raw, "]") {
-			raw = strings.Trim(raw, "[]")
-			for _, tok := range strings.Split(raw, ",") {
-				rr := strings.TrimSpace(strings.Trim(tok, "'\""))
-				if rr != "" {
-					set[rr] = struct{}{}
-				}
-			}
-		} else {
-			rr := strings.TrimSpace(strings.Trim(raw, "'\""))
-			if rr != "" {
-				set[rr] = struct{}{}
-			}
-		}
+		return "", fmt.Errorf("--github-api-url: %w", err)
 	}
-	// list item form
-	for _, m := range reRunsOnItem.FindAllStringSubmatch(s, -1) {
-		rr := strings.TrimSpace(m[1])
-		if rr != "" {
-			set[rr] = struct{}{}
-		}
+	return u, nil
+}
+
+// checkGitHubRetries validates --github-retries
+func checkGitHubRetries(n int) error {
+	if n < 0 {
+		return fmt.Errorf("--github-retries must be 0 or more, got %d", n)
 	}
-	for k := range set {
-		wr.Runners = append(wr.Runners, k)
+	return nil
+}
+
+// newScanOptions validates the analysis flags repo-defrag and lint share: --concurrency-scope,
+// --matrix-threshold and --severity-override
+func newScanOptions(scope string, matrixThreshold int, overrides []string) (analyzer.ScanOptions, error) {
+	switch scope {
+	case "any", "workflow":
+	default:
+		return analyzer.ScanOptions{}, fmt.Errorf("unsupported --concurrency-scope %q (want any|workflow)", scope)
 	}
-	sort.Strings(wr.Runners)
-	// concurrency presence
-	wr.HasConcurrency = detectConcurrencyFallback(s)
-	// unpinned uses
-	var unp []string
-	for _, m := range reUses.FindAllStringSubmatch(s, -1) {
-		ref := strings.TrimSpace(m[2])
-		usesVal := strings.TrimSpace(m[1])
-		if isLocalAction(usesVal) {
-			continue
-		}
-		if ref == "" {
-			unp = append(unp, fmt.Sprintf("uses:%s@%s", usesVal, ref))
-			continue
-		}
-		full := fmt.Sprintf("%s@%s", usesVal, ref)
-		if unpinnedRe.MatchString(full) {
-			unp = append(unp, fmt.Sprintf("uses:%s", full))
-		}
+	if matrixThreshold < 1 {
+		return analyzer.ScanOptions{}, fmt.Errorf("--matrix-threshold must be at least 1, got %d", matrixThreshold)
 	}
-	if len(unp) > 0 {
-		wr.UsesUnpinnedAction = true
-		wr.UnpinnedDetails = unp
+	sevs, err := analyzer.ParseSeverityOverrides(overrides)
+	if err != nil {
+		return analyzer.ScanOptions{}, err
 	}
-	// hints
-	wr.DeprecatedHints = detectDeprecated(wr)
-	return wr, nil
+	return analyzer.ScanOptions{ConcurrencyScope: scope, MatrixThreshold: matrixThreshold, SeverityOverrides: sevs}, nil
 }
 
-func extractTriggers(on any) []string {
-	var out []string
-	switch v := on.(type) {
-	case string:
-		out = append(out, v)
-	case []any:
-		for _, it := range v {
-			if s, ok := it.(string); ok {
-				out = append(out, s)
-			}
+// printSummaryLine prints the concise default stdout summary
+func printSummaryLine(report analyzer.Report) {
+	if gl := report.GitLab; gl != nil {
+		fmt.Printf("GitLab CI: %d jobs, %d findings\n", len(gl.Jobs), len(gl.Findings))
+	} else if az := report.Azure; az != nil {
+		fmt.Printf("Azure Pipelines: %d jobs, %d findings\n", len(az.Jobs), len(az.Findings))
+	} else {
+		fmt.Printf("Workflows: %d, Stale: %d, Unpinned: %d, NoConcurrency: %d, NoPermissions: %d, JobsNoTimeout: %d\n",
+			report.Summary.WorkflowCount,
+			report.Summary.WorkflowsStale,
+			report.Summary.WorkflowsWithUnpinned,
+			report.Summary.WorkflowsWithoutConcurrency,
+			report.Summary.WorkflowsWithoutPermissions,
+			report.Summary.JobsWithoutTimeout,
+		)
+		if p := report.Summary.ActionPins; p != (analyzer.PinCounts{}) {
+			fmt.Printf("Action pins: sha %d, tag %d, branch %d, none %d\n", p.SHA, p.Tag, p.Branch, p.None)
 		}
-	case map[string]any:
-		for k := range v {
-			out = append(out, k)
+	}
+	fmt.Printf("Hygiene score: %d/100\n", report.Summary.HygieneScore)
+	if report.GitHub != nil {
+		fmt.Printf("GitHub PRs: %d, Environments: %d, Workflows with failure stats: %d\n",
+			len(report.GitHub.PRs), len(report.GitHub.Environments), len(report.GitHub.WorkflowFailure),
+		)
+	}
+	if d := report.Delta; d != nil {
+		fmt.Printf("Since baseline %s: %d new findings, %d resolved; stale %+d, unpinned %+d\n",
+			d.Baseline, len(d.NewFindings), len(d.ResolvedFindings), d.SummaryChange["workflowsStale"], d.SummaryChange["workflowsWithUnpinned"])
+	}
+	if b := report.Baseline; b != nil {
+		fmt.Printf("Baseline %s: %d new findings, %d pre-existing suppressed", b.Path, b.NewFindings, b.ExistingFindings)
+		if len(b.NewlyFailing) > 0 {
+			fmt.Printf(", newly failing: %s", strings.Join(b.NewlyFailing, ", "))
 		}
+		fmt.Println()
 	}
-	sort.Strings(out)
-	return out
 }
 
-func extractSchedules(on any) []string {
-	var out []string
-	m, ok := on.(map[string]any)
-	if !ok {
-		return out
-	}
-	if s, ok := m["schedule"].([]any); ok {
-		for _, it := range s {
-			if mm, ok := it.(map[string]any); ok {
-				if cron, ok := mm["cron"].(string); ok {
-					out = append(out, cron)
-				}
+// printWorkflowTable prints an aligned terminal table of workflows and their issue flags.
+// Every cell in a column gets the same color wrapping so tabwriter alignment stays correct.
+func printWorkflowTable(out io.Writer, r analyzer.Report) {
+	flag := func(problem bool, bad, good string) string {
+		if problem {
+			return colorize(ansiRed, bad)
+		}
+		return colorize(ansiGreen, good)
+	}
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "WORKFLOW\t%s\t%s\t%s\tFINDINGS\t%s\n",
+		colorize(ansiBold, "STALE"), colorize(ansiBold, "PINNED"), colorize(ansiBold, "CONCURRENCY"), colorize(ansiBold, "TOP SEVERITY"))
+	for _, w := range r.Workflows {
+		top := analyzer.Severity("")
+		for _, f := range w.Findings {
+			if top == "" || !top.AtLeast(f.Severity) {
+				top = f.Severity
 			}
 		}
+		topCell := colorize(ansiGreen, "-")
+		if top != "" {
+			topCell = colorize(severityColor(top), string(top))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n",
+			filepath.Base(w.File),
+			flag(analyzer.WorkflowIsStale(w, r.StaleDays), "stale", "fresh"),
+			flag(w.UsesUnpinnedAction, "no", "yes"),
+			flag(!w.HasConcurrency, "missing", "ok"),
+			len(w.Findings),
+			topCell,
+		)
 	}
-	return out
+	tw.Flush()
 }
 
-func extractRunners(root map[string]any) []string {
-	set := map[string]struct{}{}
-	jobs, ok := root["jobs"].(map[string]any)
-	if !ok {
-		return nil
-	}
-	for _, jv := range jobs {
-		jm, ok := jv.(map[string]any)
-		if !ok {
-			continue
-		}
-		if r, ok := jm["runs-on"]; ok {
-			switch rv := r.(type) {
-			case string:
-				set[rv] = struct{}{}
-			case []any:
-				for _, it := range rv {
-					if s, ok := it.(string); ok {
-						set[s] = struct{}{}
-					}
-				}
-			}
+// writeMarkdownTable renders the summary plus one compact table row per workflow
+func writeMarkdownTable(out io.Writer, r analyzer.Report) {
+	mark := func(problem bool) string {
+		if problem {
+			return "❌"
 		}
+		return "✅"
 	}
-	var out []string
-	for k := range set {
-		out = append(out, k)
+	fmt.Fprintf(out, "**Workflows:** %d · **Stale:** %d · **Unpinned:** %d · **No concurrency:** %d · **No permissions:** %d\n\n",
+		r.Summary.WorkflowCount, r.Summary.WorkflowsStale, r.Summary.WorkflowsWithUnpinned, r.Summary.WorkflowsWithoutConcurrency, r.Summary.WorkflowsWithoutPermissions,
+	)
+	fmt.Fprintf(out, "| Workflow | Fresh | Pinned | Concurrency | Permissions | Findings |\n")
+	fmt.Fprintf(out, "|---|:---:|:---:|:---:|:---:|---:|\n")
+	for _, w := range r.Workflows {
+		fmt.Fprintf(out, "| %s | %s | %s | %s | %s | %d |\n",
+			strings.ReplaceAll(filepath.Base(w.File), "|", "\\|"),
+			mark(analyzer.WorkflowIsStale(w, r.StaleDays)), mark(w.UsesUnpinnedAction), mark(!w.HasConcurrency), mark(!w.HasPermissions), len(w.Findings),
+		)
+	}
+}
+
+func writeJSON(path string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
 	}
-	sort.Strings(out)
-	return out
+	return os.WriteFile(path, b, 0o644)
+}
+
+func writeMarkdown(path string, r analyzer.Report) error {
+	return os.WriteFile(path, renderMarkdown(r), 0o644)
 }
 
-func hasConcurrency(root map[string]any) bool {
-	if root == nil {
-		return false
+// renderMarkdown renders the full Markdown report (--md, or --format markdown on stdout)
+func renderMarkdown(r analyzer.Report) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Repo Defragmentation Report\n\nGenerated: %s UTC\n\n", r.GeneratedAt.Format(time.RFC3339))
+	if r.Since != "" {
+		fmt.Fprintf(&buf, "Workflows changed since: %s\n\n", r.Since)
 	}
-	if root["concurrency"] != nil {
-		return true
+	if r.IgnoredWorkflows > 0 {
+		fmt.Fprintf(&buf, "Workflows ignored (--ignore/%s): %d\n\n", analyzer.IgnoreFileName, r.IgnoredWorkflows)
 	}
-	jobs, ok := root["jobs"].(map[string]any)
-	if !ok {
-		return false
+	if r.Delta != nil {
+		writeMarkdownDelta(&buf, r.Delta)
 	}
-	for _, jv := range jobs {
-		jm, ok := jv.(map[string]any)
-		if !ok {
-			continue
+	if r.GitLab == nil && r.Azure == nil {
+		writeMarkdownWorkflows(&buf, r)
+	}
+
+	if gl := r.GitLab; gl != nil {
+		lm := "n/a"
+		if gl.LastModified != nil {
+			lm = gl.LastModified.Format("2006-01-02")
+		}
+		fmt.Fprintf(&buf, "## GitLab CI (%s)\n\n- Last Modified: %s\n- Jobs: %d\n\n", gl.File, lm, len(gl.Jobs))
+		if len(gl.Jobs) > 0 {
+			fmt.Fprintf(&buf, "| Job | Stage | Image | Rules | Interruptible | Scheduled |\n|---|---|---|:---:|:---:|:---:|\n")
+			for _, j := range gl.Jobs {
+				fmt.Fprintf(&buf, "| %s | %s | %s | %v | %v | %v |\n", j.Name, valueOr(j.Stage, "test"), valueOr(j.Image, "(default)"), j.HasRules, j.Interruptible, j.Scheduled)
+			}
+			fmt.Fprintln(&buf)
 		}
-		if jm["concurrency"] != nil {
-			return true
+		for _, f := range gl.Findings {
+			fmt.Fprintf(&buf, "- [%s] %s\n", f.Severity, f.Message)
+		}
+		if len(gl.Findings) > 0 {
+			fmt.Fprintln(&buf)
 		}
 	}
-	return false
-}
 
-func detectConcurrencyFallback(text string) bool {
-	lines := strings.Split(text, "\n")
-	for _, line := range lines {
-		trim := strings.TrimSpace(line)
-		if trim == "" || strings.HasPrefix(trim, "#") {
-			continue
-		}
-		if strings.HasPrefix(trim, "concurrency") {
-			// match `concurrency:` optionally followed by value
-			colon := strings.Index(trim, ":")
-			if colon != -1 {
-				key := strings.TrimSpace(trim[:colon])
-				if key == "concurrency" {
-					return true
-				}
+	if az := r.Azure; az != nil {
+		lm := "n/a"
+		if az.LastModified != nil {
+			lm = az.LastModified.Format("2006-01-02")
+		}
+		fmt.Fprintf(&buf, "## Azure Pipelines (%s)\n\n- Last Modified: %s\n- Jobs: %d\n\n", az.File, lm, len(az.Jobs))
+		if len(az.Jobs) > 0 {
+			fmt.Fprintf(&buf, "| Job | Stage | VM image | Deployment | Tasks |\n|---|---|---|:---:|---:|\n")
+			for _, j := range az.Jobs {
+				fmt.Fprintf(&buf, "| %s | %s | %s | %v | %d |\n", j.Name, valueOr(j.Stage, "-"), valueOr(j.VMImage, "(pool)"), j.Deployment, j.Tasks)
 			}
+			fmt.Fprintln(&buf)
+		}
+		for _, f := range az.Findings {
+			fmt.Fprintf(&buf, "- [%s] %s\n", f.Severity, f.Message)
+		}
+		if len(az.Findings) > 0 {
+			fmt.Fprintln(&buf)
 		}
 	}
-	return false
-}
-
-var unpinnedRe = regexp.MustCompile(`^[^@]+@(main|master|HEAD|latest)$`)
 
-func isLocalAction(u string) bool {
-	trim := strings.TrimSpace(u)
-	return strings.HasPrefix(trim, "./") || strings.HasPrefix(trim, "../") || strings.HasPrefix(trim, "docker://")
-}
-
-func detectUnpinnedActions(root map[string]any) (bool, []string) {
-	var details []string
-	flag := false
-	jobs, ok := root["jobs"].(map[string]any)
-	if !ok {
-		return false, nil
+	if len(r.Actions) > 0 {
+		fmt.Fprintf(&buf, "## Actions\n\n")
+		for _, a := range r.Actions {
+			fmt.Fprintf(&buf, "- %s (%s): runs.using %s\n", a.File, valueOr(a.Name, "(none)"), valueOr(a.Using, "(none)"))
+			for _, f := range a.Findings {
+				fmt.Fprintf(&buf, "  - [%s] %s\n", f.Severity, f.Message)
+			}
+		}
+		fmt.Fprintln(&buf)
 	}
-	for jname, jv := range jobs {
-		jm, ok := jv.(map[string]any)
-		if !ok {
-			continue
+
+	if r.GitHub != nil {
+		fmt.Fprintf(&buf, "## GitHub Insights (%s/%s)\n\n", r.GitHub.Owner, r.GitHub.Repo)
+		if r.GitHub.Partial {
+			buf.WriteString("_Partial: enrichment was interrupted or timed out; sections below may be incomplete._\n\n")
 		}
-		steps, ok := jm["steps"].([]any)
-		if !ok {
-			continue
+		if r.GitHub.DefaultBranch != "" {
+			fmt.Fprintf(&buf, "Default branch: %s\n\n", r.GitHub.DefaultBranch)
 		}
-		for _, sv := range steps {
-			sm, ok := sv.(map[string]any)
-			if !ok {
-				continue
-			}
-			if u, ok := sm["uses"].(string); ok {
-				if isLocalAction(u) {
+		if len(r.GitHub.WorkflowFailure) > 0 {
+			fmt.Fprintf(&buf, "### Workflow Failure Rates\n\n")
+			for _, wf := range r.GitHub.WorkflowFailure {
+				if wf.Skipped {
+					fmt.Fprintf(&buf, "- %s: stats skipped (%s)\n", wf.Name, wf.SkipReason)
 					continue
 				}
-				// Not pinned if pointing to a mutable branch or tag
-				if !strings.Contains(u, "@") || unpinnedRe.MatchString(u) {
-					flag = true
-					details = append(details, fmt.Sprintf("job:%s uses:%s", jname, u))
-				}
+				fmt.Fprintf(&buf, "- %s: failure rate %.0f%% over %d runs\n", wf.Name, wf.FailureRate*100, wf.SampledRuns)
 			}
+			fmt.Fprintln(&buf)
 		}
-	}
-	return flag, details
-}
-
-func detectDeprecated(w WorkflowReport) []string {
-	var hints []string
-	for _, r := range w.Runners {
-		if r == "ubuntu-22.04" {
-			hints = append(hints, "Consider ubuntu-24.04")
+		if len(r.GitHub.PRs) > 0 {
+			fmt.Fprintf(&buf, "### Stale Pull Requests (> %d days)\n\n", r.StaleDays)
+			for _, pr := range r.GitHub.PRs {
+				if pr.Stale {
+					fmt.Fprintf(&buf, "- #%d %s by %s (updated %s)\n", pr.Number, pr.Title, pr.Author, pr.UpdatedAt.Format("2006-01-02"))
+				}
+			}
+			fmt.Fprintln(&buf)
 		}
-		if r == "macos-12" {
-			hints = append(hints, "macos-12 deprecated; use macos-13/14/15")
+		if c := r.GitHub.Caches; c != nil {
+			fmt.Fprintf(&buf, "### Actions Caches\n\n")
+			fmt.Fprintf(&buf, "- Total: %d caches, %s (repository quota is 10 GB by default)\n", c.Count, humanBytes(c.TotalSizeBytes))
+			fmt.Fprintf(&buf, "- Not accessed in > %d days: %d caches, %s\n", r.StaleDays, len(c.Stale), humanBytes(c.StaleSizeBytes))
+			for _, e := range c.Oldest {
+				fmt.Fprintf(&buf, "  - oldest: %s (%s, %s, created %s, last used %s)\n", e.Key, e.Ref, humanBytes(e.SizeBytes), e.CreatedAt.Format("2006-01-02"), e.LastAccessedAt.Format("2006-01-02"))
+			}
+			if len(c.Stale) > 0 {
+				fmt.Fprintf(&buf, "- Recommendation: delete stale caches (`gh cache delete <key>`) and scope cache keys so old entries stop accumulating\n")
+			}
+			fmt.Fprintln(&buf)
 		}
-		if r == "self-hosted" {
-			hints = append(hints, "Ensure self-hosted runner labels are specific; add timeouts/concurrency")
+		if len(r.GitHub.Environments) > 0 {
+			fmt.Fprintf(&buf, "### Environments\n\n")
+			for _, env := range r.GitHub.Environments {
+				lm := "never"
+				if env.LastDeployed != nil {
+					lm = env.LastDeployed.Format("2006-01-02")
+				}
+				stale := ""
+				if env.IsStale {
+					stale = " (stale)"
+				}
+				protection := "unprotected (no protection rules)"
+				if !env.Unprotected {
+					protection = "protection: " + strings.Join(env.ProtectionRules, ", ")
+				}
+				fmt.Fprintf(&buf, "- %s: last deployment %s%s; %s\n", env.Name, lm, stale, protection)
+			}
+			fmt.Fprintln(&buf)
 		}
 	}
-	if len(w.Schedules) > 5 {
-		hints = append(hints, "Too many schedules; consider consolidation")
-	}
-	if len(w.Triggers) > 5 {
-		hints = append(hints, "Many triggers; check for overlap with other workflows")
-	}
-	return hints
+
+	return buf.Bytes()
 }
 
-func recommendForWorkflow(w WorkflowReport, daysStale int) []string {
-	var rec []string
-	if w.LastModified != nil && time.Since(*w.LastModified) > (time.Duration(daysStale)*24*time.Hour) {
-		rec = append(rec, "Stale: review necessity or update tooling pins")
-	}
-	if w.UsesUnpinnedAction {
-		rec = append(rec, "Pin actions to specific tags or SHAs")
-	}
-	if !w.HasConcurrency {
-		rec = append(rec, "Add 'concurrency' to avoid duplicate runs on busy repos")
+// writeMarkdownDelta writes the "Changes since baseline" section
+func writeMarkdownDelta(buf *bytes.Buffer, d *analyzer.RepoDefragDelta) {
+	fmt.Fprintf(buf, "## Changes since baseline\n\nBaseline: %s (generated %s)\n\n", d.Baseline, d.BaselineGeneratedAt.Format(time.RFC3339))
+	for _, k := range []string{"workflowCount", "workflowsStale", "workflowsWithUnpinned", "workflowsWithoutConcurrency", "workflowsWithoutPermissions", "jobsWithoutTimeout"} {
+		if n := d.SummaryChange[k]; n != 0 {
+			fmt.Fprintf(buf, "- %s: %+d\n", k, n)
+		}
 	}
-	if len(w.Runners) == 0 {
-		rec = append(rec, "Specify runs-on for each job explicitly")
+	lists := []struct {
+		label string
+		items []string
+	}{
+		{"Added workflows", d.AddedWorkflows},
+		{"Removed workflows", d.RemovedWorkflows},
+		{"Newly stale", d.NewStale},
+		{"No longer stale", d.NoLongerStale},
+		{"Newly unpinned actions", d.NewUnpinned},
+		{"Actions now pinned", d.NowPinned},
 	}
-	return rec
-}
-
-func gitLastModified(path string) (time.Time, error) {
-	cmd := exec.Command("git", "log", "-1", "--format=%ct", "--", path)
-	cmd.Dir = filepath.Dir(path)
-	out, err := cmd.Output()
-	if err != nil {
-		return time.Time{}, err
+	for _, l := range lists {
+		if len(l.items) > 0 {
+			fmt.Fprintf(buf, "- %s: %s\n", l.label, strings.Join(l.items, ", "))
+		}
 	}
-	secStr := strings.TrimSpace(string(out))
-	sec, err := parseInt64(secStr)
-	if err != nil {
-		return time.Time{}, err
+	for _, l := range []struct {
+		label string
+		fs    []analyzer.DeltaFinding
+	}{{"New findings", d.NewFindings}, {"Resolved findings", d.ResolvedFindings}} {
+		fmt.Fprintf(buf, "\n### %s (%d)\n\n", l.label, len(l.fs))
+		for _, f := range l.fs {
+			fmt.Fprintf(buf, "- %s: [%s] %s\n", f.Workflow, f.Severity, f.Message)
+		}
 	}
-	return time.Unix(sec, 0), nil
-}
-
-func parseInt64(s string) (int64, error) {
-	var x int64
-	_, err := fmt.Sscanf(s, "%d", &x)
-	return x, err
+	fmt.Fprintln(buf)
 }
 
-// GitHub API minimal client
-func enrichFromGitHub(owner, repo, token string, sampleRuns, daysStale int) (*GitHubReport, error) {
-	base := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
-	cli := &http.Client{Timeout: 15 * time.Second}
-	auth := "token " + token
-
-	// Workflows list
-	type ghWorkflow struct {
-		ID   int64  `json:"id"`
-		Name string `json:"name"`
-	}
-	type wfResp struct {
-		Workflows []ghWorkflow `json:"workflows"`
-	}
-	var wf wfResp
-	if err := ghGet(cli, base+"/actions/workflows", auth, &wf); err != nil {
-		return nil, err
-	}
+// writeMarkdownWorkflows writes the GitHub Actions summary and per-workflow sections
+func writeMarkdownWorkflows(buf *bytes.Buffer, r analyzer.Report) {
+	fmt.Fprintf(buf, "- Workflows scanned: %d\n- Stale workflows (> %d days): %d\n- Workflows with unpinned actions: %d\n- Workflows without concurrency: %d\n- Workflows without token permissions: %d\n- Jobs without timeout-minutes: %d\n",
+		r.Summary.WorkflowCount, r.StaleDays, r.Summary.WorkflowsStale, r.Summary.WorkflowsWithUnpinned, r.Summary.WorkflowsWithoutConcurrency, r.Summary.WorkflowsWithoutPermissions, r.Summary.JobsWithoutTimeout,
+	)
+	p := r.Summary.ActionPins
+	fmt.Fprintf(buf, "- Action references by pin: %d SHA, %d tag, %d branch, %d without ref\n", p.SHA, p.Tag, p.Branch, p.None)
+	fmt.Fprintf(buf, "- Hygiene score: %d/100\n\n", r.Summary.HygieneScore)
 
-	var failures []WorkflowFailure
-	for _, w := range wf.Workflows {
-		// Runs for each workflow
-		type run struct {
-			Conclusion string `json:"conclusion"`
-		}
-		type runsResp struct {
-			WorkflowRuns []run `json:"workflow_runs"`
+	fmt.Fprintf(buf, "## Workflows\n\n")
+	for _, w := range r.Workflows {
+		lm := "n/a"
+		if w.LastModified != nil {
+			lm = w.LastModified.Format("2006-01-02")
 		}
-		var rr runsResp
-		url := fmt.Sprintf("%s/actions/workflows/%d/runs?per_page=%d", base, w.ID, sampleRuns)
-		if err := ghGet(cli, url, auth, &rr); err != nil {
-			continue
+		fmt.Fprintf(buf, "### %s\n\n", w.File)
+		if len(r.WorkflowsDirs) > 1 {
+			fmt.Fprintf(buf, "- Source: %s\n", w.SourceDir)
 		}
-		total := len(rr.WorkflowRuns)
-		if total == 0 {
-			continue
+		fmt.Fprintf(buf, "- Name: %s\n- Triggers: %s\n- Schedules: %s\n- Runners: %s\n- Last Modified: %s\n- Concurrency: %v\n- Permissions: %s\n- Unpinned Actions: %v\n- Hygiene score: %d/100\n",
+			valueOr(w.Name, "(none)"), strings.Join(w.Triggers, ", "), strings.Join(w.Schedules, ", "), strings.Join(w.Runners, ", "), lm, w.HasConcurrency, valueOr(w.PermissionsScope, "(default)"), w.UsesUnpinnedAction, w.HygieneScore,
+		)
+		if filters := w.FilterSummary(); len(filters) > 0 {
+			fmt.Fprintf(buf, "  - Filters: %s\n", strings.Join(filters, "; "))
 		}
-		fails := 0
-		recentFail := false
-		for i, r := range rr.WorkflowRuns {
-			if r.Conclusion == "failure" || r.Conclusion == "timed_out" || r.Conclusion == "cancelled" {
-				fails++
-				if i == 0 {
-					recentFail = true
+		if len(w.UnpinnedDetails) > 0 {
+			fmt.Fprintf(buf, "  - Unpinned: %s\n", strings.Join(w.UnpinnedDetails, "; "))
+		}
+		if len(w.ReusableWorkflows) > 0 {
+			var calls []string
+			for _, rw := range w.ReusableWorkflows {
+				call := fmt.Sprintf("job:%s uses:%s", rw.Job, rw.Uses)
+				switch {
+				case rw.Mutable:
+					call += " (mutable ref)"
+				case rw.Exists != nil && !*rw.Exists:
+					call += " (missing)"
 				}
+				calls = append(calls, call)
 			}
+			fmt.Fprintf(buf, "  - Reusable workflows: %s\n", strings.Join(calls, "; "))
 		}
-		failures = append(failures, WorkflowFailure{Name: w.Name, WorkflowID: w.ID, SampledRuns: total, FailureRate: float64(fails) / float64(total), RecentFailure: recentFail})
-	}
-
-	// PRs
-	type ghPR struct {
-		Number    int       `json:"number"`
-		Title     string    `json:"title"`
-		Draft     bool      `json:"draft"`
-		UpdatedAt time.Time `json:"updated_at"`
-		User      struct {
-			Login string `json:"login"`
-		} `json:"user"`
-		Head struct {
-			SHA string `json:"sha"`
-		} `json:"head"`
-	}
-	var prs []ghPR
-	if err := ghGet(cli, base+"/pulls?state=open&per_page=100", auth, &prs); err != nil {
-		return nil, err
-	}
-	var prReports []PRReport
-	for _, p := range prs {
-		stale := time.Since(p.UpdatedAt) > (time.Duration(daysStale) * 24 * time.Hour)
-		prReports = append(prReports, PRReport{Number: p.Number, Title: p.Title, Author: p.User.Login, Draft: p.Draft, UpdatedAt: p.UpdatedAt, Stale: stale, HeadSHA: p.Head.SHA})
-	}
-
-	// Environments
-	var envs struct {
-		Environments []struct {
-			Name string `json:"name"`
-		} `json:"environments"`
-	}
-	if err := ghGet(cli, base+"/environments", auth, &envs); err != nil {
-		return nil, err
-	}
-	var envReports []EnvironmentProbe
-	for _, e := range envs.Environments {
-		// deployments (most recent)
-		var deps []struct {
-			UpdatedAt time.Time `json:"updated_at"`
+		if len(w.DeprecatedHints) > 0 {
+			fmt.Fprintf(buf, "  - Deprecated: %s\n", strings.Join(w.DeprecatedHints, "; "))
 		}
-		if err := ghGet(cli, base+"/deployments?per_page=1&environment="+urlQueryEscape(e.Name), auth, &deps); err != nil {
-			continue
+		if len(w.SecurityHints) > 0 {
+			fmt.Fprintf(buf, "  - Security: %s\n", strings.Join(w.SecurityHints, "; "))
 		}
-		var last *time.Time
-		if len(deps) > 0 {
-			last = &deps[0].UpdatedAt
+		if len(w.CachingHints) > 0 {
+			fmt.Fprintf(buf, "  - Caching: %s\n", strings.Join(w.CachingHints, "; "))
 		}
-		stale := true
-		if last != nil {
-			stale = time.Since(*last) > (time.Duration(daysStale) * 24 * time.Hour)
+		if len(w.Findings) > 0 {
+			fmt.Fprintf(buf, "  - Recommendations: %s\n", strings.Join(analyzer.SeverityTagged(w.Findings), "; "))
 		}
-		envReports = append(envReports, EnvironmentProbe{Name: e.Name, LastDeployed: last, IsStale: stale})
+		fmt.Fprintln(buf)
 	}
-
-	return &GitHubReport{Owner: owner, Repo: repo, WorkflowFailure: failures, PRs: prReports, Environments: envReports}, nil
 }
 
-func ghGet(cli *http.Client, url, auth string, v any) error {
-	req, _ := http.NewRequest("GET", url, nil)
-	if auth != "" {
-		req.Header.Set("Authorization", auth)
+// humanBytes renders a byte count with a binary unit suffix
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
 	}
-	req.Header.Set("Accept", "application/vnd.github+json")
-	res, err := cli.Do(req)
-	if err != nil {
-		return err
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
 	}
-	defer res.Body.Close()
-	if res.StatusCode == 404 {
-		return errors.New("resource not found: " + url)
-	}
-	if res.StatusCode == 401 {
-		return errors.New("unauthorized: bad token or permissions")
-	}
-	if res.StatusCode >= 300 {
-		b, _ := io.ReadAll(res.Body)
-		return fmt.Errorf("github %d: %s", res.StatusCode, string(b))
-	}
-	return json.NewDecoder(res.Body).Decode(v)
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
 }
 
-func urlQueryEscape(s string) string {
-	// minimal escape for spaces -> %20 and plus signs -> %2B
-	r := strings.NewReplacer(" ", "%20", "+", "%2B")
-	return r.Replace(s)
+func valueOr(s, alt string) string {
+	if strings.TrimSpace(s) == "" {
+		return alt
+	}
+	return s
 }
 
-func writeCleanupPlan(path string, r RepoDefragReport) error {
+func writeCleanupPlan(path string, r analyzer.Report) error {
 	var buf bytes.Buffer
 	fmt.Fprintf(&buf, "# CI Cleanup Plan\n\nGenerated: %s UTC\n\n", r.GeneratedAt.Format(time.RFC3339))
-	fmt.Fprintf(&buf, "- Workflows scanned: %d\n- Stale threshold: %d days\n\n", r.Summary.WorkflowCount, r.StaleDays)
+	fmt.Fprintf(&buf, "- Workflows scanned: %d\n- Stale threshold: %d days\n- Hygiene score: %d/100\n\n", r.Summary.WorkflowCount, r.StaleDays, r.Summary.HygieneScore)
 
 	// High level actions
 	fmt.Fprintf(&buf, "## High-level actions\n\n")
@@ -828,9 +869,26 @@ func writeCleanupPlan(path string, r RepoDefragReport) error {
 	if r.Summary.WorkflowsWithoutConcurrency > 0 {
 		fmt.Fprintf(&buf, "- Add concurrency to prevent duplicate runs (missing in %d workflows)\n", r.Summary.WorkflowsWithoutConcurrency)
 	}
+	if r.Summary.WorkflowsWithoutPermissions > 0 {
+		fmt.Fprintf(&buf, "- Set least-privilege token permissions (missing in %d workflows)\n", r.Summary.WorkflowsWithoutPermissions)
+	}
+	if r.Summary.JobsWithoutTimeout > 0 {
+		fmt.Fprintf(&buf, "- Set timeout-minutes on jobs so hung runs stop before the 6-hour default (missing on %d jobs)\n", r.Summary.JobsWithoutTimeout)
+	}
 	if r.Summary.WorkflowsStale > 0 {
 		fmt.Fprintf(&buf, "- Review or remove stale workflows (found %d)\n", r.Summary.WorkflowsStale)
 	}
+	if r.GitHub != nil {
+		var unprotected []string
+		for _, env := range r.GitHub.Environments {
+			if env.Unprotected {
+				unprotected = append(unprotected, env.Name)
+			}
+		}
+		if len(unprotected) > 0 {
+			fmt.Fprintf(&buf, "- Add required reviewers to unprotected environments (%s)\n", strings.Join(unprotected, ", "))
+		}
+	}
 	fmt.Fprintln(&buf)
 
 	fmt.Fprintf(&buf, "## Recommended snippets\n\n")
@@ -838,23 +896,42 @@ func writeCleanupPlan(path string, r RepoDefragReport) error {
 	fmt.Fprintf(&buf, "```yaml\nconcurrency:\n  group: ${{ github.workflow }}-${{ github.ref }}\n  cancel-in-progress: true\n```\n\n")
 	fmt.Fprintf(&buf, "### Actions pinning example\n\n")
 	fmt.Fprintf(&buf, "```yaml\n- uses: actions/checkout@v4\n- uses: actions/setup-go@v5\n  with:\n    go-version: '1.22'\n```\n\n")
+	fmt.Fprintf(&buf, "### Dependency caching example\n\n")
+	fmt.Fprintf(&buf, "```yaml\n- uses: actions/setup-node@v4\n  with:\n    node-version: 20\n    cache: npm  # or yarn / pnpm; setup-python takes cache: pip\n# setup-go@v4+ caches by default; drop any cache: false\n```\n\n")
 
-	// Per-workflow recommendations
+	// Per-workflow recommendations, most severe first (then lowest score) so the plan reads as a
+	// priority list whatever --sort says
 	fmt.Fprintf(&buf, "## Workflow-specific recommendations\n\n")
-	for _, w := range r.Workflows {
+	ws := append([]analyzer.WorkflowReport(nil), r.Workflows...)
+	sort.SliceStable(ws, func(i, j int) bool {
+		a, b := analyzer.TopSeverity(ws[i].Findings), analyzer.TopSeverity(ws[j].Findings)
+		if a != b {
+			return a.AtLeast(b)
+		}
+		return ws[i].HygieneScore < ws[j].HygieneScore
+	})
+	for _, w := range ws {
 		if len(w.Recommendations) == 0 && len(w.DeprecatedHints) == 0 && !w.UsesUnpinnedAction && w.HasConcurrency {
 			continue
 		}
-		fmt.Fprintf(&buf, "### %s\n\n", filepath.Base(w.File))
+		fmt.Fprintf(&buf, "### %s (score %d/100)\n\n", filepath.Base(w.File), w.HygieneScore)
 		if w.Name != "" {
 			fmt.Fprintf(&buf, "- Name: %s\n", w.Name)
 		}
-		if len(w.Recommendations) > 0 {
-			fmt.Fprintf(&buf, "- Recommendations: %s\n", strings.Join(w.Recommendations, "; "))
+		if len(w.Findings) > 0 {
+			fs := append([]analyzer.Finding(nil), w.Findings...)
+			analyzer.SortFindings(fs, "severity")
+			fmt.Fprintf(&buf, "- Recommendations: %s\n", strings.Join(analyzer.SeverityTagged(fs), "; "))
 		}
 		if len(w.DeprecatedHints) > 0 {
 			fmt.Fprintf(&buf, "- Hints: %s\n", strings.Join(w.DeprecatedHints, "; "))
 		}
+		if len(w.SecurityHints) > 0 {
+			fmt.Fprintf(&buf, "- Security: %s\n", strings.Join(w.SecurityHints, "; "))
+		}
+		if len(w.CachingHints) > 0 {
+			fmt.Fprintf(&buf, "- Caching: %s (see snippet above)\n", strings.Join(w.CachingHints, "; "))
+		}
 		if w.UsesUnpinnedAction {
 			fmt.Fprintf(&buf, "- Unpinned steps: %s\n", strings.Join(w.UnpinnedDetails, "; "))
 		}
@@ -865,6 +942,9 @@ func writeCleanupPlan(path string, r RepoDefragReport) error {
 	}
 
 	if r.GitHub != nil {
+		if c := r.GitHub.Caches; c != nil && len(c.Stale) > 0 {
+			fmt.Fprintf(&buf, "## Actions Caches\n\n- Delete %d caches not accessed in > %d days (%s)\n\n", len(c.Stale), r.StaleDays, humanBytes(c.StaleSizeBytes))
+		}
 		fmt.Fprintf(&buf, "## Pull Requests (staleness > %d days)\n\n", r.StaleDays)
 		for _, pr := range r.GitHub.PRs {
 			if pr.Stale {
@@ -883,6 +963,9 @@ func writeCleanupPlan(path string, r RepoDefragReport) error {
 				stale = " (stale)"
 			}
 			fmt.Fprintf(&buf, "- %s: last deployment %s%s\n", env.Name, lm, stale)
+			if env.Unprotected {
+				fmt.Fprintf(&buf, "  - No protection rules: add required reviewers (and optionally a wait timer or deployment branch policy) so deployments to %s need approval\n", env.Name)
+			}
 		}
 		fmt.Fprintln(&buf)
 	}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kushin77/rrctl/analyzer"
+)
+
+// writeCallGraph renders the reusable-workflow call graph as Graphviz DOT or Mermaid: a node per
+// workflow and an edge, labelled with the calling job, per jobs.<id>.uses reference. Cycles are
+// drawn in red, orphaned reusable workflows dashed, and remote callees as rounded nodes.
+func writeCallGraph(path, format string, r analyzer.Report, g analyzer.CallGraph) error {
+	label := func(n analyzer.CallNode) string {
+		l := n.ID
+		if !n.Remote {
+			if rel, err := filepath.Rel(r.RootPath, n.ID); err == nil && !strings.HasPrefix(rel, "..") {
+				l = filepath.ToSlash(rel)
+			}
+		}
+		switch {
+		case n.Missing:
+			l += " (missing)"
+		case n.Orphan:
+			l += " (orphan: never called)"
+		}
+		if n.InCycle {
+			l += " (cycle)"
+		}
+		return l
+	}
+	var buf bytes.Buffer
+	if format == "mermaid" {
+		writeMermaidCallGraph(&buf, g, label)
+	} else {
+		writeDOTCallGraph(&buf, g, label)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+func writeDOTCallGraph(buf *bytes.Buffer, g analyzer.CallGraph, label func(analyzer.CallNode) string) {
+	fmt.Fprintf(buf, "digraph workflows {\n  rankdir=LR;\n  node [shape=box];\n")
+	for _, n := range g.Nodes {
+		attrs := []string{fmt.Sprintf("label=%q", label(n))}
+		if n.Remote {
+			attrs = append(attrs, `style="rounded,dashed"`)
+		}
+		if n.Orphan {
+			attrs = append(attrs, "style=dashed", "color=gray50", "fontcolor=gray50")
+		}
+		if n.Missing || n.InCycle {
+			attrs = append(attrs, "color=red", "fontcolor=red")
+		}
+		fmt.Fprintf(buf, "  %q [%s];\n", n.ID, strings.Join(attrs, ", "))
+	}
+	for _, e := range g.Edges {
+		attrs := fmt.Sprintf("label=%q", e.Job)
+		if e.InCycle {
+			attrs += ", color=red, fontcolor=red"
+		}
+		fmt.Fprintf(buf, "  %q -> %q [%s];\n", e.From, e.To, attrs)
+	}
+	fmt.Fprintf(buf, "}\n")
+}
+
+func writeMermaidCallGraph(buf *bytes.Buffer, g analyzer.CallGraph, label func(analyzer.CallNode) string) {
+	// Mermaid node IDs must be plain words, so nodes are numbered in graph order
+	ids := map[string]string{}
+	fmt.Fprintf(buf, "flowchart LR\n")
+	var orphan, problem []string
+	for i, n := range g.Nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[n.ID] = id
+		text := strings.ReplaceAll(label(n), `"`, "#quot;")
+		if n.Remote {
+			fmt.Fprintf(buf, "  %s([%q])\n", id, text)
+		} else {
+			fmt.Fprintf(buf, "  %s[%q]\n", id, text)
+		}
+		switch {
+		case n.Missing || n.InCycle:
+			problem = append(problem, id)
+		case n.Orphan:
+			orphan = append(orphan, id)
+		}
+	}
+	var cycleEdges []string
+	for i, e := range g.Edges {
+		fmt.Fprintf(buf, "  %s -->|%s| %s\n", ids[e.From], strings.ReplaceAll(e.Job, "|", "#124;"), ids[e.To])
+		if e.InCycle {
+			cycleEdges = append(cycleEdges, fmt.Sprint(i))
+		}
+	}
+	if len(orphan) > 0 {
+		fmt.Fprintf(buf, "  classDef orphan stroke-dasharray:5 5,color:#888\n  class %s orphan\n", strings.Join(orphan, ","))
+	}
+	if len(problem) > 0 {
+		fmt.Fprintf(buf, "  classDef problem stroke:#d00,color:#d00\n  class %s problem\n", strings.Join(problem, ","))
+	}
+	if len(cycleEdges) > 0 {
+		fmt.Fprintf(buf, "  linkStyle %s stroke:#d00\n", strings.Join(cycleEdges, ","))
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kushin77/rrctl/analyzer"
+)
+
+// htmlReportTemplate renders a self-contained report: inline CSS, no scripts or external assets.
+// html/template escapes every value, so workflow names, messages and PR titles are safe to embed.
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"base":    filepath.Base,
+	"stale":   analyzer.WorkflowIsStale,
+	"date":    func(t time.Time) string { return t.Format("2006-01-02") },
+	"pct":     func(f float64) string { return fmt.Sprintf("%.0f%%", f*100) },
+	"bytes":   humanBytes,
+	"valueOr": valueOr,
+	"join":    func(s []string) string { return strings.Join(s, ", ") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Repo Defragmentation Report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem auto; max-width: 72rem; color: #1f2328; padding: 0 1rem; }
+h1 { margin-bottom: 0.2rem; }
+.meta { color: #59636e; margin-top: 0; }
+table { border-collapse: collapse; width: 100%; margin: 1rem 0; }
+th, td { border: 1px solid #d1d9e0; padding: 0.4rem 0.6rem; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+.badge { display: inline-block; border-radius: 1rem; padding: 0.05rem 0.6rem; font-size: 0.8rem; font-weight: 600; color: #fff; }
+.ok { background: #1a7f37; }
+.bad { background: #cf222e; }
+.warn { background: #9a6700; }
+.sev-high { background: #cf222e; }
+.sev-medium { background: #bc4c00; }
+.sev-low { background: #59636e; }
+details { border: 1px solid #d1d9e0; border-radius: 6px; margin: 0.5rem 0; padding: 0.5rem 1rem; }
+summary { cursor: pointer; font-weight: 600; }
+summary .badge { margin-left: 0.4rem; }
+ul { padding-left: 1.2rem; }
+code { background: #f6f8fa; padding: 0.1rem 0.3rem; border-radius: 4px; }
+</style>
+</head>
+<body>
+<h1>Repo Defragmentation Report</h1>
+<p class="meta">{{.RootPath}} &middot; generated {{.GeneratedAt.Format "2006-01-02 15:04:05"}} UTC &middot; stale threshold {{.StaleDays}} days</p>
+{{- with .GitLab}}
+<h2>GitLab CI</h2>
+<table>
+<tr><th>Job</th><th>Stage</th><th>Image</th><th>Rules</th><th>Interruptible</th><th>Scheduled</th></tr>
+{{- range .Jobs}}
+<tr><td>{{.Name}}</td><td>{{valueOr .Stage "test"}}</td><td><code>{{valueOr .Image "(default)"}}</code></td>
+<td>{{if .HasRules}}<span class="badge ok">yes</span>{{else}}<span class="badge warn">no</span>{{end}}</td>
+<td>{{if .Interruptible}}<span class="badge ok">yes</span>{{else}}<span class="badge warn">no</span>{{end}}</td>
+<td>{{if .Scheduled}}yes{{else}}no{{end}}</td></tr>
+{{- end}}
+</table>
+{{- if .Findings}}
+<ul>
+{{- range .Findings}}
+<li><span class="badge sev-{{.Severity}}">{{.Severity}}</span> {{.Message}} <code>{{.RuleID}}</code></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- else with .Azure}}
+<h2>Azure Pipelines</h2>
+<table>
+<tr><th>Job</th><th>Stage</th><th>VM image</th><th>Deployment</th><th>Tasks</th></tr>
+{{- range .Jobs}}
+<tr><td>{{.Name}}</td><td>{{valueOr .Stage "-"}}</td><td><code>{{valueOr .VMImage "(pool)"}}</code></td>
+<td>{{if .Deployment}}yes{{else}}no{{end}}</td><td>{{.Tasks}}</td></tr>
+{{- end}}
+</table>
+{{- if .Findings}}
+<ul>
+{{- range .Findings}}
+<li><span class="badge sev-{{.Severity}}">{{.Severity}}</span> {{.Message}} <code>{{.RuleID}}</code></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- else}}
+<h2>Summary</h2>
+<table>
+<tr><th>Workflows</th><th>Stale</th><th>Unpinned</th><th>No concurrency</th><th>No permissions</th><th>Jobs without timeout</th><th>Hygiene score</th></tr>
+<tr><td>{{.Summary.WorkflowCount}}</td><td>{{.Summary.WorkflowsStale}}</td><td>{{.Summary.WorkflowsWithUnpinned}}</td><td>{{.Summary.WorkflowsWithoutConcurrency}}</td><td>{{.Summary.WorkflowsWithoutPermissions}}</td><td>{{.Summary.JobsWithoutTimeout}}</td><td>{{.Summary.HygieneScore}}/100</td></tr>
+</table>
+<table>
+<tr><th>SHA-pinned actions</th><th>Tag-pinned</th><th>Branch</th><th>No ref</th></tr>
+{{- with .Summary.ActionPins}}
+<tr><td>{{.SHA}}</td><td>{{.Tag}}</td><td>{{.Branch}}</td><td>{{.None}}</td></tr>
+{{- end}}
+</table>
+<h2>Workflows</h2>
+{{- $days := .StaleDays}}
+{{- range .Workflows}}
+<details>
+<summary>{{base .File}}{{if .Name}} &mdash; {{.Name}}{{end}}
+{{- if stale . $days}}<span class="badge bad">stale</span>{{else}}<span class="badge ok">fresh</span>{{end}}
+{{- if .UsesUnpinnedAction}}<span class="badge bad">unpinned</span>{{else}}<span class="badge ok">pinned</span>{{end}}
+{{- if not .HasConcurrency}}<span class="badge warn">no concurrency</span>{{end}}
+{{- if .Findings}} ({{len .Findings}} findings){{end}} &middot; score {{.HygieneScore}}/100</summary>
+<ul>
+<li>File: <code>{{.File}}</code></li>
+<li>Triggers: {{valueOr (join .Triggers) "(none)"}}</li>
+{{- with .FilterSummary}}<li>Filters: {{join .}}</li>{{end}}
+<li>Runners: {{valueOr (join .Runners) "(none)"}}</li>
+{{- if .Schedules}}<li>Schedules: {{join .Schedules}}</li>{{end}}
+<li>Last modified: {{with .LastModified}}{{date .}}{{else}}n/a{{end}}</li>
+<li>Permissions: {{valueOr .PermissionsScope "(default)"}}</li>
+{{- if .UnpinnedDetails}}<li>Unpinned: {{join .UnpinnedDetails}}</li>{{end}}
+{{- if .DeprecatedHints}}<li>Hints: {{join .DeprecatedHints}}</li>{{end}}
+{{- if .SecurityHints}}<li>Security: {{join .SecurityHints}}</li>{{end}}
+{{- if .CachingHints}}<li>Caching: {{join .CachingHints}}</li>{{end}}
+</ul>
+{{- if .Findings}}
+<table>
+<tr><th>Severity</th><th>Rule</th><th>Finding</th></tr>
+{{- range .Findings}}
+<tr><td><span class="badge sev-{{.Severity}}">{{.Severity}}</span></td><td><code>{{.RuleID}}</code></td><td>{{.Message}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</details>
+{{- end}}
+{{- end}}
+{{- if .Actions}}
+<h2>Actions</h2>
+<ul>
+{{- range .Actions}}
+<li><code>{{.File}}</code> ({{valueOr .Name "(none)"}}): runs.using {{valueOr .Using "(none)"}}
+{{- range .Findings}} <span class="badge sev-{{.Severity}}">{{.Severity}}</span> {{.Message}}{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- with .GitHub}}
+<h2>GitHub Insights ({{.Owner}}/{{.Repo}})</h2>
+{{- if .DefaultBranch}}<p>Default branch: <code>{{.DefaultBranch}}</code></p>{{end}}
+{{- if .WorkflowFailure}}
+<h3>Workflow failure rates</h3>
+<table>
+<tr><th>Workflow</th><th>Failure rate</th><th>Runs sampled</th></tr>
+{{- range .WorkflowFailure}}
+<tr><td>{{.Name}}</td>{{if .Skipped}}<td colspan="2">skipped ({{.SkipReason}})</td>{{else}}<td>{{pct .FailureRate}}{{if .RecentFailure}} <span class="badge bad">last run failed</span>{{end}}</td><td>{{.SampledRuns}}</td>{{end}}</tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .PRs}}
+<h3>Pull requests</h3>
+<table>
+<tr><th>PR</th><th>Author</th><th>Updated</th><th></th></tr>
+{{- range .PRs}}
+<tr><td>#{{.Number}} {{.Title}}</td><td>{{.Author}}</td><td>{{date .UpdatedAt}}</td><td>{{if .Stale}}<span class="badge bad">stale</span>{{end}}{{if .Draft}} <span class="badge sev-low">draft</span>{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with .Caches}}
+<h3>Actions caches</h3>
+<p>{{.Count}} caches, {{bytes .TotalSizeBytes}}; {{len .Stale}} stale ({{bytes .StaleSizeBytes}})</p>
+{{- end}}
+{{- if .Environments}}
+<h3>Environments</h3>
+<table>
+<tr><th>Environment</th><th>Last deployment</th><th>Protection</th><th></th></tr>
+{{- range .Environments}}
+<tr><td>{{.Name}}</td><td>{{with .LastDeployed}}{{date .}}{{else}}never{{end}}</td><td>{{if .Unprotected}}<span class="badge bad">unprotected</span>{{else}}{{join .ProtectionRules}}{{end}}</td><td>{{if .IsStale}}<span class="badge bad">stale</span>{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+// writeHTML renders the report as a single self-contained HTML file
+func writeHTML(path string, r analyzer.Report) error {
+	var buf bytes.Buffer
+	if err := htmlReportTemplate.Execute(&buf, r); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kushin77/rrctl/analyzer"
+)
+
+// writeJobGraph renders every workflow's job dependency graph as Graphviz DOT
+func writeJobGraph(path string, r analyzer.Report) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "digraph jobs {\n  rankdir=LR;\n  node [shape=box];\n")
+	for i, w := range r.Workflows {
+		if len(w.JobNeeds) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "  subgraph cluster_%d {\n    label=%q;\n", i, filepath.Base(w.File))
+		var ids []string
+		for id := range w.JobNeeds {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			fmt.Fprintf(&buf, "    %q [label=%q];\n", fmt.Sprintf("%d:%s", i, id), id)
+		}
+		for _, id := range ids {
+			for _, dep := range w.JobNeeds[id] {
+				fmt.Fprintf(&buf, "    %q -> %q;\n", fmt.Sprintf("%d:%s", i, dep), fmt.Sprintf("%d:%s", i, id))
+			}
+		}
+		fmt.Fprintf(&buf, "  }\n")
+	}
+	fmt.Fprintf(&buf, "}\n")
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
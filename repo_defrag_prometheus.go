@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kushin77/rrctl/analyzer"
+)
+
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// writePrometheus writes the metrics file for the node-exporter textfile collector. The file is
+// written to a temp path and renamed so the collector never reads a partial file.
+func writePrometheus(path string, r analyzer.Report) error {
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := os.WriteFile(tmp, renderPrometheus(r), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// renderPrometheus renders the report in the Prometheus text exposition format
+func renderPrometheus(r analyzer.Report) []byte {
+	var buf bytes.Buffer
+	gauge := func(name, help string) {
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+
+	gauge("rrctl_workflows_total", "Number of workflow files scanned.")
+	fmt.Fprintf(&buf, "rrctl_workflows_total %d\n", r.Summary.WorkflowCount)
+	gauge("rrctl_workflows_stale", "Workflows not modified within the stale threshold.")
+	fmt.Fprintf(&buf, "rrctl_workflows_stale %d\n", r.Summary.WorkflowsStale)
+	gauge("rrctl_workflows_unpinned", "Workflows referencing unpinned actions.")
+	fmt.Fprintf(&buf, "rrctl_workflows_unpinned %d\n", r.Summary.WorkflowsWithUnpinned)
+	gauge("rrctl_workflows_without_concurrency", "Workflows without concurrency control.")
+	fmt.Fprintf(&buf, "rrctl_workflows_without_concurrency %d\n", r.Summary.WorkflowsWithoutConcurrency)
+	gauge("rrctl_workflows_without_permissions", "Workflows without explicit token permissions.")
+	fmt.Fprintf(&buf, "rrctl_workflows_without_permissions %d\n", r.Summary.WorkflowsWithoutPermissions)
+	gauge("rrctl_jobs_without_timeout", "Jobs without timeout-minutes.")
+	fmt.Fprintf(&buf, "rrctl_jobs_without_timeout %d\n", r.Summary.JobsWithoutTimeout)
+	gauge("rrctl_action_refs", "Step-level action references by pin level (sha, tag, branch, none).")
+	p := r.Summary.ActionPins
+	for _, l := range []struct {
+		level analyzer.PinLevel
+		n     int
+	}{{analyzer.PinSHA, p.SHA}, {analyzer.PinTag, p.Tag}, {analyzer.PinBranch, p.Branch}, {analyzer.PinNone, p.None}} {
+		fmt.Fprintf(&buf, "rrctl_action_refs{pin=%q} %d\n", l.level, l.n)
+	}
+
+	gauge("rrctl_hygiene_score", "Repository hygiene score (0-100, 100 = no findings).")
+	fmt.Fprintf(&buf, "rrctl_hygiene_score %d\n", r.Summary.HygieneScore)
+
+	counts := map[analyzer.Severity]int{}
+	for _, w := range r.Workflows {
+		for _, f := range w.Findings {
+			counts[f.Severity]++
+		}
+	}
+	for _, a := range r.Actions {
+		for _, f := range a.Findings {
+			counts[f.Severity]++
+		}
+	}
+	gauge("rrctl_findings", "Findings by severity.")
+	for _, sev := range []analyzer.Severity{analyzer.SeverityHigh, analyzer.SeverityMedium, analyzer.SeverityLow} {
+		fmt.Fprintf(&buf, "rrctl_findings{severity=%q} %d\n", sev, counts[sev])
+	}
+
+	if r.GitHub != nil {
+		repo := promLabelEscaper.Replace(r.GitHub.Owner + "/" + r.GitHub.Repo)
+		gauge("rrctl_workflow_failure_rate", "Failure rate over the sampled recent runs (0-1).")
+		for _, wf := range r.GitHub.WorkflowFailure {
+			if wf.Skipped {
+				continue
+			}
+			fmt.Fprintf(&buf, "rrctl_workflow_failure_rate{repo=\"%s\",workflow=\"%s\"} %g\n", repo, promLabelEscaper.Replace(wf.Name), wf.FailureRate)
+		}
+		gauge("rrctl_workflow_sampled_runs", "Number of recent runs sampled for the failure rate.")
+		for _, wf := range r.GitHub.WorkflowFailure {
+			if wf.Skipped {
+				continue
+			}
+			fmt.Fprintf(&buf, "rrctl_workflow_sampled_runs{repo=\"%s\",workflow=\"%s\"} %d\n", repo, promLabelEscaper.Replace(wf.Name), wf.SampledRuns)
+		}
+		stalePRs := 0
+		for _, pr := range r.GitHub.PRs {
+			if pr.Stale {
+				stalePRs++
+			}
+		}
+		gauge("rrctl_pull_requests_stale", "Open pull requests not updated within the stale threshold.")
+		fmt.Fprintf(&buf, "rrctl_pull_requests_stale{repo=\"%s\"} %d\n", repo, stalePRs)
+	}
+
+	gauge("rrctl_report_generated_timestamp_seconds", "Unix time the report was generated.")
+	fmt.Fprintf(&buf, "rrctl_report_generated_timestamp_seconds %d\n", r.GeneratedAt.Unix())
+	return buf.Bytes()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kushin77/rrctl/analyzer"
+)
+
+func TestMarkdownTablePermissions(t *testing.T) {
+	r := analyzer.Report{
+		StaleDays: 60,
+		Summary:   analyzer.Summary{WorkflowCount: 2, WorkflowsWithoutPermissions: 1},
+		Workflows: []analyzer.WorkflowReport{
+			{File: ".github/workflows/ci.yml", HasConcurrency: true, HasPermissions: true},
+			{File: ".github/workflows/release.yml", HasConcurrency: true},
+		},
+	}
+	var buf bytes.Buffer
+	writeMarkdownTable(&buf, r)
+	out := buf.String()
+	for _, want := range []string{
+		"· **No permissions:** 1\n",
+		"| Workflow | Fresh | Pinned | Concurrency | Permissions | Findings |\n",
+		"| ci.yml | ✅ | ✅ | ✅ | ✅ | 0 |\n",
+		"| release.yml | ✅ | ✅ | ✅ | ❌ | 0 |\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kushin77/rrctl/analyzer"
+	"gopkg.in/yaml.v3"
+)
+
+// testCase is one entry of the TAP stream: a finding (failing) or a clean file (passing)
+type testCase struct {
+	File    string
+	Finding *analyzer.Finding
+}
+
+// testCases lists every finding, plus one passing case per workflow/action file without findings,
+// so a clean run is a green (not empty) test report
+func testCases(r analyzer.Report) []testCase {
+	var out []testCase
+	add := func(file string, fs []analyzer.Finding) {
+		if len(fs) == 0 {
+			out = append(out, testCase{File: file})
+			return
+		}
+		for i := range fs {
+			out = append(out, testCase{File: file, Finding: &fs[i]})
+		}
+	}
+	for _, w := range r.Workflows {
+		add(w.File, w.Findings)
+	}
+	for _, a := range r.Actions {
+		add(a.File, a.Findings)
+	}
+	return out
+}
+
+func (tc testCase) name() string {
+	if tc.Finding == nil {
+		return "no findings"
+	}
+	f := tc.Finding
+	name := f.RuleID
+	if f.Job != "" {
+		name += " job:" + f.Job
+	}
+	if f.Step != "" {
+		name += " step:" + f.Step
+	}
+	return name
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitSuite struct {
+	XMLName   xml.Name    `xml:"testsuite"`
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+// junitTarget is a file (or the repository) and the rules checked against it; rules of its
+// findings are added, so files with only ad-hoc findings (secrets) need no Rules
+type junitTarget struct {
+	File     string
+	Rules    []string
+	Findings []analyzer.Finding
+}
+
+// junitTargets lists the report's workflows, local actions and GitLab/Azure configs with the
+// catalog rules of their kind
+func junitTargets(r analyzer.Report) []junitTarget {
+	var out []junitTarget
+	workflowRules, actionRules := analyzer.RuleIDs("workflow."), analyzer.RuleIDs("action.")
+	for _, w := range r.Workflows {
+		out = append(out, junitTarget{File: w.File, Rules: workflowRules, Findings: w.Findings})
+	}
+	for _, a := range r.Actions {
+		out = append(out, junitTarget{File: a.File, Rules: actionRules, Findings: a.Findings})
+	}
+	if g := r.GitLab; g != nil {
+		out = append(out, junitTarget{File: g.File, Rules: analyzer.RuleIDs("gitlab."), Findings: g.Findings})
+	}
+	if a := r.Azure; a != nil {
+		out = append(out, junitTarget{File: a.File, Rules: analyzer.RuleIDs("azure."), Findings: a.Findings})
+	}
+	return out
+}
+
+// renderJUnit renders targets as a JUnit XML testsuite with one testcase per file per rule. A rule
+// with findings fails, its <failure> listing each finding's recommendation; the others pass, so
+// dashboards show which checks a workflow meets and a clean run is green (not empty).
+func renderJUnit(name string, generated time.Time, targets []junitTarget) []byte {
+	suite := junitSuite{Name: name, Timestamp: generated.Format("2006-01-02T15:04:05")}
+	for _, t := range targets {
+		byRule := map[string][]analyzer.Finding{}
+		rules := slices.Clone(t.Rules)
+		for _, f := range t.Findings {
+			if _, seen := byRule[f.RuleID]; !seen && !slices.Contains(rules, f.RuleID) {
+				rules = append(rules, f.RuleID)
+			}
+			byRule[f.RuleID] = append(byRule[f.RuleID], f)
+		}
+		sort.Strings(rules)
+		for _, rule := range rules {
+			jc := junitCase{Name: rule, Classname: t.File}
+			if fs := byRule[rule]; len(fs) > 0 {
+				jc.Failure = junitRuleFailure(fs)
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, jc)
+		}
+	}
+	suite.Tests = len(suite.Cases)
+	b, _ := xml.MarshalIndent(suite, "", "  ")
+	return append([]byte(xml.Header), append(b, '\n')...)
+}
+
+// junitRuleFailure summarizes the findings of one rule in one file: the message is the single
+// recommendation (or a count), the type the highest severity, and the body one line per finding
+func junitRuleFailure(fs []analyzer.Finding) *junitFailure {
+	jf := &junitFailure{Message: fs[0].Message, Type: string(fs[0].Severity)}
+	if len(fs) > 1 {
+		jf.Message = fmt.Sprintf("%d findings", len(fs))
+	}
+	var body strings.Builder
+	for _, f := range fs {
+		if f.Severity.AtLeast(analyzer.Severity(jf.Type)) {
+			jf.Type = string(f.Severity)
+		}
+		loc := f.File
+		if f.Line > 0 {
+			loc = fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		fmt.Fprintf(&body, "%s: [%s] %s\n", loc, f.Severity, f.Message)
+	}
+	jf.Body = body.String()
+	return jf
+}
+
+// renderTAP renders the report as a TAP version 13 stream; each finding is a "not ok" line
+// followed by a YAML diagnostic block
+func renderTAP(r analyzer.Report) []byte {
+	var buf bytes.Buffer
+	cases := testCases(r)
+	fmt.Fprintf(&buf, "TAP version 13\n1..%d\n", len(cases))
+	for i, tc := range cases {
+		if tc.Finding == nil {
+			fmt.Fprintf(&buf, "ok %d - %s: %s\n", i+1, tc.File, tc.name())
+			continue
+		}
+		f := tc.Finding
+		fmt.Fprintf(&buf, "not ok %d - %s: %s\n", i+1, tc.File, tc.name())
+		diag := map[string]any{"rule": f.RuleID, "severity": string(f.Severity), "message": f.Message}
+		if f.Line > 0 {
+			diag["line"] = f.Line
+		}
+		if f.Fingerprint != "" {
+			diag["fingerprint"] = f.Fingerprint
+		}
+		b, _ := yaml.Marshal(diag)
+		fmt.Fprintf(&buf, "  ---\n")
+		for _, line := range strings.Split(strings.TrimRight(string(b), "\n"), "\n") {
+			fmt.Fprintf(&buf, "  %s\n", line)
+		}
+		fmt.Fprintf(&buf, "  ...\n")
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kushin77/rrctl/analyzer"
+	"github.com/spf13/cobra"
+)
+
+var repoDefragValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Strictly parse workflow YAML and fail on syntax or structure errors",
+	Long: `Strict workflow validation.
+Unlike the tolerant analysis (which falls back to regex scanning on parse errors), this parses every
+workflow strictly and reports YAML syntax errors with their line/column, plus basic structural
+problems (missing on/jobs, jobs without runs-on or uses, steps without run or uses).
+Exits non-zero if any file is invalid.`,
+	RunE: runRepoDefragValidate,
+}
+
+func init() {
+	repoDefragCmd.AddCommand(repoDefragValidateCmd)
+}
+
+func runRepoDefragValidate(cmd *cobra.Command, args []string) error {
+	var findings []analyzer.Finding
+	checked := 0
+	dirs, err := defragWorkflowDirs(cmd, defragPath)
+	if err != nil {
+		return err
+	}
+	ignorer, err := newWorkflowIgnorer(defragPath, defragIgnore)
+	if err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("read workflows dir: %w", err)
+		}
+		for _, e := range entries {
+			if e.IsDir() || !analyzer.IsWorkflowFile(e.Name()) {
+				continue
+			}
+			full := filepath.Join(dir, e.Name())
+			if ignorer.skip(full) {
+				continue
+			}
+			b, err := os.ReadFile(full)
+			if err != nil {
+				return err
+			}
+			checked++
+			findings = append(findings, analyzer.ValidateWorkflowYAML(full, b)...)
+		}
+	}
+
+	for _, f := range findings {
+		loc := fmt.Sprintf("%s:%d", f.File, f.Line)
+		if f.Column > 0 {
+			loc += fmt.Sprintf(":%d", f.Column)
+		}
+		fmt.Printf("%s: %s [%s]\n", loc, f.Message, f.RuleID)
+	}
+	fmt.Printf("Validated %d workflow files: %d problems\n", checked, len(findings))
+	if ignorer.skipped > 0 {
+		infof(os.Stdout, "Ignored %d workflow files (--ignore/%s)\n", ignorer.skipped, analyzer.IgnoreFileName)
+	}
+	if len(findings) > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d workflow validation problems", len(findings))
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kushin77/rrctl/analyzer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	prunePath      string
+	pruneWorkflows string
+	pruneDaysStale int
+	pruneNoGit     bool
+	pruneDelete    bool
+	pruneArchive   bool
+	pruneYes       bool
+	pruneIgnore    []string
+	pruneFormat    string
+)
+
+// pruneArchiveDir is where --archive moves stale workflows, relative to the repository root.
+// GitHub only runs workflows directly under .github/workflows, so archived files are inert.
+const pruneArchiveDir = ".github/workflows-archive"
+
+var repoPruneCmd = &cobra.Command{
+	Use:   "repo-prune",
+	Short: "List stale workflow files and optionally delete or archive them",
+	Long: `List the workflow files repo-defrag reports as stale (unchanged for --days-stale days).
+Nothing is changed unless --delete is given; --archive moves the files to .github/workflows-archive/
+instead of deleting them. Deleting asks for confirmation unless --yes is set.
+Workflows defining workflow_call, or called by another workflow in the directory, are never removed:
+other workflows or repositories may depend on them. Files that do not parse as YAML are kept too.`,
+	RunE: runRepoPrune,
+}
+
+func init() {
+	rootCmd.AddCommand(repoPruneCmd)
+
+	repoPruneCmd.Flags().StringVarP(&prunePath, "path", "p", ".", "Root path of the repository")
+	repoPruneCmd.Flags().StringVar(&pruneWorkflows, "workflows", ".github/workflows", "Relative path to workflows directory")
+	repoPruneCmd.Flags().IntVar(&pruneDaysStale, "days-stale", 60, "Days without change considered stale")
+	repoPruneCmd.Flags().BoolVar(&pruneNoGit, "no-git", false, "Use filesystem mtime instead of git history for last-modified")
+	repoPruneCmd.Flags().BoolVar(&pruneDelete, "delete", false, "Remove the stale workflow files (default is a dry run that only lists them)")
+	repoPruneCmd.Flags().BoolVar(&pruneArchive, "archive", false, "With --delete, move the files to "+pruneArchiveDir+"/ instead of deleting them")
+	repoPruneCmd.Flags().BoolVarP(&pruneYes, "yes", "y", false, "Do not ask for confirmation before removing files")
+	repoPruneCmd.Flags().StringArrayVar(&pruneIgnore, "ignore", nil, "Never prune workflow files matching this glob, relative to the root (repeatable; also read from .rrctlignore)")
+	repoPruneCmd.Flags().StringVar(&pruneFormat, "format", "text", "Stdout format: text or json")
+}
+
+// PruneCandidate is a stale workflow file and what repo-prune did with it
+type PruneCandidate struct {
+	File         string     `json:"file"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	// Action is "would delete", "would archive", "deleted", "archived" or "kept"
+	Action string `json:"action"`
+	// Reason explains a kept file
+	Reason string `json:"reason,omitempty"`
+	// ArchivedTo is the new path of an archived file
+	ArchivedTo string `json:"archivedTo,omitempty"`
+}
+
+// PruneReport is the --format json output of repo-prune
+type PruneReport struct {
+	Path       string           `json:"path"`
+	DaysStale  int              `json:"daysStale"`
+	DryRun     bool             `json:"dryRun"`
+	Candidates []PruneCandidate `json:"candidates"`
+}
+
+func runRepoPrune(cmd *cobra.Command, args []string) error {
+	switch pruneFormat {
+	case "text", "json":
+	default:
+		return fmt.Errorf("unsupported --format %q (want text|json)", pruneFormat)
+	}
+	if pruneArchive && !pruneDelete {
+		return fmt.Errorf("--archive requires --delete")
+	}
+	ignorer, err := newWorkflowIgnorer(prunePath, pruneIgnore)
+	if err != nil {
+		return err
+	}
+
+	stopProgress := startProgress(pruneFormat == "text")
+	workflows, err := analyzer.ScanWorkflowDirs([]string{filepath.Join(prunePath, pruneWorkflows)}, pruneDaysStale, !pruneNoGit, analyzer.ScanOptions{Skip: ignorer.skip})
+	stopProgress()
+	if err != nil {
+		return err
+	}
+	// Local reusable workflows called by any workflow, stale or not
+	called := map[string]bool{}
+	for _, w := range workflows {
+		for _, rw := range w.ReusableWorkflows {
+			if rw.Local {
+				called[filepath.Clean(filepath.Join(prunePath, filepath.FromSlash(rw.Uses)))] = true
+			}
+		}
+	}
+
+	report := PruneReport{Path: prunePath, DaysStale: pruneDaysStale, DryRun: !pruneDelete, Candidates: []PruneCandidate{}}
+	verb := "would delete"
+	if pruneArchive {
+		verb = "would archive"
+	}
+	var remove []int
+	for _, w := range workflows {
+		if !analyzer.WorkflowIsStale(w, pruneDaysStale) {
+			continue
+		}
+		c := PruneCandidate{File: w.File, LastModified: w.LastModified, Action: verb}
+		// The text fallback only finds workflow_call as a key, so look at the raw text as well
+		raw, rerr := os.ReadFile(w.File)
+		switch {
+		case slices.Contains(w.Triggers, "workflow_call"), rerr == nil && bytes.Contains(raw, []byte("workflow_call")):
+			c.Action, c.Reason = "kept", "defines workflow_call; other workflows or repositories may call it"
+		case w.TextFallback:
+			c.Action, c.Reason = "kept", "does not parse as YAML, so its triggers and calls cannot be checked; fix or remove it by hand"
+		case rerr != nil:
+			c.Action, c.Reason = "kept", fmt.Sprintf("cannot be read: %v", rerr)
+		case called[filepath.Clean(w.File)]:
+			c.Action, c.Reason = "kept", "called as a reusable workflow by another workflow"
+		default:
+			remove = append(remove, len(report.Candidates))
+		}
+		report.Candidates = append(report.Candidates, c)
+	}
+
+	if pruneDelete && len(remove) > 0 {
+		if !pruneYes {
+			cmd.SilenceUsage = true
+			ok, err := confirmPrune(len(remove))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errors.New("repo-prune: aborted, nothing was removed")
+			}
+		}
+		for _, i := range remove {
+			c := &report.Candidates[i]
+			if pruneArchive {
+				dst, err := archiveWorkflow(prunePath, c.File)
+				if err != nil {
+					return err
+				}
+				c.Action, c.ArchivedTo = "archived", dst
+				continue
+			}
+			if err := os.Remove(c.File); err != nil {
+				return fmt.Errorf("delete %s: %w", c.File, err)
+			}
+			c.Action = "deleted"
+		}
+	}
+
+	if pruneFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	printPruneText(report, len(remove))
+	return nil
+}
+
+// confirmPrune asks on the terminal before n files are removed; without a terminal there is no one
+// to ask, so --yes is required
+func confirmPrune(n int) (bool, error) {
+	if !isTerminal(os.Stdin) {
+		return false, errors.New("repo-prune --delete needs confirmation: pass --yes when not running in a terminal")
+	}
+	action := "Delete"
+	if pruneArchive {
+		action = "Archive"
+	}
+	fmt.Fprintf(os.Stderr, "%s %d stale workflow files? [y/N] ", action, n)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, nil
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// archiveWorkflow moves a workflow file into root's archive directory, refusing to overwrite an
+// earlier archived file of the same name
+func archiveWorkflow(root, file string) (string, error) {
+	dir := filepath.Join(root, filepath.FromSlash(pruneArchiveDir))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	dst := filepath.Join(dir, filepath.Base(file))
+	if _, err := os.Stat(dst); err == nil {
+		return "", fmt.Errorf("archive %s: %s already exists", file, dst)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	if err := os.Rename(file, dst); err != nil {
+		return "", fmt.Errorf("archive %s: %w", file, err)
+	}
+	return dst, nil
+}
+
+func printPruneText(r PruneReport, removable int) {
+	if len(r.Candidates) == 0 {
+		fmt.Printf("No stale workflows (> %d days without change)\n", r.DaysStale)
+		return
+	}
+	fmt.Printf("Stale workflows (> %d days without change):\n", r.DaysStale)
+	for _, c := range r.Candidates {
+		lm := "n/a"
+		if c.LastModified != nil {
+			lm = c.LastModified.Format("2006-01-02")
+		}
+		line := fmt.Sprintf("  %s (last modified %s): %s", c.File, lm, c.Action)
+		switch {
+		case c.Reason != "":
+			line += " (" + c.Reason + ")"
+		case c.ArchivedTo != "":
+			line += " to " + c.ArchivedTo
+		}
+		fmt.Println(line)
+	}
+	if r.DryRun && removable > 0 {
+		infof(os.Stdout, "Dry run: pass --delete (or --delete --archive) to remove %d files\n", removable)
+	}
+}
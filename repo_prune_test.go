@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestPruneKeepsUnparseableWorkflows checks that files which do not parse as YAML are never
+// removed, and that their reusable workflow calls still protect the callee
+func TestPruneKeepsUnparseableWorkflows(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, ".github", "workflows")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		// Broken YAML (unclosed flow sequence) whose flow-style trigger list includes workflow_call
+		"shared.yml": "on: [push, workflow_call]\njobs:\n  a:\n    runs-on: [ubuntu-latest\n",
+		// Broken YAML calling lib.yml
+		"caller.yml": "on: push\njobs:\n  call:\n    uses: ./.github/workflows/lib.yml\n  bad: [\n",
+		"lib.yml":    "on: push\njobs:\n  a:\n    runs-on: ubuntu-latest\n    steps:\n      - run: true\n",
+		"old.yml":    "on: push\njobs:\n  a:\n    runs-on: ubuntu-latest\n    steps:\n      - run: true\n",
+	}
+	old := time.Now().AddDate(0, 0, -400)
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := runRRCTL(t, "repo-prune", "--path", root, "--no-git", "--delete", "--yes", "--format", "json")
+	if err != nil {
+		t.Fatalf("repo-prune: %v\n%s", err, out)
+	}
+	var report PruneReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	actions := map[string]string{}
+	for _, c := range report.Candidates {
+		actions[filepath.Base(c.File)] = c.Action
+	}
+	want := map[string]string{"shared.yml": "kept", "caller.yml": "kept", "lib.yml": "kept", "old.yml": "deleted"}
+	for name, action := range want {
+		if actions[name] != action {
+			t.Errorf("%s: action %q, want %q (%s)", name, actions[name], action, out)
+		}
+		_, err := os.Stat(filepath.Join(dir, name))
+		if exists := err == nil; exists != (action == "kept") {
+			t.Errorf("%s: exists=%v after action %q", name, exists, action)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// repoRootDetect is the persistent --repo-root-detect flag
+var repoRootDetect bool
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&repoRootDetect, "repo-root-detect", false, "Resolve --path to the enclosing git repository root (nearest parent with .git); falls back to --path")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// Config defaults first, so --path from .rrctl.yaml is resolved by --repo-root-detect too
+		// and --quiet/--verbose may come from it
+		if err := applyConfigFile(cmd); err != nil {
+			return err
+		}
+		if err := setOutputLevel(); err != nil {
+			return err
+		}
+		if err := checkColorMode(); err != nil {
+			return err
+		}
+		return detectRepoRoot(cmd, args)
+	}
+}
+
+// detectRepoRoot rewrites the running command's --path flag to the git repository root when
+// --repo-root-detect is set, so commands work the same from any subdirectory
+func detectRepoRoot(cmd *cobra.Command, args []string) error {
+	if !repoRootDetect {
+		return nil
+	}
+	flag := cmd.Flags().Lookup("path")
+	if flag == nil {
+		return nil
+	}
+	root, err := findRepoRoot(flag.Value.String())
+	if err != nil {
+		warnf("%v; using --path %s", err, flag.Value.String())
+		return nil
+	}
+	infof(os.Stderr, "Detected repository root: %s\n", root)
+	return flag.Value.Set(root)
+}
+
+// findRepoRoot walks up from start to the nearest directory containing .git (a directory, or a
+// file for worktrees and submodules)
+func findRepoRoot(start string) (string, error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("no git repository found above " + start)
+		}
+		dir = parent
+	}
+}
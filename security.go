@@ -1,11 +1,15 @@
 package main
 
 import (
+"encoding/json"
+"errors"
 "fmt"
+"io"
 "os"
 "path/filepath"
 "strings"
 
+"github.com/kushin77/rrctl/analyzer"
 "github.com/spf13/cobra"
 )
 
@@ -14,7 +18,7 @@ Use:   "security-scan",
 Short: "Basic security scanning for common vulnerabilities",
 Long: `Run basic security scans including:
 - Secrets detection in files
-- Basic dependency vulnerability checks
+- Dependency vulnerability checks (govulncheck for Go modules)
 - File permission analysis`,
 RunE: runBasicSecurityScan,
 }
@@ -24,8 +28,87 @@ targetPath string
 checkSecrets bool
 checkDeps   bool
 checkPerms  bool
+fixPerms    bool
+applyFixes  bool
+scanGitConfig bool
+keywordMatch bool
+minEntropy float64
+minTokenLength int
+maxMatchesPerFile int
+securityJSON bool
+securityFailOn string
+respectGitignore bool
+maxFileSizeMB int
+secretsBaselinePath string
+writeSecretsBaseline bool
+secretRulesPath string
+noBuiltinRules bool
+securitySarif string
+securityFormat string
+scanHistory bool
+historyDepth int
+sensitivePatterns []string
+secretWorkers int
 )
 
+// secretsBaseline holds the --baseline approvals for the current scan (nil without --baseline)
+var secretsBaseline *analyzer.SecretBaseline
+
+// secretRules are the built-in and --rules token patterns for the current scan
+var secretRules []analyzer.SecretRule
+
+// securityOut receives the human-readable scan output; it is discarded with --json or --format sarif
+var securityOut io.Writer = os.Stdout
+
+// SecurityReport is the --json output of security-scan
+type SecurityReport struct {
+Path string `json:"path"`
+Secrets []SecretFinding `json:"secrets"`
+SuppressedSecrets int `json:"suppressedSecrets"`
+// BaselinedSecrets matched --baseline entries; they are reported but never fail the scan
+BaselinedSecrets []SecretFinding `json:"baselinedSecrets,omitempty"`
+DependencyFiles []string `json:"dependencyFiles"`
+// Dependencies are the direct dependencies of go.mod and package.json at the scan path
+Dependencies []analyzer.Dependency `json:"dependencies"`
+// DependencyFindings are pseudo-versions, local replaces, floating versions and git sources
+DependencyFindings []analyzer.DependencyFinding `json:"dependencyFindings"`
+// Vulnerabilities are govulncheck results for a Go module at the scan path
+Vulnerabilities []analyzer.Vulnerability `json:"vulnerabilities"`
+PermissionWarnings []PermissionWarning `json:"permissionWarnings"`
+// SensitiveFiles are credential-like files (--sensitive-files) readable by group or other users
+SensitiveFiles []PermissionWarning `json:"sensitiveFiles"`
+// HistorySecrets are secrets added by past commits (--scan-history), including ones deleted since
+HistorySecrets []analyzer.HistorySecret `json:"historySecrets,omitempty"`
+Errors []string `json:"errors,omitempty"`
+}
+
+// SecretFinding is a secret or URL-embedded credential match; Message carries only a redacted preview
+type SecretFinding struct {
+File string `json:"file"`
+Line int `json:"line"`
+Rule string `json:"rule"`
+Severity analyzer.Severity `json:"severity"`
+Message string `json:"message"`
+Reason string `json:"reason,omitempty"`
+}
+
+// PermissionWarning is a group/world-writable file, or a group/world-readable sensitive file;
+// ProposedMode is set with --fix-perms
+type PermissionWarning struct {
+File string `json:"file"`
+Mode string `json:"mode"`
+ProposedMode string `json:"proposedMode,omitempty"`
+Fixed bool `json:"fixed,omitempty"`
+}
+
+func secretFindings(fs []analyzer.Finding) []SecretFinding {
+var out []SecretFinding
+for _, f := range fs {
+out = append(out, SecretFinding{File: f.File, Line: f.Line, Rule: f.RuleID, Severity: f.Severity, Message: f.Message})
+}
+return out
+}
+
 func init() {
 rootCmd.AddCommand(securityCmd)
 
@@ -33,96 +116,319 @@ securityCmd.Flags().StringVarP(&targetPath, "path", "p", ".", "Path to scan")
 securityCmd.Flags().BoolVar(&checkSecrets, "secrets", true, "Check for secrets in files")
 securityCmd.Flags().BoolVar(&checkDeps, "deps", true, "Check dependencies")
 securityCmd.Flags().BoolVar(&checkPerms, "perms", true, "Check file permissions")
+securityCmd.Flags().BoolVar(&keywordMatch, "keyword-match", false, "Also flag files mentioning password/secret/key/token keywords (the legacy, noisy matcher)")
+securityCmd.Flags().Float64Var(&minEntropy, "min-entropy", analyzer.DefaultSecretScanOptions.MinEntropy, "Minimum Shannon entropy (bits/char) for a token to be reported as a likely secret")
+securityCmd.Flags().IntVar(&minTokenLength, "min-token-length", analyzer.DefaultSecretScanOptions.MinTokenLength, "Minimum length of tokens checked by the entropy detector")
+securityCmd.Flags().IntVar(&secretWorkers, "workers", 0, "Files scanned for secrets in parallel (0 = number of CPUs); findings are printed as they are found and sorted by file and line in the report")
+securityCmd.Flags().IntVar(&maxMatchesPerFile, "max-matches-per-file", 0, "Report at most this many secret matches per file (0 = no limit)")
+securityCmd.Flags().BoolVar(&respectGitignore, "respect-gitignore", true, "Skip files ignored by the .gitignore in the scan root when looking for secrets")
+securityCmd.Flags().IntVar(&maxFileSizeMB, "max-file-size", int(analyzer.DefaultSecretScanOptions.MaxFileSize>>20), "Skip files larger than this many MB when looking for secrets (0 = no limit)")
+securityCmd.Flags().StringVar(&secretsBaselinePath, "baseline", "", "JSON file of approved secret matches (file, line, rule, reason); matches are reported as suppressed and never fail the scan")
+securityCmd.Flags().BoolVar(&writeSecretsBaseline, "write-baseline", false, "Write the current secret findings to the --baseline file, accepting them, instead of gating")
+securityCmd.Flags().StringVar(&secretRulesPath, "rules", "", "YAML list of custom secret rules (name, pattern, severity) applied alongside the built-in ones")
+securityCmd.Flags().BoolVar(&noBuiltinRules, "no-builtin-rules", false, "Disable the built-in AWS/GitHub/Slack token rules")
+securityCmd.Flags().BoolVar(&scanHistory, "scan-history", false, "Also scan the lines added by past git commits for secrets, reporting the commit, author and file that introduced each")
+securityCmd.Flags().IntVar(&historyDepth, "history-depth", 1000, "With --scan-history, scan at most this many of the most recent commits (0 = entire history)")
+securityCmd.Flags().BoolVar(&scanGitConfig, "scan-git-config", false, "Also scan .git/config for credentials embedded in remote URLs")
+securityCmd.Flags().StringSliceVar(&sensitivePatterns, "sensitive-files", analyzer.DefaultSensitiveFilePatterns, "Base-name globs of credential files that must not be group/world-readable (comma-separated or repeatable; replaces the defaults)")
+securityCmd.Flags().BoolVar(&fixPerms, "fix-perms", false, "Propose chmod fixes for group/world-writable files and readable sensitive files (dry run unless --apply)")
+securityCmd.Flags().BoolVar(&applyFixes, "apply", false, "With --fix-perms, apply the proposed chmod operations")
+securityCmd.Flags().BoolVar(&securityJSON, "json", false, "Output results in JSON format")
+securityCmd.Flags().StringVar(&securityFormat, "format", "text", "Stdout format: text, json (same as --json) or sarif (secret findings as SARIF 2.1.0)")
+securityCmd.Flags().StringVar(&securitySarif, "sarif", "", "Write secret findings as SARIF 2.1.0 (for GitHub code scanning upload) to path; baselined matches are included as suppressed")
+securityCmd.Flags().StringVar(&securityFailOn, "fail-on", "none", "Exit non-zero when findings of this category exist (none|secrets|perms|vulns|any)")
 }
 
 func runBasicSecurityScan(cmd *cobra.Command, args []string) error {
-fmt.Println("🔒 Running basic security scan...")
+if applyFixes && !fixPerms {
+return fmt.Errorf("--apply requires --fix-perms")
+}
+switch securityFailOn {
+case "none", "secrets", "perms", "vulns", "any":
+default:
+return fmt.Errorf("unsupported --fail-on %q (want none|secrets|perms|vulns|any)", securityFailOn)
+}
+format := securityFormat
+switch format {
+case "text", "json", "sarif":
+default:
+return fmt.Errorf("unsupported --format %q (want text|json|sarif)", securityFormat)
+}
+if securityJSON {
+if format == "sarif" {
+return fmt.Errorf("--json conflicts with --format sarif")
+}
+format = "json"
+}
+if writeSecretsBaseline && secretsBaselinePath == "" {
+return fmt.Errorf("--write-baseline requires --baseline")
+}
+if err := analyzer.ValidateSensitivePatterns(sensitivePatterns); err != nil {
+return fmt.Errorf("--sensitive-files: %w", err)
+}
+secretRules = nil
+if !noBuiltinRules {
+secretRules = append(secretRules, analyzer.BuiltinSecretRules...)
+}
+if secretRulesPath != "" {
+rules, err := analyzer.LoadSecretRules(secretRulesPath)
+if err != nil {
+return err
+}
+secretRules = append(secretRules, rules...)
+}
+secretsBaseline = nil
+if secretsBaselinePath != "" {
+b, err := analyzer.LoadSecretBaseline(secretsBaselinePath)
+// --write-baseline may create the file
+if err != nil && !(writeSecretsBaseline && errors.Is(err, os.ErrNotExist)) {
+return err
+}
+secretsBaseline = b
+}
+
+securityOut = os.Stdout
+if format != "text" {
+securityOut = io.Discard
+}
+report := &SecurityReport{Path: targetPath, Secrets: []SecretFinding{}, DependencyFiles: []string{}, Dependencies: []analyzer.Dependency{}, DependencyFindings: []analyzer.DependencyFinding{}, Vulnerabilities: []analyzer.Vulnerability{}, PermissionWarnings: []PermissionWarning{}, SensitiveFiles: []PermissionWarning{}}
+failed := func(check string, err error) {
+fmt.Fprintf(securityOut, "❌ %s failed: %v\n", check, err)
+report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", check, err))
+}
+
+infof(securityOut, "🔒 Running basic security scan...\n")
 
 if checkSecrets {
-if err := scanForSecrets(targetPath); err != nil {
-fmt.Printf("❌ Secrets scan failed: %v\n", err)
+if err := scanForSecrets(targetPath, report); err != nil {
+failed("Secrets scan", err)
+}
+if err := scanCredentialURLs(targetPath, scanGitConfig, report); err != nil {
+failed("Credential URL scan", err)
+}
+}
+if scanHistory {
+if err := scanSecretHistory(targetPath, report); err != nil {
+failed("History scan", err)
 }
 }
 
 if checkDeps {
-if err := checkDependencies(targetPath); err != nil {
-fmt.Printf("❌ Dependency check failed: %v\n", err)
+if err := checkDependencies(targetPath, report); err != nil {
+failed("Dependency check", err)
 }
 }
 
 if checkPerms {
-if err := checkFilePermissions(targetPath); err != nil {
-fmt.Printf("❌ Permission check failed: %v\n", err)
+if err := checkFilePermissions(targetPath, report); err != nil {
+failed("Permission check", err)
 }
 }
 
-fmt.Println("✅ Security scan completed")
-return nil
+switch format {
+case "json":
+encoder := json.NewEncoder(os.Stdout)
+encoder.SetIndent("", "  ")
+if err := encoder.Encode(report); err != nil {
+return err
 }
-
-func scanForSecrets(path string) error {
-fmt.Println("🔍 Scanning for secrets...")
-
-secrets := []string{
-"password",
-"secret",
-"key",
-"token",
-"api_key",
-"apikey",
+case "sarif":
+if err := securitySarifLog(report).Encode(os.Stdout); err != nil {
+return err
+}
+default:
+infof(os.Stdout, "✅ Security scan completed\n")
 }
 
-found := false
+if securitySarif != "" {
+if err := securitySarifLog(report).Write(securitySarif); err != nil {
+return fmt.Errorf("write SARIF: %w", err)
+}
+infof(securityOut, "Wrote SARIF report to %s\n", securitySarif)
+}
 
-err := filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
-if err != nil {
+if writeSecretsBaseline {
+return writeSecretBaseline(report)
+}
+if err := securityGateError(report, securityFailOn); err != nil {
+cmd.SilenceUsage = true
+return err
+}
 return nil
 }
 
-// Skip binary files, .git, node_modules, etc.
-if info.IsDir() {
-if strings.HasPrefix(info.Name(), ".") || info.Name() == "node_modules" {
-return filepath.SkipDir
+// writeSecretBaseline accepts every current secret finding, keeping reasons already in the baseline
+func writeSecretBaseline(report *SecurityReport) error {
+var all []analyzer.Finding
+for _, sf := range append(report.Secrets, report.BaselinedSecrets...) {
+all = append(all, analyzer.Finding{RuleID: sf.Rule, File: sf.File, Line: sf.Line})
+}
+sb := analyzer.NewSecretBaseline(all, secretsBaseline)
+if err := sb.Write(secretsBaselinePath); err != nil {
+return fmt.Errorf("write secrets baseline: %w", err)
 }
+infof(os.Stderr, "Wrote secrets baseline (%d entries) to %s\n", len(sb.Entries), secretsBaselinePath)
 return nil
 }
 
-// Skip common binary extensions
-ext := strings.ToLower(filepath.Ext(filePath))
-if ext == ".jpg" || ext == ".png" || ext == ".gif" || ext == ".pdf" || ext == ".zip" {
+// securitySarifLog builds the SARIF log of the secret findings, plus baselined ones marked as suppressed
+func securitySarifLog(report *SecurityReport) *analyzer.SarifLog {
+var findings []analyzer.SarifFinding
+add := func(sf SecretFinding, suppressed bool) {
+f := analyzer.Finding{RuleID: sf.Rule, Severity: sf.Severity, File: sf.File, Line: sf.Line, Message: sf.Message}
+findings = append(findings, analyzer.SarifFinding{Finding: f, Suppressed: suppressed, Justification: sf.Reason})
+}
+for _, sf := range report.Secrets {
+add(sf, false)
+}
+for _, sf := range report.BaselinedSecrets {
+add(sf, true)
+}
+for _, hs := range report.HistorySecrets {
+f := hs.Finding
+f.Message += fmt.Sprintf(" (introduced in commit %s by %s)", shortSHA(hs.Commit), hs.Author)
+findings = append(findings, analyzer.SarifFinding{Finding: f})
+}
+return analyzer.NewSarifLog(version, targetPath, findings)
+}
+
+// printSecret prints a secret finding as a "⚠️  what in file:line" warning, or as suppressed when
+// --baseline approves it
+func printSecret(what string, f analyzer.Finding) {
+if reason, ok := secretsBaseline.Reason(f); ok {
+printAboveProgress(securityOut, "ℹ️  Suppressed by baseline %s:%d: %s (%s)\n", f.File, f.Line, f.Message, reason)
+return
+}
+printAboveProgress(securityOut, "⚠️  %s in %s:%d: %s\n", colorize(severityColor(f.Severity), what), f.File, f.Line, f.Message)
+}
+
+// filterBaselined records findings approved by --baseline, returning the rest
+func filterBaselined(findings []analyzer.Finding, report *SecurityReport) []analyzer.Finding {
+var kept []analyzer.Finding
+for _, f := range findings {
+reason, ok := secretsBaseline.Reason(f)
+if !ok {
+kept = append(kept, f)
+continue
+}
+sf := secretFindings([]analyzer.Finding{f})[0]
+sf.Reason = reason
+report.BaselinedSecrets = append(report.BaselinedSecrets, sf)
+}
+return kept
+}
+
+// securityGateError fails the scan when the --fail-on category has findings, with counts per
+// category; permission warnings already fixed with --apply do not count
+func securityGateError(report *SecurityReport, failOn string) error {
+perms := 0
+for _, pw := range append(report.PermissionWarnings, report.SensitiveFiles...) {
+if !pw.Fixed {
+perms++
+}
+}
+secrets, vulns := len(report.Secrets)+len(report.HistorySecrets), len(report.Vulnerabilities)
+var failing bool
+switch failOn {
+case "secrets":
+failing = secrets > 0
+case "perms":
+failing = perms > 0
+case "vulns":
+failing = vulns > 0
+case "any":
+failing = secrets+perms+vulns > 0
+}
+if !failing {
 return nil
 }
+return fmt.Errorf("security-scan found issues (--fail-on %s): secrets=%d perms=%d vulns=%d", failOn, secrets, perms, vulns)
+}
 
-content, err := os.ReadFile(filePath)
+func scanForSecrets(path string, report *SecurityReport) error {
+infof(securityOut, "🔍 Scanning for secrets...\n")
+
+opts := analyzer.SecretScanOptions{Keywords: keywordMatch, Entropy: true, Rules: secretRules, MinEntropy: minEntropy, MinTokenLength: minTokenLength, MaxMatchesPerFile: maxMatchesPerFile, RespectGitignore: respectGitignore, MaxFileSize: int64(maxFileSizeMB) << 20, Workers: secretWorkers}
+// Findings are printed as files finish, so a large scan shows results right away
+opts.OnFinding = func(f analyzer.Finding) { printSecret("Potential secret", f) }
+stopProgress := startProgress(securityOut == os.Stdout)
+findings, suppressed, err := analyzer.ScanSecrets(path, opts)
+stopProgress()
 if err != nil {
+return err
+}
+findings = filterBaselined(findings, report)
+printInlineSuppressed(suppressed)
+report.Secrets = append(report.Secrets, secretFindings(findings)...)
+report.SuppressedSecrets += suppressed
+
+if len(findings) == 0 {
+infof(securityOut, "✅ No obvious secrets detected\n")
+}
+
 return nil
 }
 
-contentStr := strings.ToLower(string(content))
-for _, secret := range secrets {
-if strings.Contains(contentStr, secret) {
-fmt.Printf("⚠️  Potential secret found in: %s\n", filePath)
-found = true
-break
+// scanCredentialURLs prints git/CI config credentials found by analyzer.ScanCredentialURLs
+func scanCredentialURLs(path string, includeGitConfig bool, report *SecurityReport) error {
+infof(securityOut, "🔗 Scanning git and CI config files for embedded credentials...\n")
+
+findings, suppressed, err := analyzer.ScanCredentialURLs(path, includeGitConfig)
+if err != nil {
+return err
 }
+for _, f := range findings {
+printSecret("URL-embedded credentials", f)
 }
+findings = filterBaselined(findings, report)
+printInlineSuppressed(suppressed)
+report.Secrets = append(report.Secrets, secretFindings(findings)...)
+report.SuppressedSecrets += suppressed
 
+if len(findings) == 0 {
+infof(securityOut, "✅ No URL-embedded credentials detected\n")
+}
 return nil
-})
+}
+
+// scanSecretHistory prints the secrets that past commits added, with the commit that introduced each
+func scanSecretHistory(path string, report *SecurityReport) error {
+infof(securityOut, "🕰️  Scanning git history for secrets...\n")
 
+opts := analyzer.SecretScanOptions{Keywords: keywordMatch, Entropy: true, Rules: secretRules, MinEntropy: minEntropy, MinTokenLength: minTokenLength, MaxMatchesPerFile: maxMatchesPerFile}
+stopProgress := startProgress(securityOut == os.Stdout)
+found, err := analyzer.ScanSecretHistory(path, historyDepth, opts)
+stopProgress()
 if err != nil {
 return err
 }
-
-if !found {
-fmt.Println("✅ No obvious secrets detected")
+for _, hs := range found {
+fmt.Fprintf(securityOut, "⚠️  Secret committed in %s by %s (%s) in %s:%d: %s\n", shortSHA(hs.Commit), hs.Author, hs.Date.Format("2006-01-02"), hs.File, hs.Line, hs.Message)
 }
+report.HistorySecrets = append(report.HistorySecrets, found...)
 
+if len(found) == 0 {
+infof(securityOut, "✅ No secrets found in git history\n")
+}
 return nil
 }
 
-func checkDependencies(path string) error {
-fmt.Println("📦 Checking dependencies...")
+// shortSHA abbreviates a commit SHA for display
+func shortSHA(sha string) string {
+if len(sha) > 12 {
+return sha[:12]
+}
+return sha
+}
+
+func printInlineSuppressed(n int) {
+if n > 0 {
+fmt.Fprintf(securityOut, "ℹ️  %d matches suppressed by inline rrctl:ignore comments\n", n)
+}
+}
+
+func checkDependencies(path string, report *SecurityReport) error {
+infof(securityOut, "📦 Checking dependencies...\n")
 
 // Check for common dependency files
 depFiles := []string{
@@ -137,24 +443,95 @@ depFiles := []string{
 found := false
 for _, file := range depFiles {
 if _, err := os.Stat(filepath.Join(path, file)); err == nil {
-fmt.Printf("📄 Found dependency file: %s\n", file)
+fmt.Fprintf(securityOut, "📄 Found dependency file: %s\n", file)
+report.DependencyFiles = append(report.DependencyFiles, file)
 found = true
 }
 }
 
 if !found {
-fmt.Println("ℹ️  No common dependency files found")
+infof(securityOut, "ℹ️  No common dependency files found\n")
 } else {
-fmt.Println("✅ Dependency files detected")
+infof(securityOut, "✅ Dependency files detected\n")
 }
+auditManifests(path, report)
 
+if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+return checkGoVulns(path, report)
+}
 return nil
 }
 
-func checkFilePermissions(path string) error {
-fmt.Println("🔐 Checking file permissions...")
+// manifestAudits parse the manifests whose versions security-scan audits
+var manifestAudits = map[string]func(string) ([]analyzer.Dependency, []analyzer.DependencyFinding, error){
+"go.mod": analyzer.AuditGoMod,
+"package.json": analyzer.AuditPackageJSON,
+}
+
+// auditManifests lists direct dependencies and flags risky version specs; a manifest that cannot
+// be parsed is recorded as an error without stopping the other checks
+func auditManifests(path string, report *SecurityReport) {
+for _, name := range []string{"go.mod", "package.json"} {
+file := filepath.Join(path, name)
+if _, err := os.Stat(file); err != nil {
+continue
+}
+deps, findings, err := manifestAudits[name](file)
+if err != nil {
+fmt.Fprintf(securityOut, "❌ %s audit failed: %v\n", name, err)
+report.Errors = append(report.Errors, fmt.Sprintf("%s audit: %v", name, err))
+continue
+}
+report.Dependencies = append(report.Dependencies, deps...)
+report.DependencyFindings = append(report.DependencyFindings, findings...)
+infof(securityOut, "📄 %s: %d direct dependencies\n", name, len(deps))
+for _, f := range findings {
+loc := f.File
+if f.Line > 0 {
+loc = fmt.Sprintf("%s:%d", f.File, f.Line)
+}
+fmt.Fprintf(securityOut, "⚠️  Dependency %s in %s: %s\n", f.Name, loc, f.Reason)
+}
+}
+}
+
+// checkGoVulns runs govulncheck on the Go module at path, degrading to a hint when it is not installed
+func checkGoVulns(path string, report *SecurityReport) error {
+infof(securityOut, "🛡️  Checking Go dependencies with govulncheck...\n")
+vulns, err := analyzer.ScanGoVulns(path)
+if errors.Is(err, analyzer.ErrGovulncheckMissing) {
+infof(securityOut, "ℹ️  Skipping Go vulnerability scan: %v\n", err)
+return nil
+}
+if err != nil {
+return err
+}
+report.Vulnerabilities = append(report.Vulnerabilities, vulns...)
+for _, v := range vulns {
+id := v.ID
+if cves := v.CVEs(); len(cves) > 0 {
+id += " (" + strings.Join(cves, ", ") + ")"
+}
+reach := "imported"
+if v.Called {
+reach = "called"
+}
+fmt.Fprintf(securityOut, "⚠️  %s: %s@%s, fixed in %s [%s]: %s\n", id, v.Module, v.Version, valueOr(v.FixedVersion, "(no fix)"), reach, v.Summary)
+}
+if len(vulns) == 0 {
+infof(securityOut, "✅ No known vulnerabilities in Go dependencies\n")
+} else {
+fmt.Fprintf(securityOut, "⚠️  Found %d vulnerable Go dependencies\n", len(vulns))
+}
+
+return nil
+}
+
+func checkFilePermissions(path string, report *SecurityReport) error {
+infof(securityOut, "🔐 Checking file permissions...\n")
 
 warnings := 0
+fixed := 0
 
 err := filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
 if err != nil {
@@ -166,11 +543,44 @@ if info.IsDir() {
 return nil
 }
 
-// Check for world-writable files
+// Fixes only ever clear bits, so they can never loosen permissions; on a sensitive file that is
+// also writable the read fix builds on the write fix
 mode := info.Mode()
+target := mode.Perm()
+// Symlink modes are always 0777; chmod would follow the link and could loosen the target
+fixable := fixPerms && mode&os.ModeSymlink == 0
+propose := func(pw *PermissionWarning, bits os.FileMode) {
+if !fixable {
+return
+}
+from := target
+target &^= bits
+pw.ProposedMode = fmt.Sprintf("%04o", target)
+if !applyFixes {
+fmt.Fprintf(securityOut, "   [DRY RUN] Would chmod %s: %04o -> %04o\n", filePath, from, target)
+} else if err := os.Chmod(filePath, target); err != nil {
+fmt.Fprintf(securityOut, "   ❌ chmod %s failed: %v\n", filePath, err)
+} else {
+fmt.Fprintf(securityOut, "   🔧 chmod %s: %04o -> %04o\n", filePath, from, target)
+pw.Fixed = true
+fixed++
+}
+}
+
+// Check for world-writable files
 if mode.Perm()&0o022 != 0 {
-fmt.Printf("⚠️  World-writable file: %s (permissions: %s)\n", filePath, mode.Perm())
+fmt.Fprintf(securityOut, "⚠️  World-writable file: %s (permissions: %s)\n", filePath, mode.Perm())
 warnings++
+report.PermissionWarnings = append(report.PermissionWarnings, PermissionWarning{File: filePath, Mode: fmt.Sprintf("%04o", mode.Perm())})
+propose(&report.PermissionWarnings[len(report.PermissionWarnings)-1], 0o022)
+}
+
+// Credential files should be readable by their owner only
+if mode.Perm()&0o044 != 0 && analyzer.MatchesSensitiveName(sensitivePatterns, filePath) {
+fmt.Fprintf(securityOut, "⚠️  Sensitive file readable by group/others: %s (permissions: %s)\n", filePath, mode.Perm())
+warnings++
+report.SensitiveFiles = append(report.SensitiveFiles, PermissionWarning{File: filePath, Mode: fmt.Sprintf("%04o", mode.Perm())})
+propose(&report.SensitiveFiles[len(report.SensitiveFiles)-1], 0o044)
 }
 
 return nil
@@ -181,9 +591,14 @@ return err
 }
 
 if warnings == 0 {
-fmt.Println("✅ No permission issues found")
+infof(securityOut, "✅ No permission issues found\n")
 } else {
-fmt.Printf("⚠️  Found %d permission warnings\n", warnings)
+fmt.Fprintf(securityOut, "⚠️  Found %d permission warnings\n", warnings)
+}
+if fixPerms && applyFixes {
+fmt.Fprintf(securityOut, "🔧 Fixed permissions on %d files\n", fixed)
+} else if fixPerms && warnings > 0 {
+infof(securityOut, "ℹ️  Run with --fix-perms --apply to change these modes\n")
 }
 
 return nil